@@ -4,6 +4,9 @@ set -e
 VERSION=${1:-"v1.0.0"}
 APP="seiapanel"
 DIST="dist"
+COMMIT=$(git rev-parse --short HEAD 2>/dev/null || echo "unknown")
+BUILD_DATE=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+LDFLAGS="-X seiapanel/config.Version=$VERSION -X seiapanel/config.Commit=$COMMIT -X seiapanel/config.BuildDate=$BUILD_DATE"
 
 echo "Building Seia Panel $VERSION..."
 
@@ -11,13 +14,13 @@ rm -rf $DIST
 mkdir -p $DIST
 
 # Linux amd64 (standard VPS)
-GOOS=linux GOARCH=amd64 go build -o $APP .
+GOOS=linux GOARCH=amd64 go build -ldflags "$LDFLAGS" -o $APP .
 tar -czf $DIST/${APP}-${VERSION}-linux-amd64.tar.gz \
     $APP templates/ static/ install.sh
 rm $APP
 
 # Linux arm64 (ARM VPS)
-GOOS=linux GOARCH=arm64 go build -o $APP .
+GOOS=linux GOARCH=arm64 go build -ldflags "$LDFLAGS" -o $APP .
 tar -czf $DIST/${APP}-${VERSION}-linux-arm64.tar.gz \
     $APP templates/ static/ install.sh
 rm $APP
@@ -7,15 +7,53 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gorilla/sessions"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Config holds application configuration
 type Config struct {
-	ServerFolderPath string `json:"server_folder_path"`
-	Port             string `json:"port"`
-	SessionSecret    string `json:"session_secret"`
+	ServerFolderPath     string   `json:"server_folder_path"`
+	Port                 string   `json:"port"`
+	SessionSecret        string   `json:"session_secret"`
+	SessionCookieName    string   `json:"session_cookie_name,omitempty"`             // Name of the login session cookie, empty = auth-session
+	SessionCookieDomain  string   `json:"session_cookie_domain,omitempty"`           // Domain the session cookie is scoped to, empty = the panel's host only
+	ReleaseURL           string   `json:"release_url,omitempty"`                     // Latest-release endpoint for update checks
+	DisableUpdateCheck   bool     `json:"disable_update_check,omitempty"`            // Never contact the release URL
+	NotifyWebhookURL     string   `json:"notify_webhook_url,omitempty"`              // Webhook that receives panel notifications
+	BcryptCost           int      `json:"bcrypt_cost,omitempty"`                     // Password hashing cost, 0 = bcrypt default
+	WSMaxPerUser         int      `json:"ws_max_per_user,omitempty"`                 // Open WebSockets allowed per user, 0 = default, -1 = unlimited
+	WSMaxPerServer       int      `json:"ws_max_per_server,omitempty"`               // Open WebSockets allowed per server, 0 = default, -1 = unlimited
+	URLFetchAllowHosts   []string `json:"url_fetch_allow_hosts,omitempty"`           // Hosts files may be fetched from, empty = any public host
+	URLFetchDenyHosts    []string `json:"url_fetch_deny_hosts,omitempty"`            // Hosts files may never be fetched from
+	ScheduleRunKeep      int      `json:"schedule_run_keep,omitempty"`               // Run history entries kept per schedule, 0 = default
+	ScheduleRunMaxAge    int      `json:"schedule_run_max_age_days,omitempty"`       // Days run history is kept, 0 = no age limit
+	ConsoleMaxLine       int      `json:"console_max_line_bytes,omitempty"`          // Console line length before truncation, 0 = default
+	ConsoleMaxBuffer     int      `json:"console_max_buffer_bytes,omitempty"`        // Console output kept in memory per server, 0 = default
+	ConsoleMaxRate       int      `json:"console_max_lines_per_sec,omitempty"`       // Console lines broadcast per second per server, 0 = default, -1 = unlimited
+	TempMaxAgeHours      int      `json:"temp_max_age_hours,omitempty"`              // Hours before leftovers of interrupted operations are removed, 0 = default
+	LiveConfigPatterns   []string `json:"live_config_patterns,omitempty"`            // Files the running server holds open, saving them needs force; empty = defaults
+	ScheduleRetries      int      `json:"schedule_backup_retries,omitempty"`         // Extra attempts for a failed scheduled backup, 0 = default, -1 = none
+	ScheduleRetryDelay   int      `json:"schedule_backup_retry_delay_sec,omitempty"` // Seconds before the first retry, doubling each time, 0 = default
+	IncludeBackupDirs    bool     `json:"include_backup_dirs,omitempty"`             // Let backups, sizes, archives and copies descend into backup folders inside server folders
+	UploadGunzipMaxMB    int      `json:"upload_gunzip_max_mb,omitempty"`            // Largest decompressed size of a .gz upload unpacked on arrival, 0 = default
+	ExtractMaxMB         int      `json:"extract_max_mb,omitempty"`                  // Largest total size written by one archive extraction, 0 = default
+	BackupJitterSec      int      `json:"schedule_backup_jitter_sec,omitempty"`      // Largest random delay before a timed scheduled backup starts, 0 = none
+	DiskAlertFreeMB      int      `json:"disk_alert_free_mb,omitempty"`              // Free space on the server or backup volumes below which a notification fires, 0 = default, -1 = never
+	DiskCheckIntervalSec int      `json:"disk_check_interval_sec,omitempty"`         // Seconds between free space checks, 0 = default
+	DiskReserveMB        int      `json:"disk_reserve_mb,omitempty"`                 // Free space uploads, copies and backups must leave on their volume, 0 = none
+	FileJournal          bool     `json:"file_journal,omitempty"`                    // Journal deletes, moves and overwrites in the file manager so the latest can be undone
+	FileJournalMaxFileMB int      `json:"file_journal_max_file_mb,omitempty"`        // Largest file copied aside before it is deleted or overwritten, 0 = default
+	FileJournalStashMB   int      `json:"file_journal_stash_mb,omitempty"`           // Total size of copies kept for undo, oldest dropped first, 0 = default
+	CopyConcurrency      int      `json:"copy_concurrency,omitempty"`                // Files copied at once when copying a folder, 0 = default, 1 = one at a time
+	DownloadBufferKB     int      `json:"download_buffer_kb,omitempty"`              // Read size when streaming downloads and backups, 0 = default, letting the OS copy files directly
+	BackupPerServerDirs  bool     `json:"backup_per_server_dirs,omitempty"`          // Store each server's backups in a subfolder of its backup path named after the server
+	MaxServersPerUser    int      `json:"max_servers_per_user,omitempty"`            // Servers each user may have, 0 = unlimited
+	OperationTimeoutSec  int      `json:"operation_timeout_sec,omitempty"`           // Seconds a request waits on file system checks and walks that may hang on network mounts, 0 = default
+	AutoStartDelaySec    int      `json:"auto_start_delay_sec,omitempty"`            // Seconds between servers started on panel boot, 0 = default, -1 = no delay
 }
 
 var (
@@ -28,6 +66,12 @@ func Init() {
 	// Load or create config
 	AppConfig = loadConfig()
 
+	// A name that isn't a valid cookie token would silently break logins
+	if name := AppConfig.SessionCookieName; name != "" && strings.ContainsAny(name, " \t\"(),/:;<=>?@[\\]{}") {
+		log.Printf("⚠️  Invalid session cookie name %q, using %s", name, DefaultSessionCookieName)
+		AppConfig.SessionCookieName = ""
+	}
+
 	// Initialize session store
 	SessionStore = sessions.NewCookieStore([]byte(AppConfig.SessionSecret))
 	SessionStore.Options = &sessions.Options{
@@ -35,6 +79,7 @@ func Init() {
 		MaxAge:   86400 * 7, // 7 days
 		HttpOnly: true,
 		SameSite: http.SameSiteLaxMode,
+		Domain:   GetSessionCookieDomain(),
 	}
 
 	log.Println("✅ Configuration loaded successfully")
@@ -89,6 +134,324 @@ func UpdateServerPath(path string) error {
 	return saveConfig(AppConfig)
 }
 
+// UpdateNotifyWebhookURL updates the notification webhook URL (empty disables notifications)
+func UpdateNotifyWebhookURL(url string) error {
+	AppConfig.NotifyWebhookURL = url
+	return saveConfig(AppConfig)
+}
+
+// GetNotifyWebhookURL returns the configured notification webhook URL
+func GetNotifyWebhookURL() string {
+	if AppConfig == nil {
+		return ""
+	}
+	return AppConfig.NotifyWebhookURL
+}
+
+// GetBcryptCost returns the configured password hashing cost, clamped to bcrypt's valid range
+func GetBcryptCost() int {
+	if AppConfig == nil || AppConfig.BcryptCost == 0 {
+		return bcrypt.DefaultCost
+	}
+	if AppConfig.BcryptCost < bcrypt.MinCost {
+		return bcrypt.MinCost
+	}
+	if AppConfig.BcryptCost > bcrypt.MaxCost {
+		return bcrypt.MaxCost
+	}
+	return AppConfig.BcryptCost
+}
+
+// Default WebSocket connection limits
+const (
+	DefaultWSMaxPerUser   = 10
+	DefaultWSMaxPerServer = 20
+)
+
+// GetWebSocketLimits returns the per-user and per-server WebSocket limits (0 = unlimited)
+func GetWebSocketLimits() (int, int) {
+	perUser, perServer := DefaultWSMaxPerUser, DefaultWSMaxPerServer
+	if AppConfig == nil {
+		return perUser, perServer
+	}
+	if AppConfig.WSMaxPerUser != 0 {
+		perUser = max(AppConfig.WSMaxPerUser, 0)
+	}
+	if AppConfig.WSMaxPerServer != 0 {
+		perServer = max(AppConfig.WSMaxPerServer, 0)
+	}
+	return perUser, perServer
+}
+
+// GetURLFetchHosts returns the allow and deny host lists for fetching files from URLs
+func GetURLFetchHosts() ([]string, []string) {
+	if AppConfig == nil {
+		return nil, nil
+	}
+	return AppConfig.URLFetchAllowHosts, AppConfig.URLFetchDenyHosts
+}
+
+// DefaultScheduleRunKeep is how many runs are kept per schedule unless configured
+const DefaultScheduleRunKeep = 50
+
+// GetScheduleRunRetention returns how many runs to keep per schedule and their maximum age in days (0 = no limit)
+func GetScheduleRunRetention() (int, int) {
+	if AppConfig == nil {
+		return DefaultScheduleRunKeep, 0
+	}
+	keep := AppConfig.ScheduleRunKeep
+	if keep <= 0 {
+		keep = DefaultScheduleRunKeep
+	}
+	return keep, max(AppConfig.ScheduleRunMaxAge, 0)
+}
+
+// UpdateScheduleRunRetention updates the schedule run history retention
+func UpdateScheduleRunRetention(keep, maxAgeDays int) error {
+	AppConfig.ScheduleRunKeep = keep
+	AppConfig.ScheduleRunMaxAge = maxAgeDays
+	return saveConfig(AppConfig)
+}
+
+// Default console output capture limits
+const (
+	DefaultConsoleMaxLine   = 4096
+	DefaultConsoleMaxBuffer = 2 * 1024 * 1024
+	DefaultConsoleMaxRate   = 200
+)
+
+// GetConsoleLimits returns the console line length cap, retained buffer cap (both in bytes)
+// and broadcast rate in lines per second (0 = unlimited)
+func GetConsoleLimits() (int, int, int) {
+	maxLine, maxBuffer, maxRate := DefaultConsoleMaxLine, DefaultConsoleMaxBuffer, DefaultConsoleMaxRate
+	if AppConfig == nil {
+		return maxLine, maxBuffer, maxRate
+	}
+	if AppConfig.ConsoleMaxLine > 0 {
+		maxLine = AppConfig.ConsoleMaxLine
+	}
+	if AppConfig.ConsoleMaxBuffer > 0 {
+		maxBuffer = AppConfig.ConsoleMaxBuffer
+	}
+	if AppConfig.ConsoleMaxRate != 0 {
+		maxRate = max(AppConfig.ConsoleMaxRate, 0)
+	}
+	return maxLine, maxBuffer, maxRate
+}
+
+// DefaultTempMaxAgeHours is how old a leftover temporary file must be before cleanup removes it
+const DefaultTempMaxAgeHours = 24
+
+// GetTempMaxAge returns the age after which leftover temporary files are removed
+func GetTempMaxAge() time.Duration {
+	hours := DefaultTempMaxAgeHours
+	if AppConfig != nil && AppConfig.TempMaxAgeHours > 0 {
+		hours = AppConfig.TempMaxAgeHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// DefaultLiveConfigPatterns are files a running Minecraft server reads or rewrites on its own,
+// so saving them while it runs is likely to be lost or half-read
+var DefaultLiveConfigPatterns = []string{
+	"server.properties",
+	"ops.json",
+	"whitelist.json",
+	"banned-players.json",
+	"banned-ips.json",
+	"usercache.json",
+}
+
+// GetLiveConfigPatterns returns the glob patterns for files that are risky to edit while the server runs
+func GetLiveConfigPatterns() []string {
+	if AppConfig == nil || len(AppConfig.LiveConfigPatterns) == 0 {
+		return DefaultLiveConfigPatterns
+	}
+	return AppConfig.LiveConfigPatterns
+}
+
+// GetIncludeBackupDirs reports whether folder walks may descend into server backup folders
+func GetIncludeBackupDirs() bool {
+	return AppConfig != nil && AppConfig.IncludeBackupDirs
+}
+
+// DefaultUploadGunzipMaxMB bounds how large a .gz upload may grow when unpacked on arrival
+const DefaultUploadGunzipMaxMB = 1024
+
+// GetUploadGunzipMaxBytes returns the largest decompressed size accepted for a .gz upload
+func GetUploadGunzipMaxBytes() int64 {
+	mb := DefaultUploadGunzipMaxMB
+	if AppConfig != nil && AppConfig.UploadGunzipMaxMB > 0 {
+		mb = AppConfig.UploadGunzipMaxMB
+	}
+	return int64(mb) << 20
+}
+
+// DefaultExtractMaxMB bounds how much one extraction may write, so an archive bomb can't fill the disk
+const DefaultExtractMaxMB = 10240
+
+// GetExtractMaxBytes returns the largest total size one archive extraction may write
+func GetExtractMaxBytes() int64 {
+	mb := DefaultExtractMaxMB
+	if AppConfig != nil && AppConfig.ExtractMaxMB > 0 {
+		mb = AppConfig.ExtractMaxMB
+	}
+	return int64(mb) << 20
+}
+
+// Default retry policy for scheduled backups
+const (
+	DefaultScheduleRetries    = 2
+	DefaultScheduleRetryDelay = 30 * time.Second
+)
+
+// GetScheduleRetry returns how many times a failed scheduled backup is retried and the delay
+// before the first retry
+func GetScheduleRetry() (int, time.Duration) {
+	retries, delay := DefaultScheduleRetries, DefaultScheduleRetryDelay
+	if AppConfig == nil {
+		return retries, delay
+	}
+	if AppConfig.ScheduleRetries > 0 {
+		retries = AppConfig.ScheduleRetries
+	} else if AppConfig.ScheduleRetries < 0 {
+		retries = 0
+	}
+	if AppConfig.ScheduleRetryDelay > 0 {
+		delay = time.Duration(AppConfig.ScheduleRetryDelay) * time.Second
+	}
+	return retries, delay
+}
+
+// GetBackupPerServerDirs reports whether new backups go in a per-server subfolder of the backup path
+func GetBackupPerServerDirs() bool {
+	return AppConfig != nil && AppConfig.BackupPerServerDirs
+}
+
+// GetScheduleBackupJitter returns the window within which timed scheduled backups are randomly
+// delayed, so backups sharing a fire time don't all start at once
+func GetScheduleBackupJitter() time.Duration {
+	if AppConfig == nil || AppConfig.BackupJitterSec <= 0 {
+		return 0
+	}
+	return time.Duration(AppConfig.BackupJitterSec) * time.Second
+}
+
+// Default free space monitoring
+const (
+	DefaultDiskAlertFreeMB      = 2048
+	DefaultDiskCheckIntervalSec = 300
+)
+
+// GetDiskAlert returns the free space below which a volume holding servers or backups counts
+// as low (0 = never alert) and how often free space is checked
+func GetDiskAlert() (uint64, time.Duration) {
+	freeMB, intervalSec := DefaultDiskAlertFreeMB, DefaultDiskCheckIntervalSec
+	if AppConfig != nil {
+		if AppConfig.DiskAlertFreeMB != 0 {
+			freeMB = AppConfig.DiskAlertFreeMB
+		}
+		if AppConfig.DiskCheckIntervalSec > 0 {
+			intervalSec = AppConfig.DiskCheckIntervalSec
+		}
+	}
+	if freeMB < 0 {
+		freeMB = 0
+	}
+	return uint64(freeMB) * 1024 * 1024, time.Duration(intervalSec) * time.Second
+}
+
+// GetDiskReserve returns the free space, in bytes, that uploads, copies and backups refuse to
+// eat into (0 = no reserve)
+func GetDiskReserve() uint64 {
+	if AppConfig == nil || AppConfig.DiskReserveMB <= 0 {
+		return 0
+	}
+	return uint64(AppConfig.DiskReserveMB) << 20
+}
+
+// Default file journal limits
+const (
+	DefaultFileJournalMaxFileMB = 10
+	DefaultFileJournalStashMB   = 256
+)
+
+// GetFileJournal reports whether destructive file operations are journaled, the largest file
+// copied aside for undo and the total size of those copies, in bytes
+func GetFileJournal() (bool, int64, int64) {
+	maxFileMB, stashMB := DefaultFileJournalMaxFileMB, DefaultFileJournalStashMB
+	if AppConfig == nil {
+		return false, int64(maxFileMB) << 20, int64(stashMB) << 20
+	}
+	if AppConfig.FileJournalMaxFileMB > 0 {
+		maxFileMB = AppConfig.FileJournalMaxFileMB
+	}
+	if AppConfig.FileJournalStashMB > 0 {
+		stashMB = AppConfig.FileJournalStashMB
+	}
+	return AppConfig.FileJournal, int64(maxFileMB) << 20, int64(stashMB) << 20
+}
+
+// DefaultCopyConcurrency is how many files a folder copy writes at once unless configured
+const DefaultCopyConcurrency = 4
+
+// GetCopyConcurrency returns how many files a folder copy writes at once
+func GetCopyConcurrency() int {
+	if AppConfig == nil || AppConfig.CopyConcurrency <= 0 {
+		return DefaultCopyConcurrency
+	}
+	return AppConfig.CopyConcurrency
+}
+
+// DefaultOperationTimeoutSec is how long a request waits on a potentially blocking file
+// system operation unless configured
+const DefaultOperationTimeoutSec = 60
+
+// GetOperationTimeout returns how long a request waits on a file system operation that may
+// hang on an unresponsive network mount before giving up
+func GetOperationTimeout() time.Duration {
+	sec := DefaultOperationTimeoutSec
+	if AppConfig != nil && AppConfig.OperationTimeoutSec > 0 {
+		sec = AppConfig.OperationTimeoutSec
+	}
+	return time.Duration(sec) * time.Second
+}
+
+// DefaultAutoStartDelaySec is how long panel boot waits between auto-started servers unless configured
+const DefaultAutoStartDelaySec = 10
+
+// GetAutoStartDelay returns how long to wait between servers started on panel boot, so they
+// don't all load their worlds at once
+func GetAutoStartDelay() time.Duration {
+	if AppConfig == nil || AppConfig.AutoStartDelaySec == 0 {
+		return DefaultAutoStartDelaySec * time.Second
+	}
+	if AppConfig.AutoStartDelaySec < 0 {
+		return 0
+	}
+	return time.Duration(AppConfig.AutoStartDelaySec) * time.Second
+}
+
+// GetMaxServersPerUser returns how many servers each user may have (0 = unlimited)
+func GetMaxServersPerUser() int {
+	if AppConfig == nil || AppConfig.MaxServersPerUser <= 0 {
+		return 0
+	}
+	return AppConfig.MaxServersPerUser
+}
+
+// MaxDownloadBufferKB bounds the configured download read size, in KiB
+const MaxDownloadBufferKB = 16 * 1024
+
+// GetDownloadBufferSize returns the read size used when streaming downloads and backups, in
+// bytes, or 0 (the default) to leave copying files to the OS
+func GetDownloadBufferSize() int {
+	if AppConfig == nil || AppConfig.DownloadBufferKB <= 0 {
+		return 0
+	}
+	return min(AppConfig.DownloadBufferKB, MaxDownloadBufferKB) << 10
+}
+
 // GetServerPath returns the configured server folder path
 func GetServerPath() string {
 	return AppConfig.ServerFolderPath
@@ -103,7 +466,27 @@ func generateRandomSecret() string {
 	return base64.StdEncoding.EncodeToString(b)
 }
 
+// DefaultSessionCookieName is the login session cookie name used when none is configured
+const DefaultSessionCookieName = "auth-session"
+
+// GetSessionCookieName returns the name of the login session cookie
+func GetSessionCookieName() string {
+	if AppConfig == nil || AppConfig.SessionCookieName == "" {
+		return DefaultSessionCookieName
+	}
+	return AppConfig.SessionCookieName
+}
+
+// GetSessionCookieDomain returns the domain the session cookie is scoped to, empty for the
+// panel's own host
+func GetSessionCookieDomain() string {
+	if AppConfig == nil {
+		return ""
+	}
+	return AppConfig.SessionCookieDomain
+}
+
 // GetSessionStore returns the session store
 func GetSessionStore() *sessions.CookieStore {
 	return SessionStore
-}
\ No newline at end of file
+}
@@ -0,0 +1,27 @@
+package config
+
+// Build information, set at build time via ldflags:
+//
+//	go build -ldflags "-X seiapanel/config.Version=v1.0.0 -X seiapanel/config.Commit=abc1234 -X seiapanel/config.BuildDate=2024-01-01T00:00:00Z"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// ListenAddr is the address the panel's HTTP server listens on
+const ListenAddr = ":6767"
+
+// DefaultReleaseURL is the latest-release endpoint checked for updates when none is configured
+const DefaultReleaseURL = "https://api.github.com/repos/freyzamarshall02/SeiaPanel/releases/latest"
+
+// GetReleaseURL returns the configured release URL, or "" when update checks are disabled
+func GetReleaseURL() string {
+	if AppConfig == nil || AppConfig.DisableUpdateCheck {
+		return ""
+	}
+	if AppConfig.ReleaseURL != "" {
+		return AppConfig.ReleaseURL
+	}
+	return DefaultReleaseURL
+}
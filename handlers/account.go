@@ -19,7 +19,7 @@ func AccountPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 
 	tmpl, err := template.ParseFiles("templates/account.html")
 	if err != nil {
@@ -94,7 +94,7 @@ func UpdateUsername(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update session with new username
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 	session.Values["username"] = newUsername
 	session.Save(r, w)
 
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ServerOverview is one server's entry in the servers overview
+type ServerOverview struct {
+	ID             uint       `json:"id"`
+	Name           string     `json:"name"`
+	Status         string     `json:"status"`
+	IsRunning      bool       `json:"is_running"`
+	StartedAt      *time.Time `json:"started_at"`
+	CPUPercent     float64    `json:"cpu_percent"`
+	MemoryMB       float64    `json:"memory_mb"`
+	DiskBytes      *int64     `json:"disk_bytes"` // nil if the folder couldn't be measured
+	DiskTruncated  bool       `json:"disk_truncated"`
+	BackupCount    int64      `json:"backup_count"`
+	LatestBackupAt *time.Time `json:"latest_backup_at"`
+	ScheduleCount  int64      `json:"schedule_count"`
+	LastActivityAt *time.Time `json:"last_activity_at"`
+}
+
+// ServersOverview returns status, resource usage, backups, schedules and last activity
+// for all of the user's servers in one response - JSON API
+func ServersOverview(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	servers, err := models.GetServersByUserID(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to load servers",
+		})
+		return
+	}
+
+	serverIDs := make([]uint, len(servers))
+	for i, server := range servers {
+		serverIDs[i] = server.ID
+	}
+
+	activity, err := models.GetServerActivity(serverIDs)
+	if err != nil {
+		log.Printf("❌ Failed to load server activity: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to load server activity",
+		})
+		return
+	}
+
+	// Disk usage walks share one deadline, so a stuck mount can't hold the overview
+	statsCtx, cancel := services.WithOperationTimeout(r.Context())
+	defer cancel()
+
+	overview := make([]ServerOverview, 0, len(servers))
+	for i := range servers {
+		server := &servers[i]
+		entry := ServerOverview{
+			ID:             server.ID,
+			Name:           server.Name,
+			Status:         server.Status,
+			StartedAt:      server.StartedAt,
+			BackupCount:    activity[server.ID].BackupCount,
+			LatestBackupAt: activity[server.ID].LatestBackupAt,
+			ScheduleCount:  activity[server.ID].ScheduleCount,
+			LastActivityAt: activity[server.ID].LastActivityAt,
+		}
+
+		if stats, err := services.GetServerStats(server); err == nil {
+			entry.Status = stats.State
+			entry.IsRunning = stats.IsRunning
+			entry.CPUPercent = stats.CPUPercent
+			entry.MemoryMB = stats.MemoryMB
+		}
+
+		// Disk usage is cached per folder, so repeated polling stays cheap
+		if dirStats, err := services.GetDirStats(statsCtx, server.FolderPath); err == nil {
+			entry.DiskBytes = &dirStats.TotalSize
+			entry.DiskTruncated = dirStats.Truncated
+		}
+
+		// A start counts as activity even if nothing was logged since
+		if entry.StartedAt != nil && (entry.LastActivityAt == nil || entry.StartedAt.After(*entry.LastActivityAt)) {
+			entry.LastActivityAt = entry.StartedAt
+		}
+
+		overview = append(overview, entry)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"servers": overview,
+	})
+}
+
+// ListAPITokens lists the user's API tokens - AJAX JSON response
+func ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	tokens, err := models.GetAPITokensByUserID(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to load API tokens",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"tokens":  tokens,
+	})
+}
+
+// CreateAPIToken creates an API token and returns the plain token once - AJAX JSON response
+func CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Token name is required",
+		})
+		return
+	}
+
+	token, plain, err := models.CreateAPIToken(userID, name)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to create API token",
+		})
+		return
+	}
+
+	log.Printf("✅ API token '%s' created for user %d", token.Name, userID)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"message":     "API token created. Copy it now, it won't be shown again.",
+		"token":       token,
+		"plain_token": plain,
+	})
+}
+
+// DeleteAPIToken revokes one of the user's API tokens - AJAX JSON response
+func DeleteAPIToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	userID := middleware.GetUserID(r)
+
+	tokenID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid token ID",
+		})
+		return
+	}
+
+	token, err := models.GetAPITokenByID(uint(tokenID))
+	if err != nil || token.UserID != userID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "API token not found",
+		})
+		return
+	}
+
+	if err := token.Delete(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to delete API token",
+		})
+		return
+	}
+
+	log.Printf("✅ API token '%s' revoked for user %d", token.Name, userID)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "API token revoked",
+	})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+
+	"github.com/gorilla/mux"
+)
+
+// ListAuditLogs returns a page of a server's audit log, filtered by time range and search text
+func ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = 50
+	}
+	if perPage > 200 {
+		perPage = 200
+	}
+
+	q := models.AuditLogQuery{
+		UserID:   userID,
+		ServerID: server.ID,
+		Search:   query.Get("search"),
+		Page:     page,
+		PerPage:  perPage,
+	}
+
+	// Optional time range
+	if from := query.Get("from"); from != "" {
+		t, err := parseTimeParam(from)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid from timestamp (use RFC3339 or YYYY-MM-DD)",
+			})
+			return
+		}
+		q.From = &t
+	}
+	if to := query.Get("to"); to != "" {
+		t, err := parseTimeParam(to)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid to timestamp (use RFC3339 or YYYY-MM-DD)",
+			})
+			return
+		}
+		// A plain date includes the whole day
+		if len(to) == len("2006-01-02") {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		q.To = &t
+	}
+
+	entries, total, err := models.SearchAuditLogs(q)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to retrieve audit log",
+		})
+		return
+	}
+
+	totalPages := (total + int64(perPage) - 1) / int64(perPage)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"entries":     entries,
+		"page":        page,
+		"per_page":    perPage,
+		"total":       total,
+		"total_pages": totalPages,
+	})
+}
+
+// parseTimeParam parses a timestamp given as RFC3339 or a local YYYY-MM-DD date
+func parseTimeParam(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.ParseInLocation("2006-01-02", value, time.Local)
+	}
+	return t, err
+}
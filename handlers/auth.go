@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"html/template"
 	"net/http"
 
@@ -12,7 +13,7 @@ import (
 // LoginPage renders the login page
 func LoginPage(w http.ResponseWriter, r *http.Request) {
 	// Check if user is already logged in
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 	if userID, ok := session.Values["user_id"].(uint); ok && userID != 0 {
 		http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 		return
@@ -72,7 +73,7 @@ func Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create session
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 	session.Values["user_id"] = user.ID
 	session.Values["username"] = user.Username
 	session.Save(r, w)
@@ -88,7 +89,7 @@ func Login(w http.ResponseWriter, r *http.Request) {
 // RegisterPage renders the register page
 func RegisterPage(w http.ResponseWriter, r *http.Request) {
 	// Check if user is already logged in
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 	if userID, ok := session.Values["user_id"].(uint); ok && userID != 0 {
 		http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 		return
@@ -179,6 +180,14 @@ func Register(w http.ResponseWriter, r *http.Request) {
 
 	// Create user
 	_, err := models.CreateUser(username, password)
+	if errors.Is(err, models.ErrUserLimitReached) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Registration is disabled. An account already exists.",
+		})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -199,7 +208,7 @@ func Register(w http.ResponseWriter, r *http.Request) {
 // Logout handles user logout
 func Logout(w http.ResponseWriter, r *http.Request) {
 	// Clear session
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 	session.Values["user_id"] = uint(0)
 	session.Values["username"] = ""
 	session.Options.MaxAge = -1
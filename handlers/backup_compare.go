@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// CompareBackups lists the files added, removed and changed between two backups of a server,
+// read from their archive headers without extracting either - AJAX JSON response. The backups
+// are given as "a" and "b" and always compared from the older to the newer one.
+func CompareBackups(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	older, ok := compareBackupForRequest(w, server, r.FormValue("a"))
+	if !ok {
+		return
+	}
+	newer, ok := compareBackupForRequest(w, server, r.FormValue("b"))
+	if !ok {
+		return
+	}
+	if older.ID == newer.ID {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Choose two different backups",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+	if newer.CreatedAt.Before(older.CreatedAt) {
+		older, newer = newer, older
+	}
+
+	result, err := services.CompareBackups(older.FilePath, newer.FilePath)
+	if err != nil {
+		log.Printf("⚠️  Failed to compare backups %s and %s: %v", older.FileName, newer.FileName, err)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    ErrCodeBackupUnreadable,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"from":      older,
+		"to":        newer,
+		"added":     result.Added,
+		"removed":   result.Removed,
+		"changed":   result.Changed,
+		"unchanged": result.Unchanged,
+	})
+}
+
+// compareBackupForRequest loads one of the backups being compared, writing the error
+// response and returning false when it can't
+func compareBackupForRequest(w http.ResponseWriter, server *models.Server, backupIDStr string) (*models.Backup, bool) {
+	// Parse backup ID
+	backupID, err := strconv.ParseUint(backupIDStr, 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid backup ID",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return nil, false
+	}
+
+	// Get backup and verify it belongs to this server
+	backup, err := models.GetBackupByID(uint(backupID))
+	if err != nil || backup.ServerID != server.ID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup not found",
+			"code":    ErrCodeBackupNotFound,
+		})
+		return nil, false
+	}
+
+	if _, err := os.Stat(backup.FilePath); os.IsNotExist(err) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup file not found on disk",
+			"code":    ErrCodeBackupNotFound,
+		})
+		return nil, false
+	}
+
+	return backup, true
+}
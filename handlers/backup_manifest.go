@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// GetBackupManifest returns the configuration recorded with a backup, such as its schedules - AJAX JSON response
+func GetBackupManifest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, manifest, ok := backupManifestForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"manifest": manifest,
+	})
+}
+
+// RestoreBackupSchedules recreates schedules recorded in a backup's manifest, without touching
+// any files - AJAX JSON response. "names" (a JSON array) limits the restore to some schedules;
+// schedules whose name is taken are skipped, or restored under a new name with on_conflict=rename.
+func RestoreBackupSchedules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	var names []string
+	if namesJSON := r.FormValue("names"); namesJSON != "" {
+		if err := json.Unmarshal([]byte(namesJSON), &names); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid names format",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+	}
+
+	onConflict := r.FormValue("on_conflict")
+	if onConflict == "" {
+		onConflict = "skip"
+	}
+	if onConflict != "skip" && onConflict != "rename" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "on_conflict must be skip or rename",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	server, manifest, ok := backupManifestForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Pick the requested schedules, reporting names the manifest doesn't have
+	selected := manifest.Schedules
+	var missing []string
+	if len(names) > 0 {
+		byName := make(map[string]services.ManifestSchedule, len(manifest.Schedules))
+		for _, schedule := range manifest.Schedules {
+			byName[schedule.Name] = schedule
+		}
+		selected = make([]services.ManifestSchedule, 0, len(names))
+		for _, name := range names {
+			if schedule, exists := byName[name]; exists {
+				selected = append(selected, schedule)
+			} else {
+				missing = append(missing, name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Schedules not found in backup: " + strings.Join(missing, ", "),
+			"code":    ErrCodeScheduleNotFound,
+		})
+		return
+	}
+
+	restored := make([]*models.Schedule, 0, len(selected))
+	skipped := make([]map[string]string, 0)
+	failed := make([]map[string]string, 0)
+	for _, saved := range selected {
+		schedule, err := restoreManifestSchedule(server.ID, saved, onConflict == "rename")
+		if errors.Is(err, models.ErrScheduleNameTaken) {
+			skipped = append(skipped, map[string]string{"name": saved.Name, "reason": err.Error()})
+			continue
+		}
+		if err != nil {
+			failed = append(failed, map[string]string{"name": saved.Name, "error": err.Error()})
+			continue
+		}
+
+		// Register with the scheduler like a newly created schedule
+		if schedule.Enabled {
+			if scheduleService := services.GetScheduleService(); scheduleService != nil {
+				if err := scheduleService.AddSchedule(*schedule); err != nil {
+					log.Printf("⚠️  Restored schedule '%s' could not be scheduled: %v", schedule.Name, err)
+				}
+			}
+		}
+		restored = append(restored, schedule)
+	}
+
+	if len(restored) == 0 && len(failed) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "No schedules could be restored",
+			"code":    ErrCodeInvalidRequest,
+			"skipped": skipped,
+			"failed":  failed,
+		})
+		return
+	}
+
+	log.Printf("✅ Restored %d schedule(s) for '%s' from a backup manifest", len(restored), server.Name)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"message":  fmt.Sprintf("Restored %d schedule(s), skipped %d, failed %d", len(restored), len(skipped), len(failed)),
+		"partial":  len(failed) > 0,
+		"restored": restored,
+		"skipped":  skipped,
+		"failed":   failed,
+	})
+}
+
+// restoreManifestSchedule creates a schedule from its manifest definition. Creating it runs the
+// same validation as a new schedule. With rename, a taken name gets a "(restored)" suffix.
+func restoreManifestSchedule(serverID uint, saved services.ManifestSchedule, rename bool) (*models.Schedule, error) {
+	for n := 1; ; n++ {
+		name := saved.Name
+		if n == 2 {
+			name = saved.Name + " (restored)"
+		} else if n > 2 {
+			name = fmt.Sprintf("%s (restored %d)", saved.Name, n-1)
+		}
+
+		schedule, err := models.CreateSchedule(serverID, name, saved.CronMinute, saved.CronHour, saved.CronDayOfMonth, saved.CronMonth, saved.CronDayOfWeek, saved.Enabled, saved.CatchUp, saved.SkipOverlap, saved.Action, saved.Command)
+		if errors.Is(err, models.ErrScheduleNameTaken) && rename {
+			continue
+		}
+		return schedule, err
+	}
+}
+
+// backupManifestForRequest loads the manifest of the backup named in the URL, writing the
+// error response and returning false when it can't
+func backupManifestForRequest(w http.ResponseWriter, r *http.Request) (*models.Server, *services.BackupManifest, bool) {
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	backupIDStr := vars["id"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return nil, nil, false
+	}
+
+	// Parse backup ID
+	backupID, err := strconv.ParseUint(backupIDStr, 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid backup ID",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return nil, nil, false
+	}
+
+	// Get backup and verify it belongs to this server
+	backup, err := models.GetBackupByID(uint(backupID))
+	if err != nil || backup.ServerID != server.ID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup not found",
+			"code":    ErrCodeBackupNotFound,
+		})
+		return nil, nil, false
+	}
+
+	if _, err := os.Stat(backup.FilePath); os.IsNotExist(err) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup file not found on disk",
+			"code":    ErrCodeBackupNotFound,
+		})
+		return nil, nil, false
+	}
+
+	manifest, err := services.ReadBackupManifest(backup.FilePath)
+	if errors.Is(err, services.ErrBackupManifestNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "This backup was made before configuration was recorded with backups",
+			"code":    ErrCodeNotFound,
+		})
+		return nil, nil, false
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    ErrCodeBackupUnreadable,
+		})
+		return nil, nil, false
+	}
+
+	return server, manifest, true
+}
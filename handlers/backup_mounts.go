@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// MountBackupTemp extracts a backup into a temporary, read-only location that the file
+// listing, read and download endpoints can browse with mount=<id> - AJAX JSON response
+func MountBackupTemp(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	backupIDStr := vars["id"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse backup ID
+	backupID, err := strconv.ParseUint(backupIDStr, 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid backup ID",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Get backup and verify it belongs to this server
+	backup, err := models.GetBackupByID(uint(backupID))
+	if err != nil || backup.ServerID != server.ID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup not found",
+			"code":    ErrCodeBackupNotFound,
+		})
+		return
+	}
+
+	if _, err := os.Stat(backup.FilePath); os.IsNotExist(err) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup file not found on disk",
+			"code":    ErrCodeBackupNotFound,
+		})
+		return
+	}
+
+	mount, err := services.MountBackup(backup)
+	if err != nil {
+		log.Printf("❌ Failed to mount backup %s: %v", backup.FileName, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to mount backup: " + err.Error(),
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Backup mounted for browsing",
+		"mount":   mount,
+	})
+}
+
+// UnmountBackupTemp removes a temporary backup mount before it expires - AJAX JSON response
+func UnmountBackupTemp(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	mountID := vars["mount"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	if _, ok := services.GetBackupMount(server.ID, mountID); !ok || !services.UnmountBackup(mountID) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup mount not found",
+			"code":    ErrCodeMountNotFound,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Backup unmounted",
+	})
+}
+
+// fileRootForRequest returns the root that browsing requests resolve paths against and the
+// boundary they must stay inside: the server's file root, or a backup mount when the request
+// names one with mount=<id>. ok is false for unknown or expired mounts.
+func fileRootForRequest(r *http.Request, server *models.Server) (root, boundary string, ok bool) {
+	mountID := r.FormValue("mount")
+	if mountID == "" {
+		return server.FileRootPath(), server.FolderPath, true
+	}
+
+	mount, exists := services.GetBackupMount(server.ID, mountID)
+	if !exists {
+		return "", "", false
+	}
+	return mount.Path, mount.Path, true
+}
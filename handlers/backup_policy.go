@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ListBackupPolicies returns all backup policies for the current user
+func ListBackupPolicies(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	policies, err := models.GetBackupPoliciesByUserID(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to retrieve backup policies",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	// Include the servers each policy currently applies to
+	formattedPolicies := make([]map[string]interface{}, 0)
+	for _, policy := range policies {
+		serverNames := make([]string, 0)
+		if servers, err := models.GetServersByTag(userID, policy.Tag); err == nil {
+			for _, server := range servers {
+				serverNames = append(serverNames, server.Name)
+			}
+		}
+
+		formattedPolicies = append(formattedPolicies, map[string]interface{}{
+			"policy":  policy,
+			"servers": serverNames,
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"policies": formattedPolicies,
+	})
+}
+
+// CreateBackupPolicy creates a new tag-based backup policy
+func CreateBackupPolicy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	keepBackups, err := strconv.Atoi(r.FormValue("keep_backups"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Keep backups must be a number",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	enabledStr := r.FormValue("enabled")
+	enabled := enabledStr == "true" || enabledStr == "1"
+
+	policy, err := models.CreateBackupPolicy(
+		userID,
+		r.FormValue("name"),
+		r.FormValue("tag"),
+		r.FormValue("cron_minute"),
+		r.FormValue("cron_hour"),
+		r.FormValue("cron_day_of_month"),
+		r.FormValue("cron_month"),
+		r.FormValue("cron_day_of_week"),
+		keepBackups,
+		enabled,
+	)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Add to cron scheduler if enabled
+	scheduleService := services.GetScheduleService()
+	if scheduleService != nil {
+		if err := scheduleService.AddBackupPolicy(*policy); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": true,
+				"message": "Backup policy created but failed to add to scheduler",
+				"policy":  policy,
+			})
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Backup policy created successfully",
+		"policy":  policy,
+	})
+}
+
+// UpdateBackupPolicy updates an existing backup policy
+func UpdateBackupPolicy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	policy, ok := getOwnedBackupPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	keepBackups, err := strconv.Atoi(r.FormValue("keep_backups"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Keep backups must be a number",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	enabledStr := r.FormValue("enabled")
+	enabled := enabledStr == "true" || enabledStr == "1"
+
+	err = policy.Update(
+		r.FormValue("name"),
+		r.FormValue("tag"),
+		r.FormValue("cron_minute"),
+		r.FormValue("cron_hour"),
+		r.FormValue("cron_day_of_month"),
+		r.FormValue("cron_month"),
+		r.FormValue("cron_day_of_week"),
+		keepBackups,
+		enabled,
+	)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Update in cron scheduler
+	scheduleService := services.GetScheduleService()
+	if scheduleService != nil {
+		if err := scheduleService.UpdateBackupPolicy(*policy); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": true,
+				"message": "Backup policy updated but failed to update scheduler",
+				"policy":  policy,
+			})
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Backup policy updated successfully",
+		"policy":  policy,
+	})
+}
+
+// DeleteBackupPolicy deletes a backup policy
+func DeleteBackupPolicy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	policy, ok := getOwnedBackupPolicy(w, r)
+	if !ok {
+		return
+	}
+
+	// Remove from cron scheduler
+	scheduleService := services.GetScheduleService()
+	if scheduleService != nil {
+		scheduleService.RemoveBackupPolicy(policy.ID)
+	}
+
+	if err := policy.Delete(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to delete backup policy",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Backup policy deleted successfully",
+	})
+}
+
+// getOwnedBackupPolicy loads the policy from the {id} route variable and verifies ownership,
+// writing the error response itself when it fails
+func getOwnedBackupPolicy(w http.ResponseWriter, r *http.Request) (*models.BackupPolicy, bool) {
+	vars := mux.Vars(r)
+	userID := middleware.GetUserID(r)
+
+	policyID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid policy ID",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return nil, false
+	}
+
+	policy, err := models.GetBackupPolicyByID(uint(policyID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup policy not found",
+			"code":    ErrCodeNotFound,
+		})
+		return nil, false
+	}
+
+	if policy.UserID != userID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
+		})
+		return nil, false
+	}
+
+	return policy, true
+}
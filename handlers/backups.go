@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"archive/tar"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
+	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"seiapanel/middleware"
 	"seiapanel/models"
@@ -62,6 +69,7 @@ func GetBackupSettings(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -87,6 +95,7 @@ func UpdateBackupSettings(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -97,6 +106,7 @@ func UpdateBackupSettings(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -104,12 +114,23 @@ func UpdateBackupSettings(w http.ResponseWriter, r *http.Request) {
 	backupPath := r.FormValue("backup_path")
 	maxBackupsStr := r.FormValue("max_backups")
 
+	// Keep the current wrap setting unless the form provides one
+	wrapInFolder := server.WrapBackups
+	if wrapInFolderStr := r.FormValue("wrap_in_folder"); wrapInFolderStr != "" {
+		wrapInFolder = wrapInFolderStr == "true" || wrapInFolderStr == "1"
+	}
+	autoBackupOnStop := server.AutoBackupOnStop
+	if autoBackupStr := r.FormValue("auto_backup_on_stop"); autoBackupStr != "" {
+		autoBackupOnStop = autoBackupStr == "true" || autoBackupStr == "1"
+	}
+
 	// Validate inputs
 	if backupPath == "" {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Backup path is required",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -120,37 +141,55 @@ func UpdateBackupSettings(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Max backups must be between 1 and 3",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 
 	// Validate and create backup path if needed
-	if err := services.ValidateBackupPath(backupPath); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	if err := services.ValidateBackupPath(r.Context(), backupPath, server.FolderPath); err != nil {
+		status, code := http.StatusBadRequest, ErrCodeInvalidRequest
+		if errors.Is(err, services.ErrOperationTimeout) {
+			status, code = http.StatusGatewayTimeout, ErrCodeTimeout
+		}
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   fmt.Sprintf("Invalid backup path: %v", err),
+			"code":    code,
 		})
 		return
 	}
 
 	// Update settings
-	if err := server.UpdateBackupSettings(backupPath, maxBackups); err != nil {
+	if err := server.UpdateBackupSettings(backupPath, maxBackups, wrapInFolder, autoBackupOnStop); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to update settings",
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
 
+	services.InvalidateBackupDirs()
+
+	// Settings are saved either way, but flag paths that may collide with other servers
+	warnings := services.BackupPathWarnings(server, backupPath)
+	for _, warning := range warnings {
+		log.Printf("⚠️  Backup settings for %s: %s", server.Name, warning)
+	}
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
 		"message": "Backup settings updated successfully",
 		"data": map[string]interface{}{
-			"backup_path": backupPath,
-			"max_backups": maxBackups,
+			"backup_path":         backupPath,
+			"max_backups":         maxBackups,
+			"wrap_in_folder":      wrapInFolder,
+			"auto_backup_on_stop": autoBackupOnStop,
 		},
+		"warnings": warnings,
 	})
 }
 
@@ -168,6 +207,7 @@ func ListBackups(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -178,6 +218,7 @@ func ListBackups(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to retrieve backups",
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
@@ -191,6 +232,7 @@ func ListBackups(w http.ResponseWriter, r *http.Request) {
 			"file_size":    backup.FileSize,
 			"size_display": services.FormatFileSize(backup.FileSize),
 			"created_at":   backup.CreatedAt.Format("2006-01-02 15:04:05"),
+			"pinned":       backup.Pinned,
 		})
 	}
 
@@ -200,8 +242,9 @@ func ListBackups(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// CreateBackup creates a new backup for a server
-func CreateBackup(w http.ResponseWriter, r *http.Request) {
+// GetBackupRotationPreview lists the backups that rotation would delete on the next backup,
+// without deleting anything
+func GetBackupRotationPreview(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	vars := mux.Vars(r)
@@ -214,67 +257,165 @@ func CreateBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
 
-	// Check if backup path is set
-	if server.BackupPath == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	purged, err := services.RotateBackups(server.ID, server.MaxBackups, true)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   "Backup path not configured. Please set it in Settings first.",
+			"error":   fmt.Sprintf("Failed to preview rotation: %v", err),
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
 
-	// Rotate backups if needed (delete oldest if at limit)
-	if err := services.RotateBackups(server.ID, server.MaxBackups); err != nil {
+	count, err := models.CountUnpinnedBackups(server.ID)
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   fmt.Sprintf("Failed to rotate backups: %v", err),
+			"error":   "Failed to retrieve backups",
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
 
-	// Generate backup filename
-	fileName := services.GenerateBackupFileName(server.Name)
+	formattedBackups := make([]map[string]interface{}, 0, len(purged))
+	var purgedSize int64
+	for _, backup := range purged {
+		purgedSize += backup.FileSize
+		formattedBackups = append(formattedBackups, map[string]interface{}{
+			"id":           backup.ID,
+			"file_name":    backup.FileName,
+			"file_size":    backup.FileSize,
+			"size_display": services.FormatFileSize(backup.FileSize),
+			"created_at":   backup.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":             true,
+		"max_backups":         server.MaxBackups,
+		"backup_count":        count,
+		"over_limit":          int(count) > server.MaxBackups,
+		"backups":             formattedBackups,
+		"purged_size":         purgedSize,
+		"purged_size_display": services.FormatFileSize(purgedSize),
+	})
+}
+
+// CreateBackup creates a new backup for a server
+func CreateBackup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
 
-	// Create backup
-	backupPath, fileSize, err := services.CreateTarGzBackup(server.FolderPath, server.BackupPath, fileName)
+	server, err := models.GetServerByName(serverName, userID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   fmt.Sprintf("Failed to create backup: %v", err),
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
 
-	// Save backup record to database
-	backup, err := models.CreateBackup(server.ID, fileName, backupPath, fileSize)
-	if err != nil {
-		// Clean up backup file if database insert fails
-		os.Remove(backupPath)
+	// Check if backup path is set
+	if server.BackupPath == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup path not configured. Please set it in Settings first.",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// A restore or another backup running at the same time would leave a corrupt archive.
+	// The claim is held until the background job below finishes.
+	if err := services.BeginServerOperation(server.ID, "backup"); err != nil {
+		writeOperationInProgressError(w, err)
+		return
+	}
+
+	// Rotate backups if needed (delete oldest if at limit)
+	if _, err := services.RotateBackups(server.ID, server.MaxBackups, false); err != nil {
+		services.EndServerOperation(server.ID)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   "Failed to save backup record",
+			"error":   fmt.Sprintf("Failed to rotate backups: %v", err),
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"message": "Backup created successfully",
-		"backup": map[string]interface{}{
+	// Generate backup filename
+	fileName := services.GenerateBackupFileName(server.Name)
+
+	// Wrap entries in a top-level server folder (request value overrides the server setting)
+	wrapInFolder := server.WrapBackups
+	if wrapInFolderStr := r.FormValue("wrap_in_folder"); wrapInFolderStr != "" {
+		wrapInFolder = wrapInFolderStr == "true" || wrapInFolderStr == "1"
+	}
+	rootFolder := ""
+	if wrapInFolder {
+		rootFolder = server.Name
+	}
+
+	// Archive in the background and report progress through the job, so large
+	// servers don't leave the request hanging until it times out
+	job := services.NewJob(server.ID, "backup", []string{fileName})
+	job.Start(func(job *services.Job) error {
+		defer services.EndServerOperation(server.ID)
+
+		ctx, cancel := services.WithOperationTimeout(context.Background())
+		dirStats, err := services.GetDirStats(ctx, server.FolderPath)
+		cancel()
+		if err == nil {
+			job.AddTotal(dirStats.TotalSize)
+		}
+
+		// The backup is still worth taking if the schedules can't be recorded with it
+		manifest, err := services.NewBackupManifest(server)
+		if err != nil {
+			log.Printf("⚠️  Backup of '%s' made without its schedules: %v", server.Name, err)
+		}
+
+		backupPath, fileSize, err := services.CreateTarGzBackup(server.FolderPath, services.ServerBackupDir(server), fileName, rootFolder, manifest, job)
+		if err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+
+		// Save backup record to database
+		backup, err := models.CreateBackup(server.ID, fileName, backupPath, fileSize)
+		if err != nil {
+			// Clean up backup file if database insert fails
+			os.Remove(backupPath)
+			return fmt.Errorf("failed to save backup record: %w", err)
+		}
+
+		job.SetResult("backup", map[string]interface{}{
 			"id":           backup.ID,
 			"file_name":    backup.FileName,
 			"file_size":    backup.FileSize,
 			"size_display": services.FormatFileSize(backup.FileSize),
 			"created_at":   backup.CreatedAt.Format("2006-01-02 15:04:05"),
-		},
+		})
+		return nil
+	})
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Backup started",
+		"job_id":  job.ID,
 	})
 }
 
@@ -294,6 +435,7 @@ func DeleteBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -305,6 +447,7 @@ func DeleteBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid backup ID",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -316,6 +459,7 @@ func DeleteBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Backup not found",
+			"code":    ErrCodeBackupNotFound,
 		})
 		return
 	}
@@ -326,6 +470,7 @@ func DeleteBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
 		})
 		return
 	}
@@ -342,6 +487,7 @@ func DeleteBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to delete backup record",
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
@@ -352,6 +498,90 @@ func DeleteBackup(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// PinBackup pins or unpins a backup so rotation keeps it. The "pinned" form value sets the
+// state; without it the current state is toggled.
+func PinBackup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	backupIDStr := vars["id"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse backup ID
+	backupID, err := strconv.ParseUint(backupIDStr, 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid backup ID",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Get backup
+	backup, err := models.GetBackupByID(uint(backupID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup not found",
+			"code":    ErrCodeBackupNotFound,
+		})
+		return
+	}
+
+	// Verify backup belongs to this server
+	if backup.ServerID != server.ID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
+		})
+		return
+	}
+
+	pinned := !backup.Pinned
+	if pinnedStr := r.FormValue("pinned"); pinnedStr != "" {
+		pinned = pinnedStr == "true" || pinnedStr == "1"
+	}
+
+	if err := backup.SetPinned(pinned); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to update backup",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	message := "Backup unpinned"
+	if pinned {
+		message = "Backup pinned, rotation will keep it"
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": message,
+		"pinned":  pinned,
+	})
+}
+
 // DownloadBackup streams a backup file for download
 func DownloadBackup(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -406,7 +636,7 @@ func DownloadBackup(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Length", fmt.Sprintf("%d", backup.FileSize))
 
 	// Stream file to client
-	if _, err := io.Copy(w, file); err != nil {
+	if _, err := copyDownload(w, file); err != nil {
 		fmt.Printf("Error streaming backup file: %v\n", err)
 	}
 }
@@ -427,16 +657,35 @@ func RestoreBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
 
-	// Check if server is running
-	if server.Status == "online" {
+	// Claim the server before checking its state. A start refuses while the restore holds it,
+	// and a backup running at the same time would archive a half-restored folder.
+	if err := services.BeginServerOperation(server.ID, "restore"); err != nil {
+		writeOperationInProgressError(w, err)
+		return
+	}
+	defer services.EndServerOperation(server.ID)
+
+	// Check if server is running or mid start/stop
+	if state := services.GetServerTransition(server); state != "" {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Cannot restore while server is %s. Please wait and try again.", state),
+			"code":    ErrCodeServerRunning,
+		})
+		return
+	}
+	if server.Status == "online" || services.IsServerRunning(server) {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Cannot restore while server is running. Please stop the server first.",
+			"code":    ErrCodeServerRunning,
 		})
 		return
 	}
@@ -448,6 +697,7 @@ func RestoreBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid backup ID",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -459,6 +709,7 @@ func RestoreBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Backup not found",
+			"code":    ErrCodeBackupNotFound,
 		})
 		return
 	}
@@ -469,6 +720,7 @@ func RestoreBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
 		})
 		return
 	}
@@ -479,16 +731,44 @@ func RestoreBackup(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Backup file not found on disk",
+			"code":    ErrCodeBackupNotFound,
 		})
 		return
 	}
 
+	// The record belongs to this server, but the archive itself may have been swapped on disk.
+	// Check its contents before anything is cleared; a mismatch needs an explicit override.
+	if err := services.CheckBackupServer(backup.FilePath, server); err != nil {
+		allowMismatch := r.FormValue("allow_mismatch")
+		if !errors.Is(err, services.ErrBackupServerMismatch) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("Failed to read backup manifest: %v", err),
+				"code":    ErrCodeBackupUnreadable,
+			})
+			return
+		}
+		if allowMismatch != "true" && allowMismatch != "1" {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":           false,
+				"error":             fmt.Sprintf("Refusing to restore: %v", err),
+				"code":              ErrCodeBackupMismatch,
+				"requires_override": true,
+			})
+			return
+		}
+		log.Printf("⚠️  Restoring mismatched backup %s into '%s': %v", backup.FileName, server.Name, err)
+	}
+
 	// Perform restore operation
 	if err := services.RestoreBackupFromArchive(backup.FilePath, server.FolderPath); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   fmt.Sprintf("Failed to restore backup: %v", err),
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
@@ -497,4 +777,529 @@ func RestoreBackup(w http.ResponseWriter, r *http.Request) {
 		"success": true,
 		"message": fmt.Sprintf("Server restored successfully from backup: %s", backup.FileName),
 	})
-}
\ No newline at end of file
+}
+
+// DeleteFilteredBackups deletes every backup of a server matching the given criteria
+func DeleteFilteredBackups(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	olderThanStr := r.FormValue("older_than")
+	nameContains := r.FormValue("name_contains")
+	idsJSON := r.FormValue("ids")
+
+	if olderThanStr == "" && nameContains == "" && idsJSON == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "At least one filter is required (older_than, name_contains or ids)",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Parse older-than timestamp (RFC3339 or YYYY-MM-DD)
+	var olderThan time.Time
+	if olderThanStr != "" {
+		olderThan, err = parseTimeParam(olderThanStr)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid older_than timestamp (use RFC3339 or YYYY-MM-DD)",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+	}
+
+	// Parse explicit ID list
+	var ids []uint
+	if idsJSON != "" {
+		if err := json.Unmarshal([]byte(idsJSON), &ids); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid ids format",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+	}
+
+	var deleteErrors []string
+
+	// Explicit IDs must belong to this server
+	idSet := make(map[uint]bool)
+	for _, id := range ids {
+		backup, err := models.GetBackupByID(id)
+		if err != nil {
+			deleteErrors = append(deleteErrors, fmt.Sprintf("Backup %d not found", id))
+			continue
+		}
+		if backup.ServerID != server.ID {
+			deleteErrors = append(deleteErrors, fmt.Sprintf("Backup %d does not belong to this server", id))
+			continue
+		}
+		idSet[id] = true
+	}
+
+	backups, err := models.GetBackupsByServerID(server.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to retrieve backups",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	// Delete every backup matching all given criteria
+	matchedCount := 0
+	deletedCount := 0
+	for i := range backups {
+		backup := &backups[i]
+
+		if idsJSON != "" && !idSet[backup.ID] {
+			continue
+		}
+		if !olderThan.IsZero() && !backup.CreatedAt.Before(olderThan) {
+			continue
+		}
+		if nameContains != "" && !strings.Contains(strings.ToLower(backup.FileName), strings.ToLower(nameContains)) {
+			continue
+		}
+
+		matchedCount++
+
+		// Delete file from disk (it might already be gone)
+		if err := services.DeleteBackupFile(backup.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			deleteErrors = append(deleteErrors, fmt.Sprintf("%s: %v", backup.FileName, err))
+			continue
+		}
+
+		// Delete database record
+		if err := backup.Delete(); err != nil {
+			deleteErrors = append(deleteErrors, fmt.Sprintf("%s: failed to delete backup record", backup.FileName))
+			continue
+		}
+
+		deletedCount++
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": len(deleteErrors) == 0,
+		"message": fmt.Sprintf("Deleted %d of %d matching backup(s)", deletedCount, matchedCount),
+		"matched": matchedCount,
+		"deleted": deletedCount,
+		"errors":  deleteErrors,
+	})
+}
+
+// ReconcileBackups compares the server's backup records with its backup directory, reporting
+// records whose file is gone and untracked archives. prune=true deletes the stale records and
+// import=true creates records for untracked archives - AJAX JSON response
+func ReconcileBackups(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	pruneStr := r.FormValue("prune")
+	importStr := r.FormValue("import")
+	prune := pruneStr == "true" || pruneStr == "1"
+	importFiles := importStr == "true" || importStr == "1"
+
+	result, err := services.ReconcileBackups(server, prune, importFiles)
+	if err != nil {
+		log.Printf("❌ Failed to reconcile backups for server '%s': %v", server.Name, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to reconcile backups",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	if result.Pruned > 0 || len(result.Imported) > 0 {
+		log.Printf("🧹 Reconciled backups for server '%s': %d stale record(s) pruned, %d archive(s) imported", server.Name, result.Pruned, len(result.Imported))
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   len(result.Errors) == 0,
+		"message":   fmt.Sprintf("%d missing, %d untracked, %d pruned, %d imported", len(result.Missing), len(result.Untracked), result.Pruned, len(result.Imported)),
+		"missing":   result.Missing,
+		"untracked": result.Untracked,
+		"pruned":    result.Pruned,
+		"imported":  result.Imported,
+		"errors":    result.Errors,
+	})
+}
+
+// ExtractBackupFile streams a single file from a backup, or restores it into the
+// server folder when restore=true is posted, without extracting the whole archive
+func ExtractBackupFile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	backupIDStr := vars["id"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	// Parse backup ID
+	backupID, err := strconv.ParseUint(backupIDStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid backup ID", http.StatusBadRequest)
+		return
+	}
+
+	// Get backup
+	backup, err := models.GetBackupByID(uint(backupID))
+	if err != nil {
+		http.Error(w, "Backup not found", http.StatusNotFound)
+		return
+	}
+
+	// Verify backup belongs to this server
+	if backup.ServerID != server.ID {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	// Check if file exists
+	if _, err := os.Stat(backup.FilePath); os.IsNotExist(err) {
+		http.Error(w, "Backup file not found on disk", http.StatusNotFound)
+		return
+	}
+
+	entryPath := r.FormValue("path")
+	if entryPath == "" {
+		http.Error(w, "No file specified", http.StatusBadRequest)
+		return
+	}
+
+	// Restoring writes into the live directory, so only allow it on POST
+	if r.Method == http.MethodPost && r.FormValue("restore") == "true" {
+		restoreBackupEntry(w, server, backup, entryPath)
+		return
+	}
+
+	// Stream the entry to the client
+	err = services.ReadBackupEntry(backup.FilePath, entryPath, func(header *tar.Header, content io.Reader) error {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filepath.Base(header.Name)))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", header.Size))
+
+		if _, err := copyDownload(w, content); err != nil {
+			// Can't send error response here as headers are already sent
+			fmt.Printf("Error streaming backup entry: %v\n", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrBackupEntryNotFound) {
+			http.Error(w, "File not found in backup", http.StatusNotFound)
+		} else {
+			http.Error(w, fmt.Sprintf("Failed to read backup: %v", err), http.StatusInternalServerError)
+		}
+	}
+}
+
+// ValidateBackup reads a backup archive end to end to confirm it is structurally sound - AJAX JSON response
+func ValidateBackup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	backupIDStr := vars["id"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse backup ID
+	backupID, err := strconv.ParseUint(backupIDStr, 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid backup ID",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Get backup
+	backup, err := models.GetBackupByID(uint(backupID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup not found",
+			"code":    ErrCodeBackupNotFound,
+		})
+		return
+	}
+
+	// Verify backup belongs to this server
+	if backup.ServerID != server.ID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
+		})
+		return
+	}
+
+	// Check if file exists
+	if _, err := os.Stat(backup.FilePath); os.IsNotExist(err) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup file not found on disk",
+			"code":    ErrCodeBackupNotFound,
+		})
+		return
+	}
+
+	result := services.ValidateBackupArchive(backup.FilePath)
+	if !result.Valid {
+		log.Printf("⚠️  Backup %s failed validation: %s", backup.FileName, result.Error)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":          true,
+		"valid":            result.Valid,
+		"entries":          result.Entries,
+		"total_size":       result.TotalSize,
+		"size":             services.FormatFileSize(result.TotalSize),
+		"validation_error": result.Error,
+	})
+}
+
+// restoreBackupEntry writes a single backup entry back to its original location in the server folder
+func restoreBackupEntry(w http.ResponseWriter, server *models.Server, backup *models.Backup, entryPath string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Backup entries are relative to the server folder
+	targetPath := filepath.Join(server.FolderPath, strings.TrimPrefix(entryPath, "/"))
+
+	// Security check: ensure the path is within the server folder
+	cleanPath := filepath.Clean(targetPath)
+	if !strings.HasPrefix(cleanPath, server.FolderPath) || cleanPath == server.FolderPath {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	var written int64
+	err := services.ReadBackupEntry(backup.FilePath, entryPath, func(header *tar.Header, content io.Reader) error {
+		if err := os.MkdirAll(filepath.Dir(cleanPath), 0755); err != nil {
+			return fmt.Errorf("failed to create parent directory: %w", err)
+		}
+
+		// Write to a temp file first so a failed restore never leaves a truncated file
+		tmpPath := cleanPath + ".restore-tmp"
+		outFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+
+		written, err = io.Copy(outFile, content)
+		outFile.Close()
+		if err != nil {
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+
+		return os.Rename(tmpPath, cleanPath)
+	})
+	if err != nil {
+		status, code := http.StatusInternalServerError, ErrCodeInternal
+		if errors.Is(err, services.ErrBackupEntryNotFound) {
+			status, code = http.StatusNotFound, ErrCodeFileNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    code,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Restored %s from %s", entryPath, backup.FileName),
+		"size":    written,
+	})
+}
+
+// PreviewDiskImpact reports what a destructive operation would do to the server folder:
+// its current size and item count versus the result. For restore the result is the
+// backup's contents (backup_id required); for reset it is an empty folder.
+func PreviewDiskImpact(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	operation := r.URL.Query().Get("operation")
+
+	var result services.DirStats
+	switch operation {
+	case "reset":
+		// A reset leaves an empty folder
+	case "restore":
+		backupID, err := strconv.ParseUint(r.URL.Query().Get("backup_id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid backup ID",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+
+		backup, err := models.GetBackupByID(uint(backupID))
+		if err != nil || backup.ServerID != server.ID {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Backup not found",
+				"code":    ErrCodeBackupNotFound,
+			})
+			return
+		}
+
+		result, err = services.BackupContentStats(backup.FilePath)
+		if err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Failed to read backup: " + err.Error(),
+				"code":    ErrCodeBackupUnreadable,
+			})
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Operation must be restore or reset",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	ctx, cancel := services.WithOperationTimeout(r.Context())
+	defer cancel()
+
+	current, err := services.GetDirStats(ctx, server.FolderPath)
+	if err != nil {
+		status, code := http.StatusInternalServerError, ErrCodeInternal
+		if errors.Is(err, services.ErrOperationTimeout) {
+			status, code = http.StatusGatewayTimeout, ErrCodeTimeout
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to measure server folder: " + err.Error(),
+			"code":    code,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    true,
+		"operation":  operation,
+		"current":    current,
+		"result":     result,
+		"size_delta": result.TotalSize - current.TotalSize,
+	})
+}
+
+// writeOperationInProgressError responds to a backup or restore refused because another one
+// of the same server is still running
+func writeOperationInProgressError(w http.ResponseWriter, err error) {
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   err.Error() + ". Please wait and try again.",
+		"code":    ErrCodeInProgress,
+	})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"seiapanel/config"
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB points models.DB at a fresh database in a temporary folder for one test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "app.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Server{}, &models.Backup{}, &models.Schedule{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	previous := models.DB
+	models.DB = db
+	t.Cleanup(func() {
+		models.DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+}
+
+// TestRestoreRejectedDuringBackup checks that a restore started while a backup of the same
+// server is running is refused and leaves the server folder alone
+func TestRestoreRejectedDuringBackup(t *testing.T) {
+	setupTestDB(t)
+	useTestConfig(t, &config.Config{IncludeBackupDirs: true})
+
+	folder := t.TempDir()
+	if err := os.WriteFile(filepath.Join(folder, "server.properties"), []byte("motd=backed up"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := &models.Server{Name: "survival", FolderPath: folder, StartupCommand: "java -jar server.jar", BackupPath: t.TempDir(), UserID: 1}
+	if err := models.DB.Create(server).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &services.BackupManifest{Version: 1, Server: server.Name, ServerID: server.ID}
+	archive, size, err := services.CreateTarGzBackup(folder, server.BackupPath, "backup.tar.gz", "", manifest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup, err := models.CreateBackup(server.ID, "backup.tar.gz", archive, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "server.properties"), []byte("motd=current"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	restore := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/server/survival/backups/restore", nil)
+		r = mux.SetURLVars(r, map[string]string{"name": server.Name, "id": strconv.FormatUint(uint64(backup.ID), 10)})
+		r = r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, server.UserID))
+		w := httptest.NewRecorder()
+		RestoreBackup(w, r)
+		return w
+	}
+
+	// A backup of the server is running
+	if err := services.BeginServerOperation(server.ID, "backup"); err != nil {
+		t.Fatal(err)
+	}
+	w := restore()
+	services.EndServerOperation(server.ID)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("restore during backup: status = %d, want %d (%s)", w.Code, http.StatusConflict, w.Body)
+	}
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response["code"] != ErrCodeInProgress {
+		t.Errorf("restore during backup: code = %v, want %s", response["code"], ErrCodeInProgress)
+	}
+	data, err := os.ReadFile(filepath.Join(folder, "server.properties"))
+	if err != nil || string(data) != "motd=current" {
+		t.Errorf("server folder changed by a refused restore: %q, %v", data, err)
+	}
+
+	// Once the backup is done the restore goes through
+	if w := restore(); w.Code != http.StatusOK {
+		t.Fatalf("restore after backup: status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body)
+	}
+	data, err = os.ReadFile(filepath.Join(folder, "server.properties"))
+	if err != nil || string(data) != "motd=backed up" {
+		t.Errorf("restore after backup: server.properties = %q, %v", data, err)
+	}
+}
+
+// TestRestoreRefusedReleasesServer checks that a restore refused because the server is
+// running doesn't keep the server claimed, so it can still be backed up or started
+func TestRestoreRefusedReleasesServer(t *testing.T) {
+	setupTestDB(t)
+	useTestConfig(t, &config.Config{IncludeBackupDirs: true})
+
+	server := &models.Server{Name: "survival", FolderPath: t.TempDir(), StartupCommand: "java -jar server.jar", Status: "online", UserID: 1}
+	if err := models.DB.Create(server).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/server/survival/backups/restore", nil)
+	r = mux.SetURLVars(r, map[string]string{"name": server.Name, "id": "1"})
+	r = r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, server.UserID))
+	w := httptest.NewRecorder()
+	RestoreBackup(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("restore of a running server: status = %d, want %d (%s)", w.Code, http.StatusBadRequest, w.Body)
+	}
+	if operation := services.GetServerOperation(server.ID); operation != "" {
+		t.Errorf("server still claimed by %q after a refused restore", operation)
+	}
+}
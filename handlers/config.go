@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"seiapanel/config"
+	"seiapanel/models"
+	"seiapanel/services"
+)
+
+// redacted replaces secret values in the config report
+const redacted = "[redacted]"
+
+// GetPanelConfig reports the effective panel configuration with secrets redacted - JSON API.
+// SeiaPanel is single-user, so the signed-in account is the panel's administrator.
+func GetPanelConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wsPerUser, wsPerServer := config.GetWebSocketLimits()
+	allowHosts, denyHosts := config.GetURLFetchHosts()
+	runKeep, runMaxAge := config.GetScheduleRunRetention()
+	backupRetries, backupRetryDelay := config.GetScheduleRetry()
+	consoleMaxLine, consoleMaxBuffer, consoleMaxRate := config.GetConsoleLimits()
+	diskAlertFree, diskCheckInterval := config.GetDiskAlert()
+	journalEnabled, journalMaxFile, journalStash := config.GetFileJournal()
+
+	sessionSecret := ""
+	if config.AppConfig != nil && config.AppConfig.SessionSecret != "" {
+		sessionSecret = redacted
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"config": map[string]interface{}{
+			"version":            config.Version,
+			"commit":             config.Commit,
+			"build_date":         config.BuildDate,
+			"listen_addr":        config.ListenAddr,
+			"tls_enabled":        false, // The panel serves plain HTTP; TLS is left to a reverse proxy
+			"server_folder_path": config.GetServerPath(),
+			"session_secret":     sessionSecret,
+			"session_cookie": map[string]interface{}{
+				"name":   config.GetSessionCookieName(),
+				"domain": config.GetSessionCookieDomain(),
+			},
+			"bcrypt_cost":             config.GetBcryptCost(),
+			"upload_max_bytes":        maxUploadSize,
+			"upload_gunzip_max_bytes": config.GetUploadGunzipMaxBytes(),
+			"extract_max_bytes":       config.GetExtractMaxBytes(),
+			"backups": map[string]interface{}{
+				"default_max_backups": models.DefaultMaxBackups,
+				"max_backups_limit":   models.MaxBackupsLimit,
+				"per_server_dirs":     config.GetBackupPerServerDirs(),
+			},
+			"update_check": map[string]interface{}{
+				"enabled":     config.GetReleaseURL() != "",
+				"release_url": config.GetReleaseURL(),
+			},
+			"notify_webhook_url": redactURL(config.GetNotifyWebhookURL()),
+			"websocket_limits": map[string]interface{}{
+				"per_user":   wsPerUser,
+				"per_server": wsPerServer,
+			},
+			"url_fetch": map[string]interface{}{
+				"allow_hosts":     allowHosts,
+				"deny_hosts":      denyHosts,
+				"timeout_seconds": int(services.URLFetchTimeout.Seconds()),
+			},
+			"schedule_backup_retry": map[string]interface{}{
+				"retries":         backupRetries,
+				"first_delay_sec": int(backupRetryDelay.Seconds()),
+			},
+			"schedule_backup_jitter_sec": int(config.GetScheduleBackupJitter().Seconds()),
+			"schedule_run_history": map[string]interface{}{
+				"keep":         runKeep,
+				"max_age_days": runMaxAge,
+			},
+			"temp_max_age_hours":    int(config.GetTempMaxAge().Hours()),
+			"live_config_patterns":  config.GetLiveConfigPatterns(),
+			"include_backup_dirs":   config.GetIncludeBackupDirs(),
+			"copy_concurrency":      config.GetCopyConcurrency(),
+			"download_buffer_bytes": config.GetDownloadBufferSize(),
+			"max_servers_per_user":  config.GetMaxServersPerUser(),
+			"operation_timeout_sec": int(config.GetOperationTimeout().Seconds()),
+			"auto_start_delay_sec":  int(config.GetAutoStartDelay().Seconds()),
+			"console_limits": map[string]interface{}{
+				"max_line_bytes":    consoleMaxLine,
+				"max_buffer_bytes":  consoleMaxBuffer,
+				"max_lines_per_sec": consoleMaxRate,
+			},
+			"disk_monitor": map[string]interface{}{
+				"alert_free_bytes":   diskAlertFree,
+				"check_interval_sec": int(diskCheckInterval.Seconds()),
+				"reserve_bytes":      config.GetDiskReserve(),
+			},
+			"file_journal": map[string]interface{}{
+				"enabled":        journalEnabled,
+				"max_file_bytes": journalMaxFile,
+				"stash_bytes":    journalStash,
+			},
+		},
+	})
+}
+
+// redactURL keeps only the scheme and host of a URL, since webhook URLs often carry
+// a token in the path or query
+func redactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+	return u.Scheme + "://" + u.Host + "/" + redacted
+}
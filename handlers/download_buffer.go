@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"io"
+	"sync"
+
+	"seiapanel/config"
+)
+
+// downloadBuffers recycles download buffers so concurrent downloads don't each allocate one
+var downloadBuffers sync.Pool
+
+// copyDownload streams src to a download response. By default this is a plain io.Copy, so
+// local files go out through sendfile. A configured read size replaces net/http's 32 KiB
+// chunks for storage where larger reads pay off, such as network mounts, at the cost of
+// sendfile.
+func copyDownload(w io.Writer, src io.Reader) (int64, error) {
+	size := config.GetDownloadBufferSize()
+	if size == 0 {
+		return io.Copy(w, src)
+	}
+
+	buf, ok := downloadBuffers.Get().(*[]byte)
+	if !ok || len(*buf) != size {
+		b := make([]byte, size)
+		buf = &b
+	}
+	defer downloadBuffers.Put(buf)
+
+	// Hide ReadFrom and WriteTo, or io.CopyBuffer would bypass the buffer
+	return io.CopyBuffer(struct{ io.Writer }{w}, struct{ io.Reader }{src}, *buf)
+}
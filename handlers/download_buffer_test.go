@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"seiapanel/config"
+)
+
+// BenchmarkCopyDownload sends a file over a loopback TCP connection, as downloads do, with
+// the plain copy (sendfile) and with read buffers of a few sizes
+func BenchmarkCopyDownload(b *testing.B) {
+	const fileSize = 64 << 20
+	path := filepath.Join(b.TempDir(), "world.tar.gz")
+	if err := os.WriteFile(path, make([]byte, fileSize), 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Skipf("loopback not available: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	for _, kb := range []int{0, 256, 1024} {
+		b.Run(fmt.Sprintf("buffer=%dKiB", kb), func(b *testing.B) {
+			useTestConfig(b, &config.Config{DownloadBufferKB: kb})
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer conn.Close()
+
+			b.SetBytes(fileSize)
+			for i := 0; i < b.N; i++ {
+				file, err := os.Open(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := copyDownload(conn, file); err != nil {
+					b.Fatal(err)
+				}
+				file.Close()
+			}
+		})
+	}
+}
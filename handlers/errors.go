@@ -0,0 +1,28 @@
+package handlers
+
+// Error codes sent in the "code" field of JSON error responses. Scripts and the frontend should
+// match on these rather than on the "error" message, which is meant for display and may change.
+const (
+	ErrCodeInvalidRequest   = "INVALID_REQUEST"    // Missing or malformed parameters
+	ErrCodeServerNotFound   = "SERVER_NOT_FOUND"   // No such server for the signed-in user
+	ErrCodeFileNotFound     = "FILE_NOT_FOUND"     // A file or folder in the server folder
+	ErrCodeBackupNotFound   = "BACKUP_NOT_FOUND"   // A backup record or its archive
+	ErrCodeScheduleNotFound = "SCHEDULE_NOT_FOUND" // A schedule of the server
+	ErrCodeMountNotFound    = "MOUNT_NOT_FOUND"    // A backup mount that was never created or has expired
+	ErrCodeNotFound         = "NOT_FOUND"          // Any other missing resource
+	ErrCodeAccessDenied     = "ACCESS_DENIED"      // The resource belongs to another server or user
+	ErrCodePathOutsideRoot  = "PATH_OUTSIDE_ROOT"  // A path escapes the server folder
+	ErrCodeFileProtected    = "FILE_PROTECTED"     // The file matches a protected path pattern
+	ErrCodeConflict         = "CONFLICT"           // The target name is already taken
+	ErrCodeFileInUse        = "FILE_IN_USE"        // The running server holds the file open
+	ErrCodeServerRunning    = "SERVER_RUNNING"     // The operation needs the server stopped
+	ErrCodeInProgress       = "IN_PROGRESS"        // A backup or restore of the server is already running
+	ErrCodePermissionDenied = "PERMISSION_DENIED"  // The panel process lacks file system permissions
+	ErrCodeTooLarge         = "TOO_LARGE"          // The content exceeds a size limit
+	ErrCodeBackupUnreadable = "BACKUP_UNREADABLE"  // The backup archive is corrupt or unsupported
+	ErrCodeBackupMismatch   = "BACKUP_MISMATCH"    // The backup archive was made of a different server
+	ErrCodeFetchFailed      = "FETCH_FAILED"       // A remote download failed
+	ErrCodeDiskReserve      = "DISK_RESERVE"       // The operation would leave less than the reserved free disk space
+	ErrCodeTimeout          = "TIMEOUT"            // A file system or network operation didn't finish in time
+	ErrCodeInternal         = "INTERNAL_ERROR"     // Anything else that went wrong on the panel's side
+)
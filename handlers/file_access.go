@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Permission bits for access(2)
+const (
+	accessExecute = 0x1
+	accessWrite   = 0x2
+)
+
+// errNoWriteAccess marks a pre-flight failure caused by the panel process's own permissions
+var errNoWriteAccess = errors.New("permission denied")
+
+// checkWriteAccess verifies, before a file operation starts, that the panel process may change
+// path: an existing file must be writable, while a new one needs a writable parent folder
+func checkWriteAccess(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return checkFolderAccess(filepath.Dir(path))
+	}
+	if err != nil {
+		return accessError(path, err)
+	}
+	if info.IsDir() {
+		return checkFolderAccess(path)
+	}
+	if err := syscall.Access(path, accessWrite); err != nil {
+		return accessError(path, err)
+	}
+	return nil
+}
+
+// checkFolderAccess verifies that the panel process may add, remove and rename entries in a
+// folder, which deleting, moving or renaming anything inside it requires
+func checkFolderAccess(dir string) error {
+	if err := syscall.Access(dir, accessWrite|accessExecute); err != nil {
+		return accessError(dir, err)
+	}
+	return nil
+}
+
+// accessError wraps a failed check, telling a permission problem apart from other failures
+func accessError(path string, err error) error {
+	if errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("%w: the panel (running as uid %d) can't write to %s; give that user write access or change the owner", errNoWriteAccess, os.Geteuid(), path)
+	}
+	return err
+}
+
+// writeAccessError responds that a pre-flight check failed, as a permission error when it was one
+func writeAccessError(w http.ResponseWriter, err error) {
+	status, code := http.StatusInternalServerError, ErrCodeInternal
+	if errors.Is(err, errNoWriteAccess) {
+		status, code = http.StatusForbidden, ErrCodePermissionDenied
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   err.Error(),
+		"code":    code,
+	})
+}
@@ -1,15 +1,23 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io/fs"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
+	"seiapanel/config"
 	"seiapanel/middleware"
 	"seiapanel/models"
+	"seiapanel/services"
 
 	"github.com/gorilla/mux"
 )
@@ -29,6 +37,7 @@ func ReadFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -42,6 +51,19 @@ func ReadFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "File name is required",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Resolve against the server files or a temporary backup mount
+	fileRoot, boundary, ok := fileRootForRequest(r, server)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup mount not found or expired",
+			"code":    ErrCodeMountNotFound,
 		})
 		return
 	}
@@ -49,19 +71,20 @@ func ReadFile(w http.ResponseWriter, r *http.Request) {
 	// Build full path
 	var fullPath string
 	if currentPath == "/" || currentPath == "" {
-		fullPath = filepath.Join(server.FolderPath, fileName)
+		fullPath = filepath.Join(fileRoot, fileName)
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		fullPath = filepath.Join(server.FolderPath, relativePath, fileName)
+		fullPath = filepath.Join(fileRoot, relativePath, fileName)
 	}
 
 	// Security check: ensure the path is within the server folder
 	cleanPath := filepath.Clean(fullPath)
-	if !strings.HasPrefix(cleanPath, server.FolderPath) {
+	if !strings.HasPrefix(cleanPath, boundary) {
 		w.WriteHeader(http.StatusForbidden)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
 		})
 		return
 	}
@@ -73,6 +96,7 @@ func ReadFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "File not found",
+			"code":    ErrCodeFileNotFound,
 		})
 		return
 	}
@@ -82,17 +106,21 @@ func ReadFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Cannot read directory as file",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 
-	// Read file content
+	// Read file content, never while a save is half-way through
+	unlock := services.LockFileShared(cleanPath)
 	content, err := ioutil.ReadFile(cleanPath)
+	unlock()
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to read file: " + err.Error(),
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
@@ -121,6 +149,7 @@ func WriteFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -131,6 +160,7 @@ func WriteFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -144,6 +174,7 @@ func WriteFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "File name is required",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -151,10 +182,10 @@ func WriteFile(w http.ResponseWriter, r *http.Request) {
 	// Build full path
 	var fullPath string
 	if currentPath == "/" || currentPath == "" {
-		fullPath = filepath.Join(server.FolderPath, fileName)
+		fullPath = filepath.Join(server.FileRootPath(), fileName)
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		fullPath = filepath.Join(server.FolderPath, relativePath, fileName)
+		fullPath = filepath.Join(server.FileRootPath(), relativePath, fileName)
 	}
 
 	// Security check: ensure the path is within the server folder
@@ -164,6 +195,7 @@ func WriteFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
 		})
 		return
 	}
@@ -175,6 +207,7 @@ func WriteFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "File not found",
+			"code":    ErrCodeFileNotFound,
 		})
 		return
 	}
@@ -184,25 +217,308 @@ func WriteFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Cannot write to directory",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Check permissions up front so a denial isn't reported as a failed write
+	if err := checkWriteAccess(cleanPath); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	// Protected files need an explicit override
+	protectedErr := checkProtectedPath(server, cleanPath)
+	if protectedErr != nil && !protectionOverride(r) {
+		writeProtectedError(w, protectedErr)
+		return
+	}
+
+	// Files the running server holds open may be half-read or overwritten by it
+	force := r.FormValue("force")
+	forced := force == "true" || force == "1"
+	livePattern, live := "", false
+	if services.IsServerRunning(server) {
+		livePattern, live = liveConfigPattern(server, cleanPath)
+	}
+	if live && !forced {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":        false,
+			"error":          fmt.Sprintf("%s is in use by the running server. It may read a half-written file or overwrite your changes on its next save. Stop the server first, or save with force to write anyway.", fileName),
+			"code":           ErrCodeFileInUse,
+			"requires_force": true,
+			"live_pattern":   livePattern,
 		})
 		return
 	}
 
-	// Write content to file
-	err = ioutil.WriteFile(cleanPath, []byte(content), 0644)
+	// Keep the previous content so the overwrite can be undone
+	journal := services.NewFileJournalEntry(server.ID, services.JournalOverwrite)
+	journal.Stash(cleanPath)
+
+	// Write content to file, holding off readers until it is complete
+	unlock := services.LockFileExclusive(cleanPath)
+	err = services.ReplaceFile(cleanPath, strings.NewReader(content), 0644)
+	unlock()
 	if err != nil {
+		journal.Forget(cleanPath)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to write file: " + err.Error(),
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
 
-	// Return success
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	journal.Commit()
+
+	if protectedErr != nil {
+		auditProtectionOverride(r, server, "write", cleanPath)
+	}
+
+	response := map[string]interface{}{
 		"success": true,
 		"message": "File saved successfully",
 		"name":    fileName,
+	}
+	if live {
+		response["warning"] = "Saved while the server is running, it may overwrite these changes. Restart the server to load them."
+	}
+
+	// Return success
+	json.NewEncoder(w).Encode(response)
+}
+
+// liveConfigPattern reports whether a file matches one of the configured live config patterns.
+// Patterns containing a slash match the path relative to the server folder, others match the
+// file name anywhere in it.
+func liveConfigPattern(server *models.Server, path string) (string, bool) {
+	return models.MatchPathPattern(server.FolderPath, path, config.GetLiveConfigPatterns())
+}
+
+// Limits for replace-in-files, so a broad scope can't stall the request
+const (
+	replaceMaxFileSize = 5 * 1024 * 1024 // Larger files are skipped
+	replaceMaxFiles    = 20000           // Files visited before the walk stops
+)
+
+// ReplaceInFiles replaces text across the text files under a folder, optionally as a dry run
+func ReplaceInFiles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	search := r.FormValue("search")
+	replacement := r.FormValue("replace")
+	useRegex := r.FormValue("regex") == "true" || r.FormValue("regex") == "1"
+	dryRun := r.FormValue("dry_run") == "true" || r.FormValue("dry_run") == "1"
+	override := protectionOverride(r)
+
+	if search == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Search text is required",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	pattern := regexp.QuoteMeta(search)
+	if useRegex {
+		pattern = search
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid regular expression: " + err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Extension filter, e.g. "properties,yml,.json" (empty = all text files)
+	extensions := make(map[string]bool)
+	for _, ext := range strings.Split(r.FormValue("extensions"), ",") {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext != "" {
+			extensions["."+ext] = true
+		}
+	}
+
+	// Scope folder, relative to the file root
+	scopePath := filepath.Clean(filepath.Join(server.FileRootPath(), strings.TrimPrefix(r.FormValue("path"), "/")))
+	if !strings.HasPrefix(scopePath, server.FolderPath) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	if info, err := os.Stat(scopePath); err != nil || !info.IsDir() {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Folder not found",
+			"code":    ErrCodeFileNotFound,
+		})
+		return
+	}
+
+	results := make([]map[string]interface{}, 0)
+	failures := make([]map[string]string, 0)
+	var scanned, skippedBinary, totalReplacements int
+	truncated := false
+
+	err = filepath.WalkDir(scopePath, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := r.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
+		// Only regular files; symlinks could point outside the server folder
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if len(extensions) > 0 && !extensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		if scanned >= replaceMaxFiles {
+			truncated = true
+			return filepath.SkipAll
+		}
+		scanned++
+
+		info, err := d.Info()
+		if err != nil || info.Size() > replaceMaxFileSize {
+			return nil
+		}
+
+		// Hold the file across read and rewrite so no other save lands in between
+		var unlock func()
+		if dryRun {
+			unlock = services.LockFileShared(path)
+		} else {
+			unlock = services.LockFileExclusive(path)
+		}
+		defer unlock()
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if isBinaryContent(content) {
+			skippedBinary++
+			return nil
+		}
+
+		count := len(re.FindAllIndex(content, -1))
+		if count == 0 {
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(server.FileRootPath(), path)
+		relPath = "/" + filepath.ToSlash(relPath)
+
+		// Protected files are skipped unless the override is given
+		if pattern, ok := server.ProtectedPattern(path); ok {
+			if !override {
+				failures = append(failures, map[string]string{
+					"path":  relPath,
+					"error": fmt.Sprintf("file is protected (matches %q)", pattern),
+				})
+				return nil
+			}
+			if !dryRun {
+				auditProtectionOverride(r, server, "replace", path)
+			}
+		}
+
+		if !dryRun {
+			// Regex replacements may reference groups ($1); plain ones are taken literally
+			var updated []byte
+			if useRegex {
+				updated = re.ReplaceAll(content, []byte(replacement))
+			} else {
+				updated = re.ReplaceAllLiteral(content, []byte(replacement))
+			}
+
+			if err := services.ReplaceFile(path, bytes.NewReader(updated), info.Mode().Perm()); err != nil {
+				failures = append(failures, map[string]string{"path": relPath, "error": err.Error()})
+				return nil
+			}
+		}
+
+		totalReplacements += count
+		results = append(results, map[string]interface{}{
+			"path":         relPath,
+			"replacements": count,
+		})
+		return nil
 	})
-}
\ No newline at end of file
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to search files: " + err.Error(),
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	if !dryRun && len(results) > 0 {
+		log.Printf("✅ Replaced %d occurrence(s) in %d file(s) on server '%s'", totalReplacements, len(results), server.Name)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":            true,
+		"dry_run":            dryRun,
+		"files":              results,
+		"failures":           failures,
+		"total_replacements": totalReplacements,
+		"files_scanned":      scanned,
+		"skipped_binary":     skippedBinary,
+		"truncated":          truncated,
+	})
+}
+
+// isBinaryContent reports whether file content looks binary (NUL bytes or invalid UTF-8)
+func isBinaryContent(content []byte) bool {
+	head := content
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	return bytes.IndexByte(head, 0) >= 0 || !utf8.Valid(content)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"seiapanel/config"
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// GetFileJournal lists the server's journaled deletes, moves and overwrites, newest first - AJAX JSON response
+func GetFileJournal(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	entries := services.GetFileJournal(server.ID)
+	journal := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		journal = append(journal, journalEntryJSON(server, entry))
+	}
+
+	enabled, _, _ := config.GetFileJournal()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"enabled": enabled,
+		"journal": journal,
+	})
+}
+
+// UndoFileOperation reverses the server's most recent journaled file operation - AJAX JSON response
+func UndoFileOperation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	entry, err := services.UndoLastFileOperation(server.ID)
+	if err != nil {
+		status, code := http.StatusInternalServerError, ErrCodeInternal
+		switch {
+		case errors.Is(err, services.ErrJournalEmpty):
+			status, code = http.StatusNotFound, ErrCodeNotFound
+		case errors.Is(err, services.ErrJournalNotUndoable), errors.Is(err, services.ErrJournalConflict):
+			status, code = http.StatusConflict, ErrCodeConflict
+		}
+
+		response := map[string]interface{}{
+			"success": false,
+			"error":   "Cannot undo: " + err.Error(),
+			"code":    code,
+		}
+		if entry != nil {
+			response["operation"] = journalEntryJSON(server, *entry)
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	models.CreateAuditLog(userID, server.ID, "file.undo", models.AuditSourceSession, true, entry.Op, middleware.ClientIP(r))
+	log.Printf("✅ Undid %s of %d item(s) in '%s'", entry.Op, len(entry.Items), server.Name)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"message":   "Undid " + entry.Op,
+		"operation": journalEntryJSON(server, *entry),
+	})
+}
+
+// journalEntryJSON describes a journal entry with paths relative to the server folder
+func journalEntryJSON(server *models.Server, entry services.FileJournalEntry) map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(entry.Items))
+	for _, item := range entry.Items {
+		described := map[string]interface{}{
+			"path":   serverRelativePath(server, item.Path),
+			"size":   item.Size,
+			"is_dir": item.IsDir,
+		}
+		if item.Target != "" {
+			described["target"] = serverRelativePath(server, item.Target)
+		}
+		items = append(items, described)
+	}
+
+	return map[string]interface{}{
+		"id":       entry.ID,
+		"op":       entry.Op,
+		"time":     entry.Time,
+		"undoable": entry.Undoable,
+		"items":    items,
+	}
+}
+
+// serverRelativePath returns a path inside the server folder as "/"-rooted for display
+func serverRelativePath(server *models.Server, path string) string {
+	rel, err := filepath.Rel(server.FolderPath, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return "/" + filepath.ToSlash(rel)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// createFileWithMode creates or truncates a file for writing. A newly created file gets
+// exactly mode, regardless of the process umask; an existing file keeps its mode.
+func createFileWithMode(path string, mode os.FileMode) (*os.File, error) {
+	_, statErr := os.Lstat(path)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return nil, err
+	}
+	if os.IsNotExist(statErr) {
+		if err := file.Chmod(mode); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// mkdirWithMode creates a single directory with exactly mode, regardless of the process umask
+func mkdirWithMode(path string, mode os.FileMode) error {
+	if err := os.Mkdir(path, mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// mkdirAllWithMode creates a directory and any missing parents. Only the directories it
+// creates get mode; existing ones are left alone.
+func mkdirAllWithMode(path string, mode os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return &os.PathError{Op: "mkdir", Path: path, Err: os.ErrExist}
+	}
+
+	parent := filepath.Dir(path)
+	if parent != path {
+		if err := mkdirAllWithMode(parent, mode); err != nil {
+			return err
+		}
+	}
+
+	if err := mkdirWithMode(path, mode); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// Multi-file tail limits
+const (
+	multiTailMaxFiles     = 10
+	multiTailDefaultLines = 100
+	multiTailMaxLines     = 1000
+	multiTailMaxFileBytes = 256 * 1024  // Read from the end of each file
+	multiTailMaxTotal     = 1024 * 1024 // Combined output across all files
+)
+
+// TailedFile is the end of one file requested by a multi-file tail
+type TailedFile struct {
+	Path      string   `json:"path"`
+	Lines     []string `json:"lines"`
+	Truncated bool     `json:"truncated"` // Older lines exist beyond what was returned
+	Error     string   `json:"error,omitempty"`
+}
+
+// MergedTailLine is one line of a time-merged multi-file tail
+type MergedTailLine struct {
+	File      string     `json:"file"`
+	Line      string     `json:"line"`
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+// MultiTailFiles returns the last lines of several files at once. Files are given as repeated
+// "file" query values relative to the server root; with merge=true and a log pattern configured
+// the lines are also interleaved by their timestamps - AJAX JSON response
+func MultiTailFiles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	files := r.URL.Query()["file"]
+	if len(files) == 0 || len(files) > multiTailMaxFiles {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Between 1 and " + strconv.Itoa(multiTailMaxFiles) + " files are required",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	lines := multiTailDefaultLines
+	if linesStr := r.URL.Query().Get("lines"); linesStr != "" {
+		lines, err = strconv.Atoi(linesStr)
+		if err != nil || lines < 1 || lines > multiTailMaxLines {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Lines must be between 1 and " + strconv.Itoa(multiTailMaxLines),
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+	}
+
+	// Resolve against the server files or a temporary backup mount
+	fileRoot, boundary, ok := fileRootForRequest(r, server)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup mount not found or expired",
+			"code":    ErrCodeMountNotFound,
+		})
+		return
+	}
+
+	// Each file gets an equal share of the total output
+	perFileBytes := min(int64(multiTailMaxFileBytes), int64(multiTailMaxTotal/len(files)))
+
+	tailed := make([]TailedFile, 0, len(files))
+	for _, file := range files {
+		relativePath := strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+file)), "/")
+		result := TailedFile{Path: "/" + relativePath, Lines: []string{}}
+
+		// Validate path is within server directory (security check)
+		cleanPath := filepath.Clean(filepath.Join(fileRoot, relativePath))
+		if relativePath == "" || !strings.HasPrefix(cleanPath, boundary) {
+			result.Error = "Invalid file path"
+			tailed = append(tailed, result)
+			continue
+		}
+
+		result.Lines, result.Truncated, err = tailFileLines(cleanPath, lines, perFileBytes)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		tailed = append(tailed, result)
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"files":   tailed,
+	}
+
+	mergeStr := r.URL.Query().Get("merge")
+	if mergeStr == "true" || mergeStr == "1" {
+		parser, err := services.NewLogParser(server)
+		if err != nil || parser == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Merging by time needs a valid log pattern configured for this server",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+		response["merged"] = mergeTailedFiles(tailed, parser)
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// tailFileLines returns up to n complete lines from the end of a file, reading at most
+// maxBytes. truncated reports whether earlier content was left out.
+func tailFileLines(filePath string, n int, maxBytes int64) ([]string, bool, error) {
+	unlock := services.LockFileShared(filePath)
+	defer unlock()
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return []string{}, false, errors.New("file not found")
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return []string{}, false, err
+	}
+	if !info.Mode().IsRegular() {
+		return []string{}, false, errors.New("not a regular file")
+	}
+
+	offset := max(info.Size()-maxBytes, 0)
+	data := make([]byte, info.Size()-offset)
+	read, err := file.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return []string{}, false, err
+	}
+	data = data[:read]
+
+	// A window starting mid-file begins with a partial line
+	if offset > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		} else {
+			data = nil
+		}
+	}
+
+	text := strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if text == "" {
+		return []string{}, offset > 0, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	truncated := offset > 0
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+		truncated = true
+	}
+	return lines, truncated, nil
+}
+
+// mergeTailedFiles interleaves the lines of several files by their parsed timestamps. Lines
+// without a timestamp stay with the line before them; order within a file is kept.
+func mergeTailedFiles(tailed []TailedFile, parser *services.LogParser) []MergedTailLine {
+	now := time.Now()
+
+	var merged []MergedTailLine
+	for _, file := range tailed {
+		for _, entry := range parser.Parse(file.Lines, now) {
+			merged = append(merged, MergedTailLine{
+				File:      file.Path,
+				Line:      entry.Raw,
+				Timestamp: entry.Timestamp,
+			})
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := merged[i].Timestamp, merged[j].Timestamp
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+
+	if merged == nil {
+		merged = []MergedTailLine{}
+	}
+	return merged
+}
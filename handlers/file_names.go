@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+)
+
+// maxFileNameLength is the longest name most filesystems accept, in bytes
+const maxFileNameLength = 255
+
+// windowsReservedNames can't be used as file names on Windows, with or without an extension
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// validateFileName checks a single file or folder name supplied by the user. Names must not
+// contain separators, "..", or control characters, must not start or end with a space or dot,
+// and must not be a reserved Windows device name, so the result always stays in its directory
+// and can be removed again from any client the server files are copied to.
+func validateFileName(name string) error {
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if len(name) > maxFileNameLength {
+		return errors.New("name is too long")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return errors.New("name cannot contain / or \\")
+	}
+	if strings.Contains(name, "..") {
+		return errors.New("name cannot contain ..")
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return errors.New("name cannot contain control characters")
+		}
+	}
+	if strings.TrimSpace(name) != name {
+		return errors.New("name cannot start or end with a space")
+	}
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") {
+		return errors.New("name cannot start or end with a dot")
+	}
+
+	base := strings.ToUpper(name)
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
+	}
+	if windowsReservedNames[strings.TrimSpace(base)] {
+		return errors.New("name is reserved on Windows")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateFileNameRejectsMalicious(t *testing.T) {
+	names := []string{
+		"",
+		"..",
+		"../server.properties",
+		"..\\server.properties",
+		"world/../../etc",
+		"a/b",
+		`a\b`,
+		"evil\x00.txt",
+		"line\nbreak.txt",
+		"tab\there",
+		"del\x7f",
+		" leading",
+		"trailing ",
+		"trailing.",
+		"CON",
+		"con.txt",
+		"Lpt1.log",
+		"NUL.tar.gz",
+		strings.Repeat("a", maxFileNameLength+1),
+	}
+	for _, name := range names {
+		if err := validateFileName(name); err == nil {
+			t.Errorf("validateFileName(%q) accepted a malicious name", name)
+		}
+	}
+}
+
+func TestValidateFileNameAcceptsOrdinary(t *testing.T) {
+	names := []string{
+		"server.properties",
+		"world_nether",
+		"banned-players.json",
+		"My World (1).zip",
+		"console.log",
+		"CONSOLE.txt",
+		strings.Repeat("a", maxFileNameLength),
+	}
+	for _, name := range names {
+		if err := validateFileName(name); err != nil {
+			t.Errorf("validateFileName(%q) = %v, want it accepted", name, err)
+		}
+	}
+}
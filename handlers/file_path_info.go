@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+
+	"github.com/gorilla/mux"
+)
+
+// PathSegment describes one ancestor of a file manager path
+type PathSegment struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+	IsDir  bool   `json:"is_dir"`
+}
+
+// GetPathInfo reports, for the root and each segment of a path, whether it still exists and is
+// a directory, so breadcrumbs can recover from an ancestor deleted elsewhere - AJAX JSON response
+func GetPathInfo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Resolve against the server files or a temporary backup mount
+	fileRoot, boundary, ok := fileRootForRequest(r, server)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup mount not found or expired",
+			"code":    ErrCodeMountNotFound,
+		})
+		return
+	}
+
+	relativePath := strings.Trim(filepath.ToSlash(filepath.Clean("/"+r.URL.Query().Get("path"))), "/")
+
+	// Validate path is within server directory (security check)
+	cleanPath := filepath.Clean(filepath.Join(fileRoot, relativePath))
+	if !strings.HasPrefix(cleanPath, boundary) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid file path",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	segments := []PathSegment{statPathSegment("/", "/", fileRoot)}
+	if relativePath != "" {
+		current := ""
+		for _, name := range strings.Split(relativePath, "/") {
+			current += "/" + name
+			segments = append(segments, statPathSegment(name, current, filepath.Join(fileRoot, current)))
+		}
+	}
+
+	// The deepest directory reachable without passing a missing ancestor, to fall back to
+	nearest := "/"
+	for _, segment := range segments {
+		if !segment.Exists || !segment.IsDir {
+			break
+		}
+		nearest = segment.Path
+	}
+
+	last := segments[len(segments)-1]
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":       true,
+		"path":          "/" + relativePath,
+		"valid":         last.Exists && last.IsDir,
+		"segments":      segments,
+		"nearest_valid": nearest,
+	})
+}
+
+// statPathSegment checks whether one breadcrumb segment exists on disk
+func statPathSegment(name, path, fullPath string) PathSegment {
+	segment := PathSegment{Name: name, Path: path}
+	if info, err := os.Stat(fullPath); err == nil {
+		segment.Exists = true
+		segment.IsDir = info.IsDir()
+	}
+	return segment
+}
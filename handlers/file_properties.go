@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// propertiesMaxScanBytes bounds how much of a file is read to count lines
+const propertiesMaxScanBytes = 16 * 1024 * 1024
+
+// propertiesSniffBytes is how much of a file the encoding guess looks at
+const propertiesSniffBytes = 8192
+
+// FileProperties is the detailed metadata of a single file or directory
+type FileProperties struct {
+	Name        string     `json:"name"`
+	Path        string     `json:"path"`
+	Type        string     `json:"type"` // file, directory, symlink, other
+	Size        int64      `json:"size"`
+	Mode        string     `json:"mode"`        // e.g. -rw-r--r--
+	Permissions string     `json:"permissions"` // Octal, e.g. 0644
+	Owner       string     `json:"owner,omitempty"`
+	Group       string     `json:"group,omitempty"`
+	ModifiedAt  time.Time  `json:"modified_at"`
+	AccessedAt  *time.Time `json:"accessed_at,omitempty"`
+	ChangedAt   *time.Time `json:"changed_at,omitempty"` // Last metadata change; Linux doesn't expose creation time here
+	LinkTarget  string     `json:"link_target,omitempty"`
+
+	// Regular files
+	ContentType    string `json:"content_type,omitempty"`
+	Encoding       string `json:"encoding,omitempty"` // Best guess: utf-8, ascii, utf-16le, utf-16be, binary, unknown
+	LineCount      *int64 `json:"line_count,omitempty"`
+	LinesTruncated bool   `json:"lines_truncated,omitempty"` // Only the first part of the file was counted
+
+	// Directories
+	Children *DirChildCounts `json:"children,omitempty"`
+}
+
+// DirChildCounts counts the immediate entries of a directory by type
+type DirChildCounts struct {
+	Files       int `json:"files"`
+	Directories int `json:"directories"`
+	Symlinks    int `json:"symlinks"`
+	Other       int `json:"other"`
+}
+
+// GetFileProperties returns detailed metadata for a single file or directory - AJAX JSON response
+func GetFileProperties(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	currentPath := r.URL.Query().Get("path")
+	fileName := r.URL.Query().Get("file")
+
+	if fileName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "No file specified",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Resolve against the server files or a temporary backup mount
+	fileRoot, boundary, ok := fileRootForRequest(r, server)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Backup mount not found or expired",
+			"code":    ErrCodeMountNotFound,
+		})
+		return
+	}
+
+	// Build full path
+	var fullPath string
+	if currentPath == "/" || currentPath == "" {
+		fullPath = filepath.Join(fileRoot, fileName)
+	} else {
+		relativePath := strings.TrimPrefix(currentPath, "/")
+		fullPath = filepath.Join(fileRoot, relativePath, fileName)
+	}
+
+	// Validate path is within server directory (security check)
+	cleanPath := filepath.Clean(fullPath)
+	if !strings.HasPrefix(cleanPath, boundary) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid file path",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	info, err := os.Lstat(cleanPath)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "File not found",
+			"code":    ErrCodeFileNotFound,
+		})
+		return
+	}
+
+	relPath, _ := filepath.Rel(fileRoot, cleanPath)
+	props := FileProperties{
+		Name:        info.Name(),
+		Path:        "/" + filepath.ToSlash(relPath),
+		Size:        info.Size(),
+		Mode:        info.Mode().String(),
+		Permissions: "0" + strconv.FormatUint(uint64(info.Mode().Perm()), 8),
+		ModifiedAt:  info.ModTime(),
+	}
+	fillStatProperties(&props, info)
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		props.Type = "symlink"
+		props.LinkTarget, _ = os.Readlink(cleanPath)
+	case info.IsDir():
+		props.Type = "directory"
+		children, err := countDirChildren(cleanPath)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Failed to read directory",
+				"code":    ErrCodeInternal,
+			})
+			return
+		}
+		props.Children = children
+	case info.Mode().IsRegular():
+		props.Type = "file"
+		if err := fillContentProperties(&props, cleanPath); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Failed to read file",
+				"code":    ErrCodeInternal,
+			})
+			return
+		}
+	default:
+		props.Type = "other"
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    true,
+		"properties": props,
+	})
+}
+
+// fillStatProperties adds owner, group and access/change times from the platform stat data
+func fillStatProperties(props *FileProperties, info os.FileInfo) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+
+	uid := strconv.FormatUint(uint64(stat.Uid), 10)
+	props.Owner = uid
+	if u, err := user.LookupId(uid); err == nil {
+		props.Owner = u.Username
+	}
+	gid := strconv.FormatUint(uint64(stat.Gid), 10)
+	props.Group = gid
+	if g, err := user.LookupGroupId(gid); err == nil {
+		props.Group = g.Name
+	}
+
+	accessedAt := time.Unix(stat.Atim.Sec, stat.Atim.Nsec)
+	changedAt := time.Unix(stat.Ctim.Sec, stat.Ctim.Nsec)
+	props.AccessedAt = &accessedAt
+	props.ChangedAt = &changedAt
+}
+
+// countDirChildren counts the immediate entries of a directory by type
+func countDirChildren(dirPath string) (*DirChildCounts, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &DirChildCounts{}
+	for _, entry := range entries {
+		switch {
+		case entry.Type()&os.ModeSymlink != 0:
+			counts.Symlinks++
+		case entry.IsDir():
+			counts.Directories++
+		case entry.Type().IsRegular():
+			counts.Files++
+		default:
+			counts.Other++
+		}
+	}
+	return counts, nil
+}
+
+// fillContentProperties adds the content type, encoding guess and, for text, the line count
+func fillContentProperties(props *FileProperties, filePath string) error {
+	unlock := services.LockFileShared(filePath)
+	defer unlock()
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	head := make([]byte, propertiesSniffBytes)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	head = head[:n]
+
+	props.ContentType = detectFileContentType(props.Name, head[:min(len(head), 512)])
+	props.Encoding = guessEncoding(head, n < propertiesSniffBytes)
+
+	// Lines are only meaningful for text that splits on single-byte newlines
+	switch props.Encoding {
+	case "utf-8", "ascii", "unknown":
+	default:
+		return nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	var lines int64
+	var last byte
+	buf := make([]byte, 64*1024)
+	reader := io.LimitReader(file, propertiesMaxScanBytes)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			lines += int64(bytes.Count(buf[:n], []byte{'\n'}))
+			last = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if props.Size > 0 && last != '\n' {
+		lines++ // Last line without a trailing newline
+	}
+	props.LineCount = &lines
+	props.LinesTruncated = props.Size > propertiesMaxScanBytes
+	return nil
+}
+
+// guessEncoding makes a best-effort guess at a file's text encoding from its first bytes.
+// complete is true when head holds the whole file.
+func guessEncoding(head []byte, complete bool) string {
+	switch {
+	case len(head) == 0:
+		return "ascii"
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8"
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return "utf-16le"
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return "utf-16be"
+	case bytes.IndexByte(head, 0) >= 0:
+		return "binary"
+	}
+
+	// The sniffed head may end partway through a multi-byte character
+	text := head
+	if !complete {
+		for i := 0; i < utf8.UTFMax-1 && len(text) > 0 && !utf8.Valid(text); i++ {
+			text = text[:len(text)-1]
+		}
+	}
+	if !utf8.Valid(text) {
+		return "unknown"
+	}
+	for _, b := range text {
+		if b >= 0x80 {
+			return "utf-8"
+		}
+	}
+	return "ascii"
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+
+	"github.com/gorilla/mux"
+)
+
+// errProtectedFound stops the walk over a directory once a protected file is found
+var errProtectedFound = errors.New("protected file found")
+
+// UpdateProtectedPaths sets the file patterns the file manager refuses to overwrite, delete,
+// rename or move without an override - AJAX JSON response
+func UpdateProtectedPaths(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Patterns may be separated by commas or new lines
+	patterns := strings.FieldsFunc(r.FormValue("protected_paths"), func(c rune) bool {
+		return c == ',' || c == '\n' || c == '\r'
+	})
+
+	if err := server.UpdateProtectedPaths(patterns); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error updating protected files: " + err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":         true,
+		"message":         "Protected files updated successfully",
+		"protected_paths": server.GetProtectedPaths(),
+	})
+}
+
+// protectionOverride reports whether the request asks to change protected files anyway. The
+// panel is single-user, so the signed-in account may always override; the flag only has to be
+// given explicitly, and each use is recorded in the audit log.
+func protectionOverride(r *http.Request) bool {
+	override := r.FormValue("override_protection")
+	return override == "true" || override == "1"
+}
+
+// checkProtectedPath returns an error when a path, or for a directory anything inside it,
+// matches one of the server's protected path patterns
+func checkProtectedPath(server *models.Server, path string) error {
+	if server.ProtectedPaths == "" {
+		return nil
+	}
+
+	if pattern, ok := server.ProtectedPattern(path); ok {
+		return fmt.Errorf("%s is protected (matches %q)", filepath.Base(path), pattern)
+	}
+
+	var protected, matchedPattern string
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == path {
+			return nil
+		}
+		if pattern, ok := server.ProtectedPattern(p); ok {
+			protected, matchedPattern = p, pattern
+			return errProtectedFound
+		}
+		return nil
+	})
+	if protected != "" {
+		rel, _ := filepath.Rel(path, protected)
+		return fmt.Errorf("%s contains the protected file %s (matches %q)", filepath.Base(path), filepath.ToSlash(rel), matchedPattern)
+	}
+	return nil
+}
+
+// writeProtectedError responds that a protected file was about to be changed
+func writeProtectedError(w http.ResponseWriter, err error) {
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":           false,
+		"error":             "File is protected: " + err.Error(),
+		"code":              ErrCodeFileProtected,
+		"protected":         true,
+		"requires_override": true,
+	})
+}
+
+// auditProtectionOverride records that a protected file was changed using the override flag
+func auditProtectionOverride(r *http.Request, server *models.Server, action, path string) {
+	userID := middleware.GetUserID(r)
+	rel, err := filepath.Rel(server.FolderPath, path)
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+	models.CreateAuditLog(userID, server.ID, "file.protection_override", models.AuditSourceSession, true, action+" "+filepath.ToSlash(rel), middleware.ClientIP(r))
+}
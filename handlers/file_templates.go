@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ListFileTemplates returns the user's file templates and the placeholders each one uses - AJAX JSON response
+func ListFileTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	templates, err := models.GetFileTemplatesByUserID(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to load templates",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	formatted := make([]map[string]interface{}, 0, len(templates))
+	for i := range templates {
+		formatted = append(formatted, map[string]interface{}{
+			"template":     templates[i],
+			"placeholders": templates[i].Placeholders(),
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"templates": formatted,
+	})
+}
+
+// CreateFileTemplate stores a named file template from the "name" and "content" form fields - AJAX JSON response
+func CreateFileTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	template, err := models.CreateFileTemplate(userID, r.FormValue("name"), r.FormValue("content"))
+	if err != nil {
+		writeFileTemplateError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"message":  "Template created successfully",
+		"template": template,
+	})
+}
+
+// UpdateFileTemplate replaces a template's name and content - AJAX JSON response
+func UpdateFileTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	template, ok := loadFileTemplate(w, r, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	if err := template.Update(r.FormValue("name"), r.FormValue("content")); err != nil {
+		writeFileTemplateError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"message":  "Template updated successfully",
+		"template": template,
+	})
+}
+
+// DeleteFileTemplate deletes a file template - AJAX JSON response
+func DeleteFileTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	template, ok := loadFileTemplate(w, r, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+
+	if err := template.Delete(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to delete template",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Template deleted successfully",
+	})
+}
+
+// WriteFileFromTemplate creates a file in the server folder from a template - AJAX JSON response.
+// "variables" is a JSON object whose values replace {{name}} placeholders; {{server_name}} is
+// filled in unless given. An existing file is only replaced with overwrite=true.
+func WriteFileFromTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	template, ok := loadFileTemplate(w, r, r.FormValue("template_id"))
+	if !ok {
+		return
+	}
+
+	currentPath := r.FormValue("path")
+	fileName := r.FormValue("file")
+	overwrite := r.FormValue("overwrite") == "true" || r.FormValue("overwrite") == "1"
+
+	if err := validateFileName(fileName); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid file name: " + err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	variables := map[string]string{}
+	if variablesJSON := r.FormValue("variables"); variablesJSON != "" {
+		if err := json.Unmarshal([]byte(variablesJSON), &variables); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Variables must be a JSON object of strings",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+	}
+	if _, given := variables["server_name"]; !given {
+		variables["server_name"] = server.Name
+	}
+
+	// Security check: ensure the path is within the server folder
+	cleanPath := filepath.Clean(filepath.Join(server.FileRootPath(), strings.TrimPrefix(currentPath, "/"), fileName))
+	if !strings.HasPrefix(cleanPath, server.FolderPath) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	if info, err := os.Stat(filepath.Dir(cleanPath)); err != nil || !info.IsDir() {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Target folder not found",
+			"code":    ErrCodeFileNotFound,
+		})
+		return
+	}
+
+	// An existing file is only replaced on request, and then like a save in the editor
+	var protectedErr error
+	exists := false
+	if info, err := os.Stat(cleanPath); err == nil {
+		exists = true
+		if info.IsDir() {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "A folder with this name already exists",
+				"code":    ErrCodeConflict,
+			})
+			return
+		}
+		if !overwrite {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":            false,
+				"error":              "File '" + fileName + "' already exists",
+				"code":               ErrCodeConflict,
+				"requires_overwrite": true,
+			})
+			return
+		}
+
+		protectedErr = checkProtectedPath(server, cleanPath)
+		if protectedErr != nil && !protectionOverride(r) {
+			writeProtectedError(w, protectedErr)
+			return
+		}
+	}
+
+	if err := checkWriteAccess(cleanPath); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	content, unresolved := template.Render(variables)
+
+	journal := services.NewFileJournalEntry(server.ID, services.JournalOverwrite)
+	if exists {
+		journal.Stash(cleanPath)
+	}
+
+	unlock := services.LockFileExclusive(cleanPath)
+	err = writeTemplateFile(cleanPath, content, server.FilePerm())
+	unlock()
+	if err != nil {
+		journal.Forget(cleanPath)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to write file: " + err.Error(),
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+	journal.Commit()
+
+	if protectedErr != nil {
+		auditProtectionOverride(r, server, "write", cleanPath)
+	}
+
+	response := map[string]interface{}{
+		"success":     true,
+		"message":     "File created from template '" + template.Name + "'",
+		"name":        fileName,
+		"overwritten": exists,
+		"unresolved":  unresolved,
+	}
+	if len(unresolved) > 0 {
+		response["warning"] = "No value was given for: " + strings.Join(unresolved, ", ")
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeTemplateFile writes rendered template content, creating the file with mode if it is new
+func writeTemplateFile(path, content string, mode os.FileMode) error {
+	return services.ReplaceFile(path, strings.NewReader(content), mode)
+}
+
+// loadFileTemplate resolves a template ID of the signed-in user, writing an error response and
+// returning false when it is invalid or not found
+func loadFileTemplate(w http.ResponseWriter, r *http.Request, idStr string) (*models.FileTemplate, bool) {
+	templateID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid template ID",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return nil, false
+	}
+
+	template, err := models.GetFileTemplateByID(uint(templateID), middleware.GetUserID(r))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Template not found",
+			"code":    ErrCodeNotFound,
+		})
+		return nil, false
+	}
+	return template, true
+}
+
+// writeFileTemplateError responds to a template that failed validation or whose name is taken
+func writeFileTemplateError(w http.ResponseWriter, err error) {
+	status, code := http.StatusBadRequest, ErrCodeInvalidRequest
+	if errors.Is(err, models.ErrFileTemplateNameTaken) {
+		status, code = http.StatusConflict, ErrCodeConflict
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   err.Error(),
+		"code":    code,
+	})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+
+	"github.com/gorilla/mux"
+)
+
+// TouchFile sets a file's modification and access times without changing its content, creating
+// it empty when it doesn't exist, like touch - AJAX JSON response. "time" sets a specific time,
+// given as RFC 3339 or Unix seconds; without it the current time is used.
+func TouchFile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	currentPath := r.FormValue("path")
+	fileName := r.FormValue("file")
+
+	if err := validateFileName(fileName); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid file name: " + err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	touchTime := time.Now()
+	if timeStr := strings.TrimSpace(r.FormValue("time")); timeStr != "" {
+		touchTime, err = parseTouchTime(timeStr)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid time: use RFC 3339 (2006-01-02T15:04:05Z) or Unix seconds",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+	}
+
+	// Build full path
+	var fullPath string
+	if currentPath == "/" || currentPath == "" {
+		fullPath = filepath.Join(server.FileRootPath(), fileName)
+	} else {
+		relativePath := strings.TrimPrefix(currentPath, "/")
+		fullPath = filepath.Join(server.FileRootPath(), relativePath, fileName)
+	}
+
+	// Security check: ensure the path is within the server folder
+	cleanPath := filepath.Clean(fullPath)
+	if !strings.HasPrefix(cleanPath, server.FolderPath) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	// Chtimes follows symlinks, so a link must not lead out of the server folder
+	created := false
+	if _, err := os.Lstat(cleanPath); err == nil {
+		if !touchTargetInside(server.FolderPath, cleanPath) {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Access denied: link points outside server directory",
+				"code":    ErrCodePathOutsideRoot,
+			})
+			return
+		}
+		if err := checkWriteAccess(cleanPath); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+	} else if os.IsNotExist(err) {
+		// The panel needs write access to the folder the file goes in
+		if err := checkFolderAccess(filepath.Dir(cleanPath)); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+		file, err := createFileWithMode(cleanPath, server.FilePerm())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Failed to create file: " + err.Error(),
+				"code":    ErrCodeInternal,
+			})
+			return
+		}
+		file.Close()
+		created = true
+	} else {
+		writeAccessError(w, accessError(cleanPath, err))
+		return
+	}
+
+	if err := os.Chtimes(cleanPath, touchTime, touchTime); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to update file times: " + err.Error(),
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	message := "File times updated successfully"
+	if created {
+		message = "File created successfully"
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"message":     message,
+		"name":        fileName,
+		"created":     created,
+		"modified_at": touchTime,
+	})
+}
+
+// parseTouchTime reads a touch time given as RFC 3339 or Unix seconds
+func parseTouchTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// touchTargetInside reports whether path, with any symlinks resolved, stays within root
+func touchTargetInside(root, path string) bool {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolvedRoot
+	}
+	return resolved == root || strings.HasPrefix(resolved, root+string(filepath.Separator))
+}
@@ -3,25 +3,38 @@ package handlers
 import (
 	"archive/tar"
 	"archive/zip"
+	"bytes"
+	"compress/bzip2"
 	"compress/gzip"
+	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"io/ioutil"
+	"log"
 	"math/big"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"seiapanel/config"
 	"seiapanel/middleware"
 	"seiapanel/models"
+	"seiapanel/services"
 
 	"github.com/gorilla/mux"
+	"github.com/ulikunitz/xz"
 )
 
 // FileInfo represents a file or directory information
@@ -31,15 +44,137 @@ type FileInfo struct {
 	Size      int64     `json:"size"`
 	ModTime   time.Time `json:"mod_time"`
 	Extension string    `json:"extension"`
+	// Recursive directory stats, only set with recursive_stats=true
+	TotalSize      *int64 `json:"total_size,omitempty"`
+	ItemCount      *int64 `json:"item_count,omitempty"`
+	StatsTruncated bool   `json:"stats_truncated,omitempty"`
 }
 
 // ListDirectoryResponse represents the response for directory listing
 type ListDirectoryResponse struct {
 	CurrentPath string     `json:"current_path"`
 	Files       []FileInfo `json:"files"`
+	Matched     *int       `json:"matched,omitempty"` // Entries matching the filter, only set when filtering
 	Error       string     `json:"error,omitempty"`
 }
 
+// fileFilter narrows a listing to entries matching a glob (e.g. "*.yml") or a list of extensions
+type fileFilter struct {
+	glob       string
+	extensions map[string]bool
+	keepDirs   bool // Directories are listed regardless of the filter
+}
+
+// parseFileFilter parses a filter value. Values with glob characters are globs, anything
+// else is a comma-separated list of extensions ("jar, .yml"). Matching ignores case.
+func parseFileFilter(value string, keepDirs bool) (*fileFilter, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	filter := &fileFilter{keepDirs: keepDirs}
+	if strings.ContainsAny(value, "*?[") {
+		filter.glob = strings.ToLower(value)
+		if _, err := filepath.Match(filter.glob, ""); err != nil {
+			return nil, err
+		}
+		return filter, nil
+	}
+
+	filter.extensions = make(map[string]bool)
+	for _, ext := range strings.Split(value, ",") {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext != "" {
+			filter.extensions[ext] = true
+		}
+	}
+	return filter, nil
+}
+
+// apply returns the matching entries in a new slice, leaving files (which may be cached) untouched
+func (f *fileFilter) apply(files []FileInfo) []FileInfo {
+	matched := make([]FileInfo, 0)
+	for _, file := range files {
+		if file.IsDir && f.keepDirs {
+			matched = append(matched, file)
+			continue
+		}
+
+		name := strings.ToLower(file.Name)
+		if f.glob != "" {
+			if ok, _ := filepath.Match(f.glob, name); ok {
+				matched = append(matched, file)
+			}
+			continue
+		}
+		if !file.IsDir && f.extensions[strings.ToLower(file.Extension)] {
+			matched = append(matched, file)
+		}
+	}
+	return matched
+}
+
+// Listings of unchanged directories are served from cache for a short time. The directory
+// modtime is part of the key, so adding, removing or renaming entries invalidates it at once;
+// the TTL bounds how long in-place edits to a file's size go unnoticed.
+const (
+	listingCacheTTL        = 5 * time.Second
+	listingCacheMaxEntries = 256
+)
+
+type listingCacheEntry struct {
+	modTime  time.Time
+	cachedAt time.Time
+	files    []FileInfo
+}
+
+var (
+	listingCache    = make(map[string]listingCacheEntry)
+	listingCacheMux sync.Mutex
+)
+
+// getCachedListing returns a cached listing if it is fresh and the directory hasn't changed
+func getCachedListing(key string, modTime time.Time) ([]FileInfo, bool) {
+	listingCacheMux.Lock()
+	defer listingCacheMux.Unlock()
+
+	entry, exists := listingCache[key]
+	if !exists || !entry.modTime.Equal(modTime) || time.Since(entry.cachedAt) >= listingCacheTTL {
+		return nil, false
+	}
+	return entry.files, true
+}
+
+// putCachedListing stores a listing, evicting expired entries (and then the oldest) when full
+func putCachedListing(key string, modTime time.Time, files []FileInfo) {
+	listingCacheMux.Lock()
+	defer listingCacheMux.Unlock()
+
+	if len(listingCache) >= listingCacheMaxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for k, entry := range listingCache {
+			if time.Since(entry.cachedAt) >= listingCacheTTL {
+				delete(listingCache, k)
+				continue
+			}
+			if oldestKey == "" || entry.cachedAt.Before(oldest) {
+				oldestKey, oldest = k, entry.cachedAt
+			}
+		}
+		if len(listingCache) >= listingCacheMaxEntries {
+			delete(listingCache, oldestKey)
+		}
+	}
+
+	listingCache[key] = listingCacheEntry{
+		modTime:  modTime,
+		cachedAt: time.Now(),
+		files:    files,
+	}
+}
+
 // FilesPage renders the file manager page
 func FilesPage(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -95,19 +230,40 @@ func ListFiles(w http.ResponseWriter, r *http.Request) {
 		requestedPath = "/"
 	}
 
+	// Optional filter, applied after the directory is read (and cached) in full
+	keepDirsStr := r.URL.Query().Get("keep_dirs")
+	filter, err := parseFileFilter(r.URL.Query().Get("filter"), keepDirsStr == "true" || keepDirsStr == "1")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ListDirectoryResponse{
+			Error: "Invalid filter pattern",
+		})
+		return
+	}
+
+	// Resolve against the server files or a temporary backup mount
+	fileRoot, boundary, ok := fileRootForRequest(r, server)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ListDirectoryResponse{
+			Error: "Backup mount not found or expired",
+		})
+		return
+	}
+
 	// Build full path
 	var fullPath string
 	if requestedPath == "/" {
-		fullPath = server.FolderPath
+		fullPath = fileRoot
 	} else {
 		// Remove leading slash and join with server path
 		relativePath := strings.TrimPrefix(requestedPath, "/")
-		fullPath = filepath.Join(server.FolderPath, relativePath)
+		fullPath = filepath.Join(fileRoot, relativePath)
 	}
 
 	// Security check: ensure the path is within the server folder
 	cleanPath := filepath.Clean(fullPath)
-	if !strings.HasPrefix(cleanPath, server.FolderPath) {
+	if !strings.HasPrefix(cleanPath, boundary) {
 		w.WriteHeader(http.StatusForbidden)
 		json.NewEncoder(w).Encode(ListDirectoryResponse{
 			Error: "Access denied: path outside server directory",
@@ -133,6 +289,19 @@ func ListFiles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Walking every subdirectory is expensive, so recursive stats are opt-in
+	recursiveStatsStr := r.URL.Query().Get("recursive_stats")
+	recursiveStats := recursiveStatsStr == "true" || recursiveStatsStr == "1"
+
+	// Serve repeated polls of an unchanged directory from cache (recursive stats have their own cache)
+	cacheKey := fmt.Sprintf("%d:%s", server.ID, cleanPath)
+	if !recursiveStats {
+		if files, ok := getCachedListing(cacheKey, fileInfo.ModTime()); ok {
+			writeListing(w, requestedPath, files, filter)
+			return
+		}
+	}
+
 	// Read directory contents
 	entries, err := ioutil.ReadDir(cleanPath)
 	if err != nil {
@@ -143,6 +312,10 @@ func ListFiles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// One deadline covers every folder walked, so a stuck mount can't hold the listing
+	statsCtx, cancel := services.WithOperationTimeout(r.Context())
+	defer cancel()
+
 	// Convert to FileInfo array
 	files := make([]FileInfo, 0)
 	for _, entry := range entries {
@@ -163,6 +336,19 @@ func ListFiles(w http.ResponseWriter, r *http.Request) {
 			fileInfo.Extension = strings.TrimPrefix(filepath.Ext(entry.Name()), ".")
 		}
 
+		if recursiveStats && entry.IsDir() {
+			stats, err := services.GetDirStats(statsCtx, filepath.Join(cleanPath, entry.Name()))
+			if r.Context().Err() != nil {
+				// Client went away, stop walking
+				return
+			}
+			if err == nil {
+				fileInfo.TotalSize = &stats.TotalSize
+				fileInfo.ItemCount = &stats.ItemCount
+				fileInfo.StatsTruncated = stats.Truncated
+			}
+		}
+
 		files = append(files, fileInfo)
 	}
 
@@ -177,11 +363,26 @@ func ListFiles(w http.ResponseWriter, r *http.Request) {
 		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
 	})
 
+	if !recursiveStats {
+		putCachedListing(cacheKey, fileInfo.ModTime(), files)
+	}
+
 	// Return response
-	json.NewEncoder(w).Encode(ListDirectoryResponse{
+	writeListing(w, requestedPath, files, filter)
+}
+
+// writeListing writes a directory listing, narrowed by filter when one is given
+func writeListing(w http.ResponseWriter, requestedPath string, files []FileInfo, filter *fileFilter) {
+	response := ListDirectoryResponse{
 		CurrentPath: requestedPath,
 		Files:       files,
-	})
+	}
+	if filter != nil {
+		response.Files = filter.apply(files)
+		matched := len(response.Files)
+		response.Matched = &matched
+	}
+	json.NewEncoder(w).Encode(response)
 }
 
 // NavigateFolder navigates to a specific folder
@@ -198,6 +399,7 @@ func NavigateFolder(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{
 			"error": "Server not found",
+			"code":  ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -210,6 +412,7 @@ func NavigateFolder(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{
 			"error": "Folder name is required",
+			"code":  ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -223,13 +426,14 @@ func NavigateFolder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Security check: ensure the new path is within the server folder
-	fullPath := filepath.Join(server.FolderPath, strings.TrimPrefix(newPath, "/"))
+	fullPath := filepath.Join(server.FileRootPath(), strings.TrimPrefix(newPath, "/"))
 	cleanPath := filepath.Clean(fullPath)
 	
 	if !strings.HasPrefix(cleanPath, server.FolderPath) {
 		w.WriteHeader(http.StatusForbidden)
 		json.NewEncoder(w).Encode(map[string]string{
 			"error": "Access denied: path outside server directory",
+			"code":  ErrCodePathOutsideRoot,
 		})
 		return
 	}
@@ -240,6 +444,7 @@ func NavigateFolder(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{
 			"error": "Folder not found",
+			"code":  ErrCodeFileNotFound,
 		})
 		return
 	}
@@ -248,6 +453,7 @@ func NavigateFolder(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{
 			"error": "Path is not a directory",
+			"code":  ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -258,6 +464,88 @@ func NavigateFolder(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ResolvePath validates and normalizes a file manager path, returning it along with its parent.
+// Accepts panel paths ("/world/region") as well as absolute paths pasted from the server folder.
+func ResolvePath(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	requestedPath := strings.TrimSpace(r.URL.Query().Get("path"))
+	if requestedPath == "" {
+		requestedPath = "/"
+	}
+
+	rootPath := server.FileRootPath()
+
+	// Build full path (absolute paths inside the server folder are taken as-is)
+	var fullPath string
+	if strings.HasPrefix(filepath.Clean(requestedPath), server.FolderPath) {
+		fullPath = filepath.Clean(requestedPath)
+	} else {
+		fullPath = filepath.Join(rootPath, strings.TrimPrefix(requestedPath, "/"))
+	}
+
+	// Security check: ensure the path is within the file manager root, which the returned
+	// panel path is relative to. Parts of the server folder above the root are refused too.
+	cleanPath := filepath.Clean(fullPath)
+	relPath, err := filepath.Rel(rootPath, cleanPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	// Check if path exists
+	fileInfo, err := os.Stat(cleanPath)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Path not found",
+			"code":    ErrCodeFileNotFound,
+		})
+		return
+	}
+
+	// Convert back to a panel path relative to the file manager root
+	normalizedPath := "/"
+	if relPath != "." {
+		normalizedPath = "/" + filepath.ToSlash(relPath)
+	}
+
+	parentPath := ""
+	if normalizedPath != "/" {
+		parentPath = filepath.ToSlash(filepath.Dir(normalizedPath))
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"path":        normalizedPath,
+		"parent_path": parentPath,
+		"name":        fileInfo.Name(),
+		"is_dir":      fileInfo.IsDir(),
+	})
+}
+
 // CreateDirectory creates a new directory
 func CreateDirectory(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -273,6 +561,7 @@ func CreateDirectory(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -283,6 +572,7 @@ func CreateDirectory(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -296,6 +586,17 @@ func CreateDirectory(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Directory name is required",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	if err := validateFileName(dirName); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid directory name: " + err.Error(),
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -303,10 +604,10 @@ func CreateDirectory(w http.ResponseWriter, r *http.Request) {
 	// Build full path
 	var fullPath string
 	if currentPath == "/" || currentPath == "" {
-		fullPath = filepath.Join(server.FolderPath, dirName)
+		fullPath = filepath.Join(server.FileRootPath(), dirName)
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		fullPath = filepath.Join(server.FolderPath, relativePath, dirName)
+		fullPath = filepath.Join(server.FileRootPath(), relativePath, dirName)
 	}
 
 	// Security check: ensure the path is within the server folder
@@ -316,6 +617,7 @@ func CreateDirectory(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
 		})
 		return
 	}
@@ -326,16 +628,24 @@ func CreateDirectory(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Directory already exists",
+			"code":    ErrCodeConflict,
 		})
 		return
 	}
 
+	// The parent folder must be writable by the panel
+	if err := checkFolderAccess(filepath.Dir(cleanPath)); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
 	// Create directory
-	if err := os.Mkdir(cleanPath, 0755); err != nil {
+	if err := mkdirWithMode(cleanPath, server.DirPerm()); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to create directory: " + err.Error(),
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
@@ -348,6 +658,9 @@ func CreateDirectory(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// maxUploadSize is the largest file accepted by uploads and URL fetches
+const maxUploadSize = 100 << 20
+
 // UploadFile uploads a file
 func UploadFile(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -363,17 +676,19 @@ func UploadFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
 
 	// Parse multipart form (max 100MB)
-	err = r.ParseMultipartForm(100 << 20)
+	err = r.ParseMultipartForm(maxUploadSize)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to parse upload",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -385,21 +700,72 @@ func UploadFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "No file uploaded",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 	defer file.Close()
 
+	if err := validateFileName(header.Filename); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid file name: " + err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Optionally store a .gz upload decompressed under its name without the extension
+	fileName := header.Filename
+	decompressStr := r.FormValue("decompress")
+	decompress := decompressStr == "true" || decompressStr == "1"
+	if decompress {
+		if !strings.HasSuffix(strings.ToLower(fileName), ".gz") {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Only .gz files can be decompressed on upload",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+		fileName = fileName[:len(fileName)-len(".gz")]
+		if err := validateFileName(fileName); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid decompressed file name: " + err.Error(),
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+	}
+
+	// Optionally extract a supported archive into the target folder once it is saved;
+	// uploads in other formats are stored as they are
+	autoExtractStr := r.FormValue("auto_extract")
+	autoExtract := autoExtractStr == "true" || autoExtractStr == "1"
+	if autoExtract && decompress {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Choose either decompress or auto_extract, not both",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
 	// Get target path
 	currentPath := r.FormValue("path")
 
 	// Build full path
 	var fullPath string
 	if currentPath == "/" || currentPath == "" {
-		fullPath = filepath.Join(server.FolderPath, header.Filename)
+		fullPath = filepath.Join(server.FileRootPath(), fileName)
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		fullPath = filepath.Join(server.FolderPath, relativePath, header.Filename)
+		fullPath = filepath.Join(server.FileRootPath(), relativePath, fileName)
 	}
 
 	// Security check: ensure the path is within the server folder
@@ -409,32 +775,100 @@ func UploadFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	// Uploads need the panel to be able to create or overwrite the file
+	if err := checkWriteAccess(cleanPath); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	// Overwriting a protected file needs an explicit override
+	var protectedErr error
+	if _, err := os.Lstat(cleanPath); err == nil {
+		protectedErr = checkProtectedPath(server, cleanPath)
+		if protectedErr != nil && !protectionOverride(r) {
+			writeProtectedError(w, protectedErr)
+			return
+		}
+	}
+
+	if err := services.CheckDiskReserve(cleanPath, header.Size); err != nil {
+		writeDiskReserveError(w, err)
+		return
+	}
+
+	if decompress {
+		size, err := gunzipToFile(file, cleanPath, server.FilePerm(), config.GetUploadGunzipMaxBytes())
+		if errors.Is(err, services.ErrDiskReserve) {
+			writeDiskReserveError(w, err)
+			return
+		}
+		if err != nil {
+			status, code := http.StatusBadRequest, ErrCodeInvalidRequest
+			if errors.Is(err, errGunzipTooLarge) {
+				status, code = http.StatusRequestEntityTooLarge, ErrCodeTooLarge
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Failed to decompress file: " + err.Error(),
+				"code":    code,
+			})
+			return
+		}
+		if protectedErr != nil {
+			auditProtectionOverride(r, server, "upload", cleanPath)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":         true,
+			"message":         "File uploaded and decompressed successfully",
+			"filename":        fileName,
+			"size":            size,
+			"compressed_size": header.Size,
+			"decompressed":    true,
 		})
 		return
 	}
 
 	// Create destination file
-	dst, err := os.Create(cleanPath)
+	dst, err := createFileWithMode(cleanPath, server.FilePerm())
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to create file: " + err.Error(),
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
-	defer dst.Close()
 
 	// Copy uploaded file to destination
 	_, err = io.Copy(dst, file)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to save file: " + err.Error(),
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
+	if protectedErr != nil {
+		auditProtectionOverride(r, server, "upload", cleanPath)
+	}
+
+	if autoExtract && archiveExtractor(fileName) != nil {
+		writeUploadExtractResult(w, r, server, cleanPath, header.Size)
+		return
+	}
 
 	// Return success
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -445,8 +879,61 @@ func UploadFile(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// CreateNewFile creates a new empty file
-func CreateNewFile(w http.ResponseWriter, r *http.Request) {
+// writeUploadExtractResult starts extracting a just-uploaded archive next to it as a background
+// job, with the same guards as UnarchiveFile. The upload stands when extraction fails, so the
+// archive can still be extracted by hand; delete_after removes it only after a clean
+// extraction, and best_effort skips entries that fail.
+func writeUploadExtractResult(w http.ResponseWriter, r *http.Request, server *models.Server, archivePath string, size int64) {
+	fileName := filepath.Base(archivePath)
+
+	deleteAfterStr := r.FormValue("delete_after")
+	deleteAfter := deleteAfterStr == "true" || deleteAfterStr == "1"
+	bestEffortStr := r.FormValue("best_effort")
+	bestEffort := bestEffortStr == "true" || bestEffortStr == "1"
+	override := protectionOverride(r)
+
+	job := services.NewJob(server.ID, "extract", []string{fileName})
+	job.Start(func(job *services.Job) error {
+		job.AddTotal(size)
+
+		report := &extractReport{
+			BestEffort: bestEffort,
+			FileMode:   extractFileMode(server),
+			DirMode:    server.DirPerm(),
+			Server:     server,
+			Override:   override,
+			MaxBytes:   config.GetExtractMaxBytes(),
+			Failures:   make([]map[string]string, 0),
+		}
+		extracted, deleted, err := extractArchive(archivePath, filepath.Dir(archivePath), job, report, deleteAfter)
+		for _, path := range report.Overridden {
+			auditProtectionOverride(r, server, "extract", path)
+		}
+		job.SetResult("extracted", extracted)
+		job.SetResult("extracted_entries", report.Extracted)
+		job.SetResult("failed_entries", report.Failures)
+		job.SetResult("archive_deleted", deleted)
+
+		if err == nil && !extracted && len(report.Failures) > 0 {
+			err = errors.New(report.Failures[len(report.Failures)-1]["error"])
+		}
+		if err != nil {
+			log.Printf("⚠️  Failed to extract uploaded archive %s: %v", archivePath, err)
+		}
+		return err
+	})
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"message":  "File uploaded, extraction started",
+		"filename": fileName,
+		"size":     size,
+		"job_id":   job.ID,
+	})
+}
+
+// DownloadFromURL fetches a remote file straight into the server directory
+func DownloadFromURL(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	vars := mux.Vars(r)
@@ -460,50 +947,64 @@ func CreateNewFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
 
-	// Parse form data
-	if err := r.ParseForm(); err != nil {
+	rawURL := strings.TrimSpace(r.FormValue("url"))
+	parsedURL, err := url.Parse(rawURL)
+	if rawURL == "" || err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   "Error parsing form",
+			"error":   "A valid URL is required",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+	if _, err := services.ValidateFetchURL(parsedURL); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    ErrCodeAccessDenied,
 		})
 		return
 	}
 
-	currentPath := r.FormValue("path")
-	fileName := r.FormValue("name")
-
-	// Validate input
-	if fileName == "" {
+	// File name defaults to the last segment of the URL path
+	fileName := filepath.Base(strings.TrimSpace(r.FormValue("filename")))
+	if fileName == "." || fileName == "/" {
+		fileName = path.Base(parsedURL.Path)
+	}
+	if fileName == "" || fileName == "." || fileName == "/" || fileName == ".." {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   "File name is required",
+			"error":   "Could not determine a file name, please provide one",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
-
-	// Validate file has extension
-	if !strings.Contains(fileName, ".") {
+	if err := validateFileName(fileName); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   "File name must include an extension",
+			"error":   "Invalid file name: " + err.Error(),
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 
 	// Build full path
+	currentPath := r.FormValue("path")
 	var fullPath string
 	if currentPath == "/" || currentPath == "" {
-		fullPath = filepath.Join(server.FolderPath, fileName)
+		fullPath = filepath.Join(server.FileRootPath(), fileName)
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		fullPath = filepath.Join(server.FolderPath, relativePath, fileName)
+		fullPath = filepath.Join(server.FileRootPath(), relativePath, fileName)
 	}
 
 	// Security check: ensure the path is within the server folder
@@ -513,6 +1014,7 @@ func CreateNewFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
 		})
 		return
 	}
@@ -522,33 +1024,48 @@ func CreateNewFile(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusConflict)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   "File already exists",
+			"error":   "File '" + fileName + "' already exists",
+			"code":    ErrCodeConflict,
 		})
 		return
 	}
 
-	// Create empty file
-	file, err := os.Create(cleanPath)
+	// Check the folder is writable before fetching anything
+	if err := checkFolderAccess(filepath.Dir(cleanPath)); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	size, err := services.FetchURLToFile(r.Context(), parsedURL.String(), cleanPath, maxUploadSize)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		status, code := http.StatusBadGateway, ErrCodeFetchFailed
+		if errors.Is(err, services.ErrFetchTooLarge) {
+			status, code = http.StatusRequestEntityTooLarge, ErrCodeTooLarge
+		} else if errors.Is(err, services.ErrOperationTimeout) {
+			status, code = http.StatusGatewayTimeout, ErrCodeTimeout
+		}
+		log.Printf("❌ Failed to fetch %s for server '%s': %v", parsedURL.Redacted(), server.Name, err)
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   "Failed to create file: " + err.Error(),
+			"error":   "Failed to download file: " + err.Error(),
+			"code":    code,
 		})
 		return
 	}
-	file.Close()
 
-	// Return success
+	log.Printf("✅ Fetched %s into server '%s' (%d bytes)", fileName, server.Name, size)
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"message": "File created successfully",
-		"name":    fileName,
+		"success":  true,
+		"message":  "File downloaded successfully",
+		"filename": fileName,
+		"size":     size,
 	})
 }
 
-// RenameFile renames a file or directory
-func RenameFile(w http.ResponseWriter, r *http.Request) {
+// CreateNewFile creates a new empty file
+func CreateNewFile(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	vars := mux.Vars(r)
@@ -562,6 +1079,7 @@ func RenameFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -572,29 +1090,168 @@ func RenameFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 
 	currentPath := r.FormValue("path")
-	oldName := r.FormValue("old_name")
-	newName := r.FormValue("new_name")
+	fileName := r.FormValue("name")
 
 	// Validate input
-	if oldName == "" || newName == "" {
+	if fileName == "" {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   "Both old name and new name are required",
+			"error":   "File name is required",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 
-	if oldName == newName {
+	if err := validateFileName(fileName); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid file name: " + err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Validate file has extension
+	if !strings.Contains(fileName, ".") {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "File name must include an extension",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Build full path
+	var fullPath string
+	if currentPath == "/" || currentPath == "" {
+		fullPath = filepath.Join(server.FileRootPath(), fileName)
+	} else {
+		relativePath := strings.TrimPrefix(currentPath, "/")
+		fullPath = filepath.Join(server.FileRootPath(), relativePath, fileName)
+	}
+
+	// Security check: ensure the path is within the server folder
+	cleanPath := filepath.Clean(fullPath)
+	if !strings.HasPrefix(cleanPath, server.FolderPath) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	// Check if file already exists
+	if _, err := os.Stat(cleanPath); err == nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "File already exists",
+			"code":    ErrCodeConflict,
+		})
+		return
+	}
+
+	// The panel needs write access to the folder the file goes in
+	if err := checkFolderAccess(filepath.Dir(cleanPath)); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	// Create empty file
+	file, err := createFileWithMode(cleanPath, server.FilePerm())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to create file: " + err.Error(),
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+	file.Close()
+
+	// Return success
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "File created successfully",
+		"name":    fileName,
+	})
+}
+
+// RenameFile renames a file or directory
+func RenameFile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	currentPath := r.FormValue("path")
+	oldName := r.FormValue("old_name")
+	newName := r.FormValue("new_name")
+
+	// Validate input
+	if oldName == "" || newName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Both old name and new name are required",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	if oldName == newName {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "New name is the same as old name",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// The old name only has to resolve to an existing file, the new one must be a clean name
+	if err := validateFileName(newName); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid new name: " + err.Error(),
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -602,19 +1259,19 @@ func RenameFile(w http.ResponseWriter, r *http.Request) {
 	// Build old full path
 	var oldFullPath string
 	if currentPath == "/" || currentPath == "" {
-		oldFullPath = filepath.Join(server.FolderPath, oldName)
+		oldFullPath = filepath.Join(server.FileRootPath(), oldName)
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		oldFullPath = filepath.Join(server.FolderPath, relativePath, oldName)
+		oldFullPath = filepath.Join(server.FileRootPath(), relativePath, oldName)
 	}
 
 	// Build new full path
 	var newFullPath string
 	if currentPath == "/" || currentPath == "" {
-		newFullPath = filepath.Join(server.FolderPath, newName)
+		newFullPath = filepath.Join(server.FileRootPath(), newName)
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		newFullPath = filepath.Join(server.FolderPath, relativePath, newName)
+		newFullPath = filepath.Join(server.FileRootPath(), relativePath, newName)
 	}
 
 	// Security check: ensure both paths are within the server folder
@@ -626,6 +1283,7 @@ func RenameFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
 		})
 		return
 	}
@@ -636,16 +1294,31 @@ func RenameFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "File or directory not found",
+			"code":    ErrCodeFileNotFound,
 		})
 		return
 	}
 
+	// Protected files need an explicit override
+	protectedErr := checkProtectedPath(server, cleanOldPath)
+	if protectedErr != nil && !protectionOverride(r) {
+		writeProtectedError(w, protectedErr)
+		return
+	}
+
+	// Renaming needs write access to the folder holding the entry
+	if err := checkFolderAccess(filepath.Dir(cleanOldPath)); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
 	// Check if new name already exists
 	if _, err := os.Stat(cleanNewPath); err == nil {
 		w.WriteHeader(http.StatusConflict)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "A file or directory with this name already exists",
+			"code":    ErrCodeConflict,
 		})
 		return
 	}
@@ -656,10 +1329,19 @@ func RenameFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to rename: " + err.Error(),
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
 
+	journal := services.NewFileJournalEntry(server.ID, services.JournalMove)
+	journal.AddMove(cleanOldPath, cleanNewPath)
+	journal.Commit()
+
+	if protectedErr != nil {
+		auditProtectionOverride(r, server, "rename", cleanOldPath)
+	}
+
 	// Return success
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -684,6 +1366,7 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -694,6 +1377,7 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -708,6 +1392,7 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Source path, target path, and files are required",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -719,6 +1404,7 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid files format",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -728,6 +1414,7 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "No files to move",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -738,13 +1425,14 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Cannot move files to the same directory",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 
 	// Build full paths
-	sourceFullPath := filepath.Join(server.FolderPath, strings.TrimPrefix(sourcePath, "/"))
-	targetFullPath := filepath.Join(server.FolderPath, strings.TrimPrefix(targetPath, "/"))
+	sourceFullPath := filepath.Join(server.FileRootPath(), strings.TrimPrefix(sourcePath, "/"))
+	targetFullPath := filepath.Join(server.FileRootPath(), strings.TrimPrefix(targetPath, "/"))
 
 	// Security check
 	if !strings.HasPrefix(filepath.Clean(sourceFullPath), server.FolderPath) ||
@@ -753,6 +1441,7 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
 		})
 		return
 	}
@@ -764,6 +1453,7 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Target directory not found",
+			"code":    ErrCodeFileNotFound,
 		})
 		return
 	}
@@ -773,10 +1463,37 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Target path is not a directory",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 
+	// Both folders change, so the panel needs write access to each
+	for _, dir := range []string{sourceFullPath, targetFullPath} {
+		if err := checkFolderAccess(dir); err != nil {
+			writeAccessError(w, err)
+			return
+		}
+	}
+
+	// Protected files need an explicit override, checked up front so nothing is moved on refusal
+	override := protectionOverride(r)
+	var protectedPaths []string
+	for _, fileName := range files {
+		sourceFilePath := filepath.Join(sourceFullPath, fileName)
+		if err := checkProtectedPath(server, sourceFilePath); err != nil {
+			if !override {
+				writeProtectedError(w, err)
+				return
+			}
+			protectedPaths = append(protectedPaths, sourceFilePath)
+		}
+	}
+
+	// Journal the files moved so far, even when a later one fails
+	journal := services.NewFileJournalEntry(server.ID, services.JournalMove)
+	defer journal.Commit()
+
 	// Move each file
 	movedCount := 0
 	for _, fileName := range files {
@@ -794,6 +1511,7 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(map[string]interface{}{
 				"success": false,
 				"error":   "File '" + fileName + "' already exists in target directory",
+				"code":    ErrCodeConflict,
 			})
 			return
 		}
@@ -804,13 +1522,19 @@ func MoveFiles(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(map[string]interface{}{
 				"success": false,
 				"error":   "Failed to move '" + fileName + "': " + err.Error(),
+				"code":    ErrCodeInternal,
 			})
 			return
 		}
+		journal.AddMove(sourceFilePath, targetFilePath)
 
 		movedCount++
 	}
 
+	for _, path := range protectedPaths {
+		auditProtectionOverride(r, server, "move", path)
+	}
+
 	// Return success
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -834,6 +1558,7 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -844,6 +1569,7 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -858,6 +1584,7 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Source path, target path, and files are required",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -869,6 +1596,7 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid files format",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -878,13 +1606,14 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "No files to copy",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 
 	// Build full paths
-	sourceFullPath := filepath.Join(server.FolderPath, strings.TrimPrefix(sourcePath, "/"))
-	targetFullPath := filepath.Join(server.FolderPath, strings.TrimPrefix(targetPath, "/"))
+	sourceFullPath := filepath.Join(server.FileRootPath(), strings.TrimPrefix(sourcePath, "/"))
+	targetFullPath := filepath.Join(server.FileRootPath(), strings.TrimPrefix(targetPath, "/"))
 
 	// Security check
 	if !strings.HasPrefix(filepath.Clean(sourceFullPath), server.FolderPath) ||
@@ -893,6 +1622,7 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
 		})
 		return
 	}
@@ -904,6 +1634,7 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Target directory not found",
+			"code":    ErrCodeFileNotFound,
 		})
 		return
 	}
@@ -913,10 +1644,23 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Target path is not a directory",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 
+	// The target folder must be writable by the panel
+	if err := checkFolderAccess(targetFullPath); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	// The copies must fit without eating into the reserved free space
+	if err := services.CheckDiskReserve(targetFullPath, copySize(r.Context(), sourceFullPath, files)); err != nil {
+		writeDiskReserveError(w, err)
+		return
+	}
+
 	// Copy each file
 	copiedCount := 0
 	for _, fileName := range files {
@@ -935,6 +1679,7 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 			json.NewEncoder(w).Encode(map[string]interface{}{
 				"success": false,
 				"error":   "File '" + fileName + "' already exists in target directory",
+				"code":    ErrCodeConflict,
 			})
 			return
 		}
@@ -947,6 +1692,7 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 				json.NewEncoder(w).Encode(map[string]interface{}{
 					"success": false,
 					"error":   "Failed to copy directory '" + fileName + "': " + err.Error(),
+					"code":    ErrCodeInternal,
 				})
 				return
 			}
@@ -957,6 +1703,7 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 				json.NewEncoder(w).Encode(map[string]interface{}{
 					"success": false,
 					"error":   "Failed to copy file '" + fileName + "': " + err.Error(),
+					"code":    ErrCodeInternal,
 				})
 				return
 			}
@@ -973,6 +1720,37 @@ func CopyFiles(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// copySize returns the total size of the named files and folders in sourceDir. Folders that
+// can't be measured in time count as empty.
+func copySize(ctx context.Context, sourceDir string, names []string) int64 {
+	ctx, cancel := services.WithOperationTimeout(ctx)
+	defer cancel()
+
+	var total int64
+	for _, name := range names {
+		info, err := os.Stat(filepath.Join(sourceDir, name))
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			total += info.Size()
+		} else if stats, err := services.GetDirStats(ctx, filepath.Join(sourceDir, name)); err == nil {
+			total += stats.TotalSize
+		}
+	}
+	return total
+}
+
+// writeDiskReserveError reports an operation refused to protect the reserved free space
+func writeDiskReserveError(w http.ResponseWriter, err error) {
+	w.WriteHeader(http.StatusInsufficientStorage)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   err.Error(),
+		"code":    ErrCodeDiskReserve,
+	})
+}
+
 // copyFile copies a single file from src to dst
 func copyFile(src, dst string) error {
 	sourceFile, err := os.Open(src)
@@ -1002,11 +1780,40 @@ func copyFile(src, dst string) error {
 
 // copyDir recursively copies a directory from src to dst
 func copyDir(src, dst string) error {
+	return copyDirGuarded(src, dst, services.NewWalkGuard(src))
+}
+
+// copyDirSequentialMax is the file count below which a copy isn't worth spreading over workers
+const copyDirSequentialMax = 32
+
+// copyPair is a file to copy and where to copy it
+type copyPair struct {
+	src string
+	dst string
+}
+
+// copyDirGuarded copies a directory, using guard to stop on loops and overly deep trees. The
+// folders are created first, parents before children, then the files are copied in parallel.
+func copyDirGuarded(src, dst string, guard *services.WalkGuard) error {
+	var files []copyPair
+	if err := copyDirTree(src, dst, guard, &files); err != nil {
+		return err
+	}
+	return copyFilesParallel(files, config.GetCopyConcurrency())
+}
+
+// copyDirTree recreates the folders under src at dst and collects the files to copy into them.
+// Symlinks are recreated as links to the same target rather than followed, so a link to a
+// folder can't loop the copy or pull in files from outside the server folder.
+func copyDirTree(src, dst string, guard *services.WalkGuard, files *[]copyPair) error {
 	// Get source directory info
 	sourceInfo, err := os.Stat(src)
 	if err != nil {
 		return err
 	}
+	if err := guard.EnterDir(src, sourceInfo); err != nil {
+		return err
+	}
 
 	// Create destination directory
 	if err := os.MkdirAll(dst, sourceInfo.Mode()); err != nil {
@@ -1019,25 +1826,85 @@ func copyDir(src, dst string) error {
 		return err
 	}
 
-	// Copy each entry
 	for _, entry := range entries {
 		sourcePath := filepath.Join(src, entry.Name())
 		destPath := filepath.Join(dst, entry.Name())
 
+		if entry.Mode()&os.ModeSymlink != 0 {
+			link, err := os.Readlink(sourcePath)
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(link, destPath); err != nil {
+				return err
+			}
+			continue
+		}
+
 		if entry.IsDir() {
-			// Recursively copy subdirectory
-			if err := copyDir(sourcePath, destPath); err != nil {
+			if services.IsBackupDir(sourcePath) {
+				continue
+			}
+
+			// Recursively create subdirectory
+			if err := copyDirTree(sourcePath, destPath, guard, files); err != nil {
 				return err
 			}
 		} else {
-			// Copy file
-			if err := copyFile(sourcePath, destPath); err != nil {
+			*files = append(*files, copyPair{src: sourcePath, dst: destPath})
+		}
+	}
+
+	return nil
+}
+
+// copyFilesParallel copies files using up to workers copies at a time, stopping at the first
+// error. Small batches are copied one by one, where starting workers costs more than it saves.
+func copyFilesParallel(files []copyPair, workers int) error {
+	if workers <= 1 || len(files) < copyDirSequentialMax {
+		for _, file := range files {
+			if err := copyFile(file.src, file.dst); err != nil {
 				return err
 			}
 		}
+		return nil
 	}
 
-	return nil
+	var (
+		wg       sync.WaitGroup
+		failOnce sync.Once
+		firstErr error
+	)
+	pending := make(chan copyPair)
+	failed := make(chan struct{})
+
+	for i := 0; i < min(workers, len(files)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range pending {
+				if err := copyFile(file.src, file.dst); err != nil {
+					failOnce.Do(func() {
+						firstErr = err
+						close(failed)
+					})
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, file := range files {
+		select {
+		case pending <- file:
+		case <-failed:
+			break dispatch
+		}
+	}
+	close(pending)
+	wg.Wait()
+
+	return firstErr
 }
 
 // DeleteFiles deletes selected files/folders (STUB)
@@ -1055,6 +1922,7 @@ func DeleteFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -1064,6 +1932,7 @@ func DeleteFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid form data",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -1077,6 +1946,7 @@ func DeleteFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid files data",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -1085,6 +1955,7 @@ func DeleteFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "No files selected",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -1092,10 +1963,10 @@ func DeleteFiles(w http.ResponseWriter, r *http.Request) {
 	// Build full path
 	var fullPath string
 	if currentPath == "/" || currentPath == "" {
-		fullPath = server.FolderPath
+		fullPath = server.FileRootPath()
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		fullPath = filepath.Join(server.FolderPath, relativePath)
+		fullPath = filepath.Join(server.FileRootPath(), relativePath)
 	}
 
 	// Validate path is within server directory
@@ -1103,10 +1974,34 @@ func DeleteFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid path",
+			"code":    ErrCodePathOutsideRoot,
 		})
 		return
 	}
 
+	// Deleting needs write access to the folder holding the entries
+	if err := checkFolderAccess(fullPath); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	// Protected files need an explicit override, checked up front so nothing is deleted on refusal
+	override := protectionOverride(r)
+	protectedPaths := make(map[string]bool)
+	for _, fileName := range fileNames {
+		filePath := filepath.Join(fullPath, fileName)
+		if err := checkProtectedPath(server, filePath); err != nil {
+			if !override {
+				writeProtectedError(w, err)
+				return
+			}
+			protectedPaths[filePath] = true
+		}
+	}
+
+	// Small files are copied aside first so the delete can be undone
+	journal := services.NewFileJournalEntry(server.ID, services.JournalDelete)
+
 	// Delete each file/folder
 	deletedCount := 0
 	var errors []string
@@ -1127,14 +2022,22 @@ func DeleteFiles(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Delete file or folder (RemoveAll works for both)
+		journal.Stash(filePath)
 		if err := os.RemoveAll(filePath); err != nil {
+			journal.Forget(filePath)
 			errors = append(errors, fmt.Sprintf("Failed to delete %s: %v", fileName, err))
 			continue
 		}
 
+		if protectedPaths[filePath] {
+			auditProtectionOverride(r, server, "delete", filePath)
+		}
+
 		deletedCount++
 	}
 
+	journal.Commit()
+
 	// Prepare response
 	if deletedCount > 0 {
 		response := map[string]interface{}{
@@ -1153,13 +2056,14 @@ func DeleteFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to delete files",
+			"code":    ErrCodeInternal,
 			"errors":  errors,
 		})
 	}
 }
 
 // ArchiveFiles creates an archive of selected files/folders (STUB)
-// ArchiveFiles creates a tar.gz archive of selected files/folders
+// ArchiveFiles creates a tar.gz archive of selected files/folders as a background job
 func ArchiveFiles(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -1173,6 +2077,7 @@ func ArchiveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -1182,6 +2087,7 @@ func ArchiveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid form data",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -1195,6 +2101,7 @@ func ArchiveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid files data",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -1203,6 +2110,7 @@ func ArchiveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "No files selected",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -1210,10 +2118,10 @@ func ArchiveFiles(w http.ResponseWriter, r *http.Request) {
 	// Build full path
 	var fullPath string
 	if currentPath == "/" || currentPath == "" {
-		fullPath = server.FolderPath
+		fullPath = server.FileRootPath()
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		fullPath = filepath.Join(server.FolderPath, relativePath)
+		fullPath = filepath.Join(server.FileRootPath(), relativePath)
 	}
 
 	// Validate path is within server directory
@@ -1221,6 +2129,7 @@ func ArchiveFiles(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid path",
+			"code":    ErrCodePathOutsideRoot,
 		})
 		return
 	}
@@ -1234,28 +2143,43 @@ func ArchiveFiles(w http.ResponseWriter, r *http.Request) {
 	archiveName := fmt.Sprintf("archived_%d.tar.gz", randomNum)
 	archivePath := filepath.Join(fullPath, archiveName)
 
+	// Run archive creation in the background so large selections don't time out the request
+	job := services.NewJob(server.ID, "archive", fileNames)
+	job.SetResult("archive", archiveName)
+	job.Start(func(job *services.Job) error {
+		return createTarGzArchive(archivePath, fullPath, fileNames, job)
+	})
+
+	// Success response
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Archive creation started: %s", archiveName),
+		"archive": archiveName,
+		"count":   len(fileNames),
+		"job_id":  job.ID,
+	})
+}
+
+// createTarGzArchive writes the named entries of baseDir into a new tar.gz archive
+func createTarGzArchive(archivePath, baseDir string, fileNames []string, job *services.Job) error {
+	// Compute the total size up front so progress can be reported
+	for _, fileName := range fileNames {
+		job.AddTotal(pathSize(filepath.Join(baseDir, fileName)))
+	}
+
 	// Create archive file
 	archiveFile, err := os.Create(archivePath)
 	if err != nil {
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Failed to create archive file",
-		})
-		return
+		return fmt.Errorf("failed to create archive file: %w", err)
 	}
-	defer archiveFile.Close()
 
-	// Create gzip writer
+	// Create gzip and tar writers
 	gzipWriter := gzip.NewWriter(archiveFile)
-	defer gzipWriter.Close()
-
-	// Create tar writer
 	tarWriter := tar.NewWriter(gzipWriter)
-	defer tarWriter.Close()
 
 	// Add each file/folder to archive
 	for _, fileName := range fileNames {
-		sourcePath := filepath.Join(fullPath, fileName)
+		sourcePath := filepath.Join(baseDir, fileName)
 
 		// Check if file exists
 		info, err := os.Stat(sourcePath)
@@ -1264,51 +2188,104 @@ func ArchiveFiles(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Add to archive (recursively if directory)
-		if err := addToArchive(tarWriter, sourcePath, fileName, info); err != nil {
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"error":   fmt.Sprintf("Failed to add %s to archive", fileName),
-			})
-			return
+		if err = addToArchive(tarWriter, sourcePath, fileName, info, job, services.NewWalkGuard(sourcePath)); err != nil {
+			err = fmt.Errorf("failed to add %s to archive: %w", fileName, err)
+			break
 		}
 	}
 
-	// Success response
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"message": fmt.Sprintf("Successfully created archive: %s", archiveName),
-		"archive": archiveName,
-		"count":   len(fileNames),
-	})
-}
+	// Close writers in order, keeping the first error
+	if closeErr := tarWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := gzipWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := archiveFile.Close(); err == nil {
+		err = closeErr
+	}
 
-// addToArchive recursively adds files/directories to tar archive
-func addToArchive(tarWriter *tar.Writer, sourcePath string, nameInArchive string, info os.FileInfo) error {
-	// Create tar header
-	header, err := tar.FileInfoHeader(info, "")
+	// Don't leave a partial archive behind
 	if err != nil {
+		os.Remove(archivePath)
 		return err
 	}
 
-	// Set name in archive
-	header.Name = nameInArchive
-
-	// Write header
-	if err := tarWriter.WriteHeader(header); err != nil {
-		return err
-	}
+	return nil
+}
 
-	// If it's a file, write contents
-	if !info.IsDir() {
-		file, err := os.Open(sourcePath)
+// pathSize returns the total size of a file or all regular files under a directory
+func pathSize(path string) int64 {
+	var total int64
+	filepath.Walk(path, func(entryPath string, info os.FileInfo, err error) error {
 		if err != nil {
-			return err
+			return nil
+		}
+		if info.IsDir() && entryPath != path && services.IsBackupDir(entryPath) {
+			return filepath.SkipDir
+		}
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// addToArchive recursively adds files/directories to tar archive. guard stops the recursion on
+// loops and overly deep trees.
+func addToArchive(tarWriter *tar.Writer, sourcePath string, nameInArchive string, info os.FileInfo, job *services.Job, guard *services.WalkGuard) error {
+	if info.IsDir() {
+		if err := guard.EnterDir(sourcePath, info); err != nil {
+			return err
+		}
+	}
+
+	// Symlinks are archived as links rather than followed
+	link := ""
+	if info.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(sourcePath); err != nil {
+			return err
+		}
+	}
+
+	// Create tar header
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+
+	// Set name in archive
+	header.Name = nameInArchive
+
+	// Write header
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+
+	// Links and special files have no contents to write
+	if !info.IsDir() && !info.Mode().IsRegular() {
+		return nil
+	}
+
+	// If it's a file, write contents
+	if !info.IsDir() {
+		job.SetCurrentFile(nameInArchive)
+
+		unlock := services.LockFileShared(sourcePath)
+		defer unlock()
+
+		file, err := os.Open(sourcePath)
+		if err != nil {
+			return err
 		}
 		defer file.Close()
 
-		if _, err := io.Copy(tarWriter, file); err != nil {
+		if _, err := io.Copy(job.TrackWriter(tarWriter), file); err != nil {
 			return err
 		}
+		job.FileDone()
 		return nil
 	}
 
@@ -1320,13 +2297,16 @@ func addToArchive(tarWriter *tar.Writer, sourcePath string, nameInArchive string
 
 	for _, entry := range entries {
 		entryPath := filepath.Join(sourcePath, entry.Name())
+		if entry.IsDir() && services.IsBackupDir(entryPath) {
+			continue
+		}
 		entryInfo, err := entry.Info()
 		if err != nil {
 			continue
 		}
 
 		entryNameInArchive := filepath.Join(nameInArchive, entry.Name())
-		if err := addToArchive(tarWriter, entryPath, entryNameInArchive, entryInfo); err != nil {
+		if err := addToArchive(tarWriter, entryPath, entryNameInArchive, entryInfo, job, guard); err != nil {
 			return err
 		}
 	}
@@ -1334,7 +2314,130 @@ func addToArchive(tarWriter *tar.Writer, sourcePath string, nameInArchive string
 	return nil
 }
 
-// UnarchiveFile extracts an archive (tar.gz, zip, etc.) to the current directory
+// extractReport collects the outcome of an extraction. In best-effort mode, individual entry
+// failures are recorded and skipped instead of aborting the whole extraction.
+type extractReport struct {
+	BestEffort bool
+	FileMode   os.FileMode    // Mode for extracted files, 0 keeps the archive's modes
+	DirMode    os.FileMode    // Mode for extracted directories
+	Server     *models.Server // Server whose protected files may not be overwritten
+	Override   bool           // Overwrite protected files anyway
+	Overridden []string       // Protected files overwritten with the override
+	MaxBytes   int64          // Total size the extraction may write, 0 = unlimited
+	Extracted  int
+	Failures   []map[string]string
+	written    int64
+}
+
+// errExtractTooLarge is returned when an extraction writes past the configured size limit
+var errExtractTooLarge = errors.New("extracted files exceed the size limit")
+
+// copyEntry writes an entry's contents to out, refusing to go past the extraction's size
+// limit or into the reserved free space. Archives can unpack to far more than their own
+// size, so both are checked as the output grows rather than up front.
+func (rep *extractReport) copyEntry(out io.Writer, src io.Reader, target string) error {
+	out = services.NewDiskReserveWriter(out, target)
+	if rep == nil || rep.MaxBytes <= 0 {
+		_, err := io.Copy(out, src)
+		return err
+	}
+
+	// Read one byte past the remaining budget to tell "exactly the limit" from "too large"
+	written, err := io.Copy(out, io.LimitReader(src, rep.MaxBytes-rep.written+1))
+	rep.written += written
+	if err == nil && rep.written > rep.MaxBytes {
+		err = errExtractTooLarge
+	}
+	return err
+}
+
+// checkOverwrite returns an error when extracting to target would overwrite a protected file
+// without the override
+func (rep *extractReport) checkOverwrite(target string) error {
+	if rep == nil || rep.Server == nil {
+		return nil
+	}
+	if _, err := os.Lstat(target); err != nil {
+		return nil
+	}
+	pattern, ok := rep.Server.ProtectedPattern(target)
+	if !ok {
+		return nil
+	}
+	if !rep.Override {
+		return fmt.Errorf("%s is protected (matches %q)", filepath.Base(target), pattern)
+	}
+	rep.Overridden = append(rep.Overridden, target)
+	return nil
+}
+
+// entryFailed records a failed entry in best-effort mode, or returns err to abort in fail-fast
+// mode. Running out of size budget or free space aborts either way, since every later entry
+// would fail too.
+func (rep *extractReport) entryFailed(name string, err error) error {
+	if rep == nil || !rep.BestEffort || errors.Is(err, errExtractTooLarge) || errors.Is(err, services.ErrDiskReserve) {
+		return err
+	}
+	rep.Failures = append(rep.Failures, map[string]string{
+		"entry": name,
+		"error": err.Error(),
+	})
+	return nil
+}
+
+// fileMode returns the mode for an extracted file. A configured mode replaces the archive's
+// mode but keeps its executable bits, so scripts stay runnable.
+func (rep *extractReport) fileMode(archived os.FileMode) os.FileMode {
+	if rep == nil || rep.FileMode == 0 {
+		return archived.Perm()
+	}
+	return rep.FileMode | archived.Perm()&0111
+}
+
+// extractFileMode returns the mode extracted files are forced to, or 0 to keep the archive's
+// modes when the server has no file mode configured
+func extractFileMode(server *models.Server) os.FileMode {
+	if server.FileMode == "" {
+		return 0
+	}
+	return server.FilePerm()
+}
+
+// dirMode returns the mode for an extracted directory
+func (rep *extractReport) dirMode() os.FileMode {
+	if rep == nil || rep.DirMode == 0 {
+		return models.DefaultDirMode
+	}
+	return rep.DirMode
+}
+
+// entryDone counts a successfully extracted entry
+func (rep *extractReport) entryDone() {
+	if rep != nil {
+		rep.Extracted++
+	}
+}
+
+// archiveExtractor returns the extractor for a supported archive name, or nil if unsupported
+func archiveExtractor(fileName string) func(archivePath, destPath string, job *services.Job, report *extractReport) error {
+	switch {
+	case strings.HasSuffix(fileName, ".tar.gz") || strings.HasSuffix(fileName, ".tgz"):
+		return extractTarGz
+	case strings.HasSuffix(fileName, ".tar.bz2") || strings.HasSuffix(fileName, ".tbz2"):
+		return extractTarBz2
+	case strings.HasSuffix(fileName, ".tar.xz") || strings.HasSuffix(fileName, ".txz"):
+		return extractTarXz
+	case strings.HasSuffix(fileName, ".tar"):
+		return extractTar
+	case strings.HasSuffix(fileName, ".zip"):
+		return extractZip
+	case strings.HasSuffix(fileName, ".gz"):
+		return extractGz
+	}
+	return nil
+}
+
+// UnarchiveFile extracts one or more archives (tar.gz, zip, etc.) to the current directory as a background job
 func UnarchiveFile(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -1348,6 +2451,7 @@ func UnarchiveFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -1357,17 +2461,44 @@ func UnarchiveFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid form data",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
 
 	currentPath := r.FormValue("path")
-	fileName := r.FormValue("file")
 
-	if fileName == "" {
+	// Best-effort mode skips entries that fail instead of stopping at the first error
+	bestEffortStr := r.FormValue("best_effort")
+	bestEffort := bestEffortStr == "true" || bestEffortStr == "1"
+
+	// Archives are kept by default; delete_after removes each one once it extracted cleanly
+	deleteAfterStr := r.FormValue("delete_after")
+	deleteAfter := deleteAfterStr == "true" || deleteAfterStr == "1"
+
+	// Protected files in the way are only overwritten with an explicit override
+	override := protectionOverride(r)
+
+	// Accept a single "file" or a JSON "files" list for multi-select extraction
+	var fileNames []string
+	if filesJSON := r.FormValue("files"); filesJSON != "" {
+		if err := json.Unmarshal([]byte(filesJSON), &fileNames); err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid files data",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+	} else if fileName := r.FormValue("file"); fileName != "" {
+		fileNames = []string{fileName}
+	}
+
+	if len(fileNames) == 0 {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "No file specified",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -1375,10 +2506,10 @@ func UnarchiveFile(w http.ResponseWriter, r *http.Request) {
 	// Build full path
 	var fullPath string
 	if currentPath == "/" || currentPath == "" {
-		fullPath = server.FolderPath
+		fullPath = server.FileRootPath()
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		fullPath = filepath.Join(server.FolderPath, relativePath)
+		fullPath = filepath.Join(server.FileRootPath(), relativePath)
 	}
 
 	// Validate path is within server directory
@@ -1386,128 +2517,223 @@ func UnarchiveFile(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid path",
+			"code":    ErrCodePathOutsideRoot,
 		})
 		return
 	}
 
-	archivePath := filepath.Join(fullPath, fileName)
+	// Validate every archive before queueing anything
+	for _, fileName := range fileNames {
+		archivePath := filepath.Join(fullPath, fileName)
+
+		if !strings.HasPrefix(archivePath, server.FolderPath) {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid path",
+				"code":    ErrCodePathOutsideRoot,
+			})
+			return
+		}
 
-	// Check if archive exists
-	if _, err := os.Stat(archivePath); os.IsNotExist(err) {
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Archive file not found",
-		})
-		return
+		if _, err := os.Stat(archivePath); os.IsNotExist(err) {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("Archive file not found: %s", fileName),
+				"code":    ErrCodeFileNotFound,
+			})
+			return
+		}
+
+		if archiveExtractor(fileName) == nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("Unsupported archive format: %s (supported: .tar.gz, .tgz, .tar.bz2, .tar.xz, .tar, .zip, .gz)", fileName),
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
 	}
 
-	// Detect archive type and extract
-	var extractErr error
-	if strings.HasSuffix(fileName, ".tar.gz") || strings.HasSuffix(fileName, ".tgz") {
-		extractErr = extractTarGz(archivePath, fullPath)
-	} else if strings.HasSuffix(fileName, ".tar") {
-		extractErr = extractTar(archivePath, fullPath)
-	} else if strings.HasSuffix(fileName, ".zip") {
-		extractErr = extractZip(archivePath, fullPath)
-	} else if strings.HasSuffix(fileName, ".gz") {
-		extractErr = extractGz(archivePath, fullPath)
-	} else {
+	// Queue the archives for sequential extraction in the background
+	job := services.NewJob(server.ID, "extract", fileNames)
+	job.Start(func(job *services.Job) error {
+		for _, fileName := range fileNames {
+			if info, err := os.Stat(filepath.Join(fullPath, fileName)); err == nil {
+				job.AddTotal(info.Size())
+			}
+		}
+
+		report := &extractReport{
+			BestEffort: bestEffort,
+			FileMode:   extractFileMode(server),
+			DirMode:    server.DirPerm(),
+			Server:     server,
+			Override:   override,
+			MaxBytes:   config.GetExtractMaxBytes(),
+			Failures:   make([]map[string]string, 0),
+		}
+		extracted := make([]string, 0, len(fileNames))
+		deleted := make([]string, 0)
+		defer func() {
+			for _, path := range report.Overridden {
+				auditProtectionOverride(r, server, "extract", path)
+			}
+			job.SetResult("extracted", extracted)
+			job.SetResult("extracted_entries", report.Extracted)
+			job.SetResult("failed_entries", report.Failures)
+			job.SetResult("deleted_archives", deleted)
+		}()
+
+		for _, fileName := range fileNames {
+			ok, removed, err := extractArchive(filepath.Join(fullPath, fileName), fullPath, job, report, deleteAfter)
+			if err != nil {
+				return err
+			}
+			if ok {
+				extracted = append(extracted, fileName)
+			}
+			if removed {
+				deleted = append(deleted, fileName)
+			}
+		}
+		return nil
+	})
+
+	// Success response
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":      true,
+		"message":      fmt.Sprintf("Extraction started for %d archive(s)", len(fileNames)),
+		"job_id":       job.ID,
+		"delete_after": deleteAfter,
+	})
+}
+
+// extractArchive extracts one archive into destPath, reporting whether it was extracted and
+// whether it was deleted afterwards. In best-effort mode an unreadable archive is recorded in
+// the report and skipped rather than returned as an error. With deleteAfter the archive is
+// removed only when none of its entries were skipped, so nothing is lost.
+func extractArchive(archivePath, destPath string, job *services.Job, report *extractReport, deleteAfter bool) (bool, bool, error) {
+	fileName := filepath.Base(archivePath)
+	failuresBefore := len(report.Failures)
+
+	extract := archiveExtractor(fileName)
+	if extract == nil {
+		return false, false, fmt.Errorf("unsupported archive format: %s", fileName)
+	}
+	if err := extract(archivePath, destPath, job, report); err != nil {
+		if err := report.entryFailed(fileName, err); err != nil {
+			return false, false, fmt.Errorf("failed to extract %s: %w", fileName, err)
+		}
+		return false, false, nil
+	}
+
+	if !deleteAfter || len(report.Failures) != failuresBefore {
+		return true, false, nil
+	}
+	if err := os.Remove(archivePath); err != nil {
+		log.Printf("⚠️  Failed to delete extracted archive %s: %v", archivePath, err)
+		return true, false, nil
+	}
+	return true, true, nil
+}
+
+// GetFileJob reports the status and progress of a background job (file operations, backups)
+func GetFileJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	jobID := vars["id"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   "Unsupported archive format (supported: .tar.gz, .tgz, .tar, .zip, .gz)",
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
 
-	if extractErr != nil {
+	// Get job and verify it belongs to this server
+	job, exists := services.GetJob(jobID)
+	if !exists || job.ServerID != server.ID {
+		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
-			"error":   fmt.Sprintf("Failed to extract archive: %v", extractErr),
+			"error":   "Job not found",
+			"code":    ErrCodeNotFound,
 		})
 		return
 	}
 
-	// Success response
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
-		"message": fmt.Sprintf("Successfully extracted: %s", fileName),
+		"job":     job.Snapshot(),
 	})
 }
 
 // extractTarGz extracts a .tar.gz archive
-func extractTarGz(archivePath, destPath string) error {
+func extractTarGz(archivePath, destPath string, job *services.Job, report *extractReport) error {
 	file, err := os.Open(archivePath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	gzipReader, err := gzip.NewReader(file)
+	gzipReader, err := gzip.NewReader(job.TrackReader(file))
 	if err != nil {
 		return err
 	}
 	defer gzipReader.Close()
 
-	tarReader := tar.NewReader(gzipReader)
-
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		target := filepath.Join(destPath, header.Name)
-
-		// Security check: prevent path traversal
-		if !strings.HasPrefix(target, filepath.Clean(destPath)+string(os.PathSeparator)) {
-			continue
-		}
+	return extractTarStream(tar.NewReader(gzipReader), destPath, job, report)
+}
 
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(target, 0755); err != nil {
-				return err
-			}
-		case tar.TypeReg:
-			// Create parent directory if needed
-			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
-				return err
-			}
+// extractTarBz2 extracts a .tar.bz2 archive
+func extractTarBz2(archivePath, destPath string, job *services.Job, report *extractReport) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
 
-			outFile, err := os.Create(target)
-			if err != nil {
-				return err
-			}
+	return extractTarStream(tar.NewReader(bzip2.NewReader(job.TrackReader(file))), destPath, job, report)
+}
 
-			if _, err := io.Copy(outFile, tarReader); err != nil {
-				outFile.Close()
-				return err
-			}
-			outFile.Close()
+// extractTarXz extracts a .tar.xz archive
+func extractTarXz(archivePath, destPath string, job *services.Job, report *extractReport) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
 
-			// Set file permissions
-			if err := os.Chmod(target, os.FileMode(header.Mode)); err != nil {
-				return err
-			}
-		}
+	xzReader, err := xz.NewReader(job.TrackReader(file))
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return extractTarStream(tar.NewReader(xzReader), destPath, job, report)
 }
 
 // extractTar extracts a .tar archive
-func extractTar(archivePath, destPath string) error {
+func extractTar(archivePath, destPath string, job *services.Job, report *extractReport) error {
 	file, err := os.Open(archivePath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	tarReader := tar.NewReader(file)
+	return extractTarStream(tar.NewReader(job.TrackReader(file)), destPath, job, report)
+}
 
+// extractTarStream extracts every entry of a tar stream into destPath.
+// A broken stream always aborts, since later entries can't be located past it.
+func extractTarStream(tarReader *tar.Reader, destPath string, job *services.Job, report *extractReport) error {
 	for {
 		header, err := tarReader.Next()
 		if err == io.EOF {
@@ -1524,91 +2750,52 @@ func extractTar(archivePath, destPath string) error {
 			continue
 		}
 
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(target, 0755); err != nil {
-				return err
-			}
-		case tar.TypeReg:
-			// Create parent directory if needed
-			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
-				return err
-			}
-
-			outFile, err := os.Create(target)
-			if err != nil {
-				return err
-			}
-
-			if _, err := io.Copy(outFile, tarReader); err != nil {
-				outFile.Close()
-				return err
-			}
-			outFile.Close()
+		job.SetCurrentFile(header.Name)
 
-			// Set file permissions
-			if err := os.Chmod(target, os.FileMode(header.Mode)); err != nil {
+		if err := extractTarEntry(tarReader, header, target, report); err != nil {
+			if err := report.entryFailed(header.Name, err); err != nil {
 				return err
 			}
+			continue
 		}
+
+		report.entryDone()
+		job.FileDone()
 	}
 
 	return nil
 }
 
-// extractZip extracts a .zip archive
-func extractZip(archivePath, destPath string) error {
-	zipReader, err := zip.OpenReader(archivePath)
-	if err != nil {
-		return err
-	}
-	defer zipReader.Close()
-
-	for _, file := range zipReader.File {
-		target := filepath.Join(destPath, file.Name)
-
-		// Security check: prevent path traversal
-		if !strings.HasPrefix(target, filepath.Clean(destPath)+string(os.PathSeparator)) {
-			continue
-		}
-
-		if file.FileInfo().IsDir() {
-			if err := os.MkdirAll(target, 0755); err != nil {
-				return err
-			}
-			continue
+// extractTarEntry writes a single tar entry to target
+func extractTarEntry(tarReader *tar.Reader, header *tar.Header, target string, report *extractReport) error {
+	switch header.Typeflag {
+	case tar.TypeDir:
+		if err := mkdirAllWithMode(target, report.dirMode()); err != nil {
+			return err
 		}
-
+	case tar.TypeReg:
 		// Create parent directory if needed
-		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		if err := mkdirAllWithMode(filepath.Dir(target), report.dirMode()); err != nil {
 			return err
 		}
-
-		// Open file in archive
-		srcFile, err := file.Open()
-		if err != nil {
+		if err := report.checkOverwrite(target); err != nil {
 			return err
 		}
 
-		// Create destination file
 		outFile, err := os.Create(target)
 		if err != nil {
-			srcFile.Close()
 			return err
 		}
 
-		// Copy contents
-		if _, err := io.Copy(outFile, srcFile); err != nil {
+		if err := report.copyEntry(outFile, tarReader, target); err != nil {
 			outFile.Close()
-			srcFile.Close()
+			os.Remove(target)
 			return err
 		}
-
 		outFile.Close()
-		srcFile.Close()
 
 		// Set file permissions
-		if err := os.Chmod(target, file.Mode()); err != nil {
+		if err := os.Chmod(target, report.fileMode(os.FileMode(header.Mode))); err != nil {
 			return err
 		}
 	}
@@ -1616,37 +2803,405 @@ func extractZip(archivePath, destPath string) error {
 	return nil
 }
 
-// extractGz extracts a .gz file (single file compression)
-func extractGz(archivePath, destPath string) error {
-	file, err := os.Open(archivePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	gzipReader, err := gzip.NewReader(file)
+// extractZip extracts a .zip archive
+func extractZip(archivePath, destPath string, job *services.Job, report *extractReport) error {
+	zipReader, err := zip.OpenReader(archivePath)
 	if err != nil {
 		return err
 	}
-	defer gzipReader.Close()
+	defer zipReader.Close()
 
-	// Output filename is the archive name without .gz extension
-	outputName := strings.TrimSuffix(filepath.Base(archivePath), ".gz")
-	outputPath := filepath.Join(destPath, outputName)
+	for _, file := range zipReader.File {
+		target := filepath.Join(destPath, file.Name)
 
-	outFile, err := os.Create(outputPath)
-	if err != nil {
+		// Security check: prevent path traversal
+		if !strings.HasPrefix(target, filepath.Clean(destPath)+string(os.PathSeparator)) {
+			continue
+		}
+
+		job.SetCurrentFile(file.Name)
+
+		// Zip entries are read through random access, so progress is counted per entry
+		err := extractZipEntry(file, target, report)
+		job.AddBytes(int64(file.CompressedSize64))
+		if err != nil {
+			if err := report.entryFailed(file.Name, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		report.entryDone()
+		job.FileDone()
+	}
+
+	return nil
+}
+
+// extractZipEntry writes a single zip entry to target
+func extractZipEntry(file *zip.File, target string, report *extractReport) error {
+	if file.FileInfo().IsDir() {
+		return mkdirAllWithMode(target, report.dirMode())
+	}
+
+	// Create parent directory if needed
+	if err := mkdirAllWithMode(filepath.Dir(target), report.dirMode()); err != nil {
+		return err
+	}
+
+	if err := report.checkOverwrite(target); err != nil {
+		return err
+	}
+
+	// Open file in archive
+	srcFile, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	// Create destination file
+	outFile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+
+	// Copy contents
+	if err := report.copyEntry(outFile, srcFile, target); err != nil {
+		outFile.Close()
+		os.Remove(target)
+		return err
+	}
+	outFile.Close()
+
+	// Set file permissions
+	return os.Chmod(target, report.fileMode(file.Mode()))
+}
+
+// extractGz extracts a .gz file (single file compression)
+func extractGz(archivePath, destPath string, job *services.Job, report *extractReport) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(job.TrackReader(file))
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	// Output filename is the archive name without .gz extension
+	outputName := strings.TrimSuffix(filepath.Base(archivePath), ".gz")
+	outputPath := filepath.Join(destPath, outputName)
+
+	job.SetCurrentFile(outputName)
+
+	if err := report.checkOverwrite(outputPath); err != nil {
+		return err
+	}
+	outFile, err := createFileWithMode(outputPath, report.fileMode(models.DefaultFileMode))
+	if err != nil {
 		return err
 	}
 	defer outFile.Close()
 
-	if _, err := io.Copy(outFile, gzipReader); err != nil {
+	if err := report.copyEntry(outFile, gzipReader, outputPath); err != nil {
+		os.Remove(outputPath)
 		return err
 	}
 
+	report.entryDone()
+	job.FileDone()
 	return nil
 }
 
+// compressedExtensions are formats CompressFile refuses, since gzipping them gains nothing
+var compressedExtensions = map[string]bool{
+	".gz":  true,
+	".tgz": true,
+	".bz2": true,
+	".xz":  true,
+	".txz": true,
+	".zip": true,
+	".jar": true,
+	".7z":  true,
+	".rar": true,
+	".zst": true,
+}
+
+// CompressFile gzips a single file in place as file.gz, optionally removing the original
+func CompressFile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid form data",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	currentPath := r.FormValue("path")
+	fileName := r.FormValue("file")
+	deleteOriginalStr := r.FormValue("delete_original")
+	deleteOriginal := deleteOriginalStr == "true" || deleteOriginalStr == "1"
+
+	if fileName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "No file specified",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Build full path
+	var fullPath string
+	if currentPath == "/" || currentPath == "" {
+		fullPath = filepath.Join(server.FileRootPath(), fileName)
+	} else {
+		relativePath := strings.TrimPrefix(currentPath, "/")
+		fullPath = filepath.Join(server.FileRootPath(), relativePath, fileName)
+	}
+
+	// Security check: ensure the path is within the server folder
+	cleanPath := filepath.Clean(fullPath)
+	if !strings.HasPrefix(cleanPath, server.FolderPath) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied: path outside server directory",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	info, err := os.Lstat(cleanPath)
+	if err != nil || !info.Mode().IsRegular() {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "File not found",
+			"code":    ErrCodeFileNotFound,
+		})
+		return
+	}
+
+	if compressedExtensions[strings.ToLower(filepath.Ext(cleanPath))] {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "File is already compressed",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	gzPath := cleanPath + ".gz"
+	if _, err := os.Lstat(gzPath); err == nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "File '" + filepath.Base(gzPath) + "' already exists",
+			"code":    ErrCodeConflict,
+		})
+		return
+	}
+
+	unlock := services.LockFileShared(cleanPath)
+	compressedSize, err := gzipFile(cleanPath, gzPath, info)
+	unlock()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to compress file: " + err.Error(),
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	deleted := false
+	if deleteOriginal {
+		if err := os.Remove(cleanPath); err != nil {
+			log.Printf("⚠️  Failed to remove '%s' after compressing: %v", cleanPath, err)
+		} else {
+			deleted = true
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":          true,
+		"message":          "File compressed successfully",
+		"filename":         filepath.Base(gzPath),
+		"original_size":    info.Size(),
+		"compressed_size":  compressedSize,
+		"deleted_original": deleted,
+	})
+}
+
+// gzipFile writes a gzip copy of src to dst, keeping the original's name, mode and modtime.
+// The copy is written under a temporary name and only renamed into place once complete.
+func gzipFile(src, dst string, info os.FileInfo) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	tmpPath := services.PartialPath(dst)
+	out, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return 0, err
+	}
+
+	gzipWriter := gzip.NewWriter(out)
+	gzipWriter.Name = filepath.Base(src)
+	gzipWriter.ModTime = info.ModTime()
+
+	_, err = io.Copy(gzipWriter, in)
+	if closeErr := gzipWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, dst)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+
+	os.Chtimes(dst, info.ModTime(), info.ModTime())
+
+	compressed, err := os.Stat(dst)
+	if err != nil {
+		return 0, err
+	}
+	return compressed.Size(), nil
+}
+
+// errGunzipTooLarge is returned when a gzip stream unpacks past the allowed size
+var errGunzipTooLarge = errors.New("decompressed file exceeds the size limit")
+
+// gunzipToFile decompresses a gzip stream into dst, refusing output larger than maxSize or
+// reaching into the reserved free space. The output is written under a temporary name and
+// only renamed into place once complete.
+func gunzipToFile(src io.Reader, dst string, mode os.FileMode, maxSize int64) (int64, error) {
+	gzipReader, err := gzip.NewReader(src)
+	if err != nil {
+		return 0, errors.New("not a valid gzip file")
+	}
+	defer gzipReader.Close()
+
+	tmpPath := services.PartialPath(dst)
+	out, err := createFileWithMode(tmpPath, mode)
+	if err != nil {
+		return 0, err
+	}
+
+	// Read one byte past the limit to tell "exactly maxSize" from "too large". The output can
+	// be far larger than the upload, so free space is checked as it grows.
+	written, err := io.Copy(services.NewDiskReserveWriter(out, dst), io.LimitReader(gzipReader, maxSize+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written > maxSize {
+		err = errGunzipTooLarge
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, dst)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	return written, nil
+}
+
+// textContentTypes maps extensions of common server files to their content types,
+// since sniffing can't recognize most plain-text config formats
+var textContentTypes = map[string]string{
+	".txt":        "text/plain; charset=utf-8",
+	".log":        "text/plain; charset=utf-8",
+	".properties": "text/plain; charset=utf-8",
+	".cfg":        "text/plain; charset=utf-8",
+	".conf":       "text/plain; charset=utf-8",
+	".ini":        "text/plain; charset=utf-8",
+	".toml":       "text/plain; charset=utf-8",
+	".sh":         "text/plain; charset=utf-8",
+	".bat":        "text/plain; charset=utf-8",
+	".yml":        "text/yaml; charset=utf-8",
+	".yaml":       "text/yaml; charset=utf-8",
+	".json":       "application/json",
+	".mcmeta":     "application/json",
+	".md":         "text/markdown; charset=utf-8",
+	".csv":        "text/csv; charset=utf-8",
+	".xml":        "text/xml; charset=utf-8",
+}
+
+// detectFileContentType combines extension-based mapping with content sniffing
+func detectFileContentType(fileName string, head []byte) string {
+	if contentType, ok := textContentTypes[strings.ToLower(filepath.Ext(fileName))]; ok {
+		return contentType
+	}
+
+	contentType := http.DetectContentType(head)
+
+	// Unrecognized content without NUL bytes is almost always text
+	if contentType == "application/octet-stream" && len(head) > 0 && !bytes.Contains(head, []byte{0}) {
+		// The sniffed head may end partway through a multi-byte character
+		text := head
+		for i := 0; i < utf8.UTFMax-1 && len(text) > 0 && !utf8.Valid(text); i++ {
+			text = text[:len(text)-1]
+		}
+		if utf8.Valid(text) {
+			return "text/plain; charset=utf-8"
+		}
+	}
+
+	return contentType
+}
+
+// isViewableContentType reports whether a browser can safely display the content inline.
+// HTML, SVG and other XML types are excluded since they could run scripts on the panel's origin.
+func isViewableContentType(contentType string) bool {
+	switch {
+	case strings.HasPrefix(contentType, "text/html"), strings.HasPrefix(contentType, "image/svg"),
+		strings.Contains(contentType, "xml"):
+		return false
+	case strings.HasPrefix(contentType, "text/"), strings.HasPrefix(contentType, "image/"):
+		return true
+	case strings.HasPrefix(contentType, "application/json"), strings.HasPrefix(contentType, "application/pdf"):
+		return true
+	}
+	return false
+}
+
 // DownloadFile streams a file to the client for download
 func DownloadFile(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -1669,19 +3224,26 @@ func DownloadFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Resolve against the server files or a temporary backup mount
+	fileRoot, boundary, ok := fileRootForRequest(r, server)
+	if !ok {
+		http.Error(w, "Backup mount not found or expired", http.StatusNotFound)
+		return
+	}
+
 	// Build full path
 	var fullPath string
 	if currentPath == "/" || currentPath == "" {
-		fullPath = server.FolderPath
+		fullPath = fileRoot
 	} else {
 		relativePath := strings.TrimPrefix(currentPath, "/")
-		fullPath = filepath.Join(server.FolderPath, relativePath)
+		fullPath = filepath.Join(fileRoot, relativePath)
 	}
 
 	filePath := filepath.Join(fullPath, fileName)
 
 	// Validate path is within server directory (security check)
-	if !strings.HasPrefix(filePath, server.FolderPath) {
+	if !strings.HasPrefix(filePath, boundary) {
 		http.Error(w, "Invalid file path", http.StatusForbidden)
 		return
 	}
@@ -1703,8 +3265,9 @@ func DownloadFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Open file
-	file, err := os.Open(filePath)
+	// Saves replace the file rather than rewrite it, so once open the download can't be torn
+	// and slow clients don't hold saves back
+	file, err := services.OpenFileShared(filePath)
 	if err != nil {
 		http.Error(w, "Failed to open file", http.StatusInternalServerError)
 		return
@@ -1713,26 +3276,464 @@ func DownloadFile(w http.ResponseWriter, r *http.Request) {
 
 	// Detect content type
 	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
+	n, err := file.Read(buffer)
 	if err != nil && err != io.EOF {
 		http.Error(w, "Failed to read file", http.StatusInternalServerError)
 		return
 	}
-	contentType := http.DetectContentType(buffer)
+	contentType := detectFileContentType(fileName, buffer[:n])
 
 	// Reset file pointer to beginning
 	file.Seek(0, 0)
 
+	// Viewable types can be shown in the browser when requested, everything else is downloaded
+	disposition := "attachment"
+	if r.URL.Query().Get("inline") == "true" && isViewableContentType(contentType) {
+		disposition = "inline"
+		// Whatever slips past the type check still can't script the panel
+		w.Header().Set("Content-Security-Policy", "sandbox")
+	}
+
 	// Set headers
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=\"%s\"", disposition, fileName))
 	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.Header().Set("Content-Length", fmt.Sprintf("%d", fileInfo.Size()))
 
 	// Stream file to client
-	_, err = io.Copy(w, file)
+	_, err = copyDownload(w, file)
 	if err != nil {
 		// Can't send error response here as headers are already sent
 		// Log error instead
 		fmt.Printf("Error streaming file: %v\n", err)
 	}
-}
\ No newline at end of file
+}
+
+// DownloadSelectedFiles streams the selected files and folders as a zip built on the fly,
+// without writing an archive to disk
+func DownloadSelectedFiles(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	currentPath := r.FormValue("path")
+	filesJSON := r.FormValue("files")
+
+	// Parse files array
+	var fileNames []string
+	if err := json.Unmarshal([]byte(filesJSON), &fileNames); err != nil {
+		http.Error(w, "Invalid files data", http.StatusBadRequest)
+		return
+	}
+
+	if len(fileNames) == 0 {
+		http.Error(w, "No files selected", http.StatusBadRequest)
+		return
+	}
+
+	// Build full path
+	var fullPath string
+	if currentPath == "/" || currentPath == "" {
+		fullPath = server.FileRootPath()
+	} else {
+		relativePath := strings.TrimPrefix(currentPath, "/")
+		fullPath = filepath.Join(server.FileRootPath(), relativePath)
+	}
+
+	// Validate every item before streaming, errors can't be reported once the zip has started
+	for _, fileName := range fileNames {
+		itemPath := filepath.Clean(filepath.Join(fullPath, fileName))
+		if !strings.HasPrefix(itemPath, server.FolderPath) || itemPath == filepath.Clean(server.FolderPath) {
+			http.Error(w, fmt.Sprintf("Invalid file path: %s", fileName), http.StatusForbidden)
+			return
+		}
+		if _, err := os.Lstat(itemPath); err != nil {
+			http.Error(w, fmt.Sprintf("File not found: %s", fileName), http.StatusNotFound)
+			return
+		}
+	}
+
+	archiveName := fmt.Sprintf("%s_%s.zip", server.Name, time.Now().Format("20060102_150405"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", archiveName))
+	w.Header().Set("Content-Type", "application/zip")
+
+	zipWriter := zip.NewWriter(w)
+	for _, fileName := range fileNames {
+		itemPath := filepath.Clean(filepath.Join(fullPath, fileName))
+		if err := addToZipStream(r, zipWriter, itemPath, filepath.Base(itemPath)); err != nil {
+			// Headers are already sent, so the client gets a truncated archive
+			log.Printf("❌ Failed to stream selected files for %s: %v", server.Name, err)
+			return
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		log.Printf("❌ Failed to finish zip stream for %s: %v", server.Name, err)
+	}
+}
+
+// ExportTarGz streams the server folder, or the folder given as "path", to the client as a
+// tar.gz without writing anything to disk. Backup folders are left out like in backups.
+func ExportTarGz(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	// Build full path
+	currentPath := r.URL.Query().Get("path")
+	var fullPath string
+	if currentPath == "/" || currentPath == "" {
+		fullPath = server.FileRootPath()
+	} else {
+		relativePath := strings.TrimPrefix(currentPath, "/")
+		fullPath = filepath.Join(server.FileRootPath(), relativePath)
+	}
+
+	// Security check: ensure the path is within the server folder
+	cleanPath := filepath.Clean(fullPath)
+	if !strings.HasPrefix(cleanPath, server.FolderPath) {
+		http.Error(w, "Access denied: path outside server directory", http.StatusForbidden)
+		return
+	}
+
+	info, err := os.Stat(cleanPath)
+	if err != nil {
+		http.Error(w, "Folder not found", http.StatusNotFound)
+		return
+	}
+	if !info.IsDir() {
+		http.Error(w, "Path is not a directory", http.StatusBadRequest)
+		return
+	}
+
+	// Entries go under a folder named after what was exported, so the archive unpacks cleanly
+	rootFolder := filepath.Base(cleanPath)
+	if cleanPath == filepath.Clean(server.FolderPath) {
+		rootFolder = server.Name
+	}
+
+	archiveName := fmt.Sprintf("%s_%s.tar.gz", rootFolder, time.Now().Format("20060102_150405"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", archiveName))
+	w.Header().Set("Content-Type", "application/gzip")
+
+	if err := services.StreamTarGz(r.Context(), w, cleanPath, rootFolder); err != nil {
+		// Headers are already sent, so the client gets a truncated archive
+		if r.Context().Err() != nil {
+			log.Printf("🔌 Export of %s for server '%s' stopped: client disconnected", cleanPath, server.Name)
+		} else {
+			log.Printf("❌ Failed to export %s for server '%s': %v", cleanPath, server.Name, err)
+		}
+	}
+}
+
+// addToZipStream adds a file or directory tree to a streaming zip. Symlinks and special
+// files are skipped so nothing outside the server folder can be pulled in.
+func addToZipStream(r *http.Request, zipWriter *zip.Writer, sourcePath, nameInArchive string) error {
+	guard := services.NewWalkGuard(sourcePath)
+	return filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := r.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		if info.IsDir() && path != sourcePath && services.IsBackupDir(path) {
+			return filepath.SkipDir
+		}
+		if info.IsDir() {
+			if err := guard.EnterDir(path, info); err != nil {
+				return err
+			}
+		}
+
+		relPath, err := filepath.Rel(sourcePath, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(nameInArchive, relPath))
+		if info.IsDir() {
+			header.Name += "/"
+			_, err := zipWriter.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+
+		entryWriter, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		file, err := services.OpenFileShared(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(entryWriter, file)
+		return err
+	})
+}
+
+// GetFileThumbnail serves a small cached thumbnail for an image file
+func GetFileThumbnail(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	// Get file path from query parameter
+	currentPath := r.URL.Query().Get("path")
+	fileName := r.URL.Query().Get("file")
+
+	if fileName == "" {
+		http.Error(w, "No file specified", http.StatusBadRequest)
+		return
+	}
+
+	// Build full path
+	var fullPath string
+	if currentPath == "/" || currentPath == "" {
+		fullPath = filepath.Join(server.FileRootPath(), fileName)
+	} else {
+		relativePath := strings.TrimPrefix(currentPath, "/")
+		fullPath = filepath.Join(server.FileRootPath(), relativePath, fileName)
+	}
+
+	// Validate path is within server directory (security check)
+	cleanPath := filepath.Clean(fullPath)
+	if !strings.HasPrefix(cleanPath, server.FolderPath) {
+		http.Error(w, "Invalid file path", http.StatusForbidden)
+		return
+	}
+
+	// Skip non-image files
+	if !services.IsThumbnailable(fileName) {
+		http.Error(w, "File is not an image", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	thumbPath, contentType, err := services.GetThumbnail(cleanPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "File not found", http.StatusNotFound)
+		} else if err == services.ErrNotAnImage {
+			http.Error(w, "File is not an image", http.StatusUnsupportedMediaType)
+		} else if errors.Is(err, services.ErrImageTooLarge) {
+			http.Error(w, "Image is too large for a thumbnail", http.StatusUnprocessableEntity)
+		} else {
+			http.Error(w, "Failed to generate thumbnail", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// The thumbnail URL changes content whenever the image does, so keep browser caching short
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	http.ServeFile(w, r, thumbPath)
+}
+
+// Hex dump limits
+const (
+	hexDumpDefaultLength = 512
+	hexDumpMaxLength     = 64 * 1024
+)
+
+// HexDumpFile returns a read-only hex+ASCII dump of a byte range of a file
+func HexDumpFile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Get file path from query parameter
+	currentPath := r.URL.Query().Get("path")
+	fileName := r.URL.Query().Get("file")
+
+	if fileName == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "No file specified",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Parse byte range, clamping the length to the cap
+	offset := int64(0)
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		offset, err = strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil || offset < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid offset",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+	}
+
+	length := hexDumpDefaultLength
+	if lengthStr := r.URL.Query().Get("length"); lengthStr != "" {
+		length, err = strconv.Atoi(lengthStr)
+		if err != nil || length <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid length",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+	}
+	if length > hexDumpMaxLength {
+		length = hexDumpMaxLength
+	}
+
+	// Build full path
+	var fullPath string
+	if currentPath == "/" || currentPath == "" {
+		fullPath = filepath.Join(server.FileRootPath(), fileName)
+	} else {
+		relativePath := strings.TrimPrefix(currentPath, "/")
+		fullPath = filepath.Join(server.FileRootPath(), relativePath, fileName)
+	}
+
+	// Validate path is within server directory (security check)
+	cleanPath := filepath.Clean(fullPath)
+	if !strings.HasPrefix(cleanPath, server.FolderPath) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid file path",
+			"code":    ErrCodePathOutsideRoot,
+		})
+		return
+	}
+
+	file, err := os.Open(cleanPath)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "File not found",
+			"code":    ErrCodeFileNotFound,
+		})
+		return
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil || fileInfo.IsDir() {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Path is not a file",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	buffer := make([]byte, length)
+	n, err := file.ReadAt(buffer, offset)
+	if err != nil && err != io.EOF {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to read file",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"offset":    offset,
+		"length":    n,
+		"file_size": fileInfo.Size(),
+		"eof":       offset+int64(n) >= fileInfo.Size(),
+		"dump":      formatHexDump(buffer[:n], offset),
+	})
+}
+
+// formatHexDump formats bytes as 16-byte rows of "offset  hex  |ascii|", numbering rows from baseOffset
+func formatHexDump(data []byte, baseOffset int64) string {
+	var sb strings.Builder
+	for rowStart := 0; rowStart < len(data); rowStart += 16 {
+		row := data[rowStart:]
+		if len(row) > 16 {
+			row = row[:16]
+		}
+
+		fmt.Fprintf(&sb, "%08x  ", baseOffset+int64(rowStart))
+		for i := 0; i < 16; i++ {
+			if i < len(row) {
+				fmt.Fprintf(&sb, "%02x ", row[i])
+			} else {
+				sb.WriteString("   ")
+			}
+			if i == 7 {
+				sb.WriteByte(' ')
+			}
+		}
+
+		sb.WriteString(" |")
+		for _, b := range row {
+			if b >= 0x20 && b < 0x7f {
+				sb.WriteByte(b)
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteString("|\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"seiapanel/config"
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// useTestConfig swaps in cfg for one test. include_backup_dirs keeps walks from looking up
+// backup folders in the database, which tests here don't open.
+func useTestConfig(t testing.TB, cfg *config.Config) {
+	t.Helper()
+
+	previous := config.AppConfig
+	config.AppConfig = cfg
+	t.Cleanup(func() { config.AppConfig = previous })
+}
+
+// TestCopyDirSymlinkLoop checks that a symlink back to the copied folder is recreated as a
+// link instead of being followed
+func TestCopyDirSymlinkLoop(t *testing.T) {
+	useTestConfig(t, &config.Config{IncludeBackupDirs: true})
+
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "world"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(src, filepath.Join(src, "world", "loop")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "copy")
+	done := make(chan error, 1)
+	go func() { done <- copyDir(src, dst) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("copyDir: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("copyDir through a symlink loop did not finish")
+	}
+
+	link, err := os.Readlink(filepath.Join(dst, "world", "loop"))
+	if err != nil {
+		t.Fatalf("copy of the loop is not a symlink: %v", err)
+	}
+	if link != src {
+		t.Errorf("copied symlink points to %q, want %q", link, src)
+	}
+}
+
+// TestCopyDirTooDeep checks that copying a tree deeper than MaxWalkDepth stops with
+// ErrWalkTooDeep
+func TestCopyDirTooDeep(t *testing.T) {
+	useTestConfig(t, &config.Config{IncludeBackupDirs: true})
+
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, strings.Repeat("d/", services.MaxWalkDepth+1)), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := copyDir(src, filepath.Join(t.TempDir(), "copy")); !errors.Is(err, services.ErrWalkTooDeep) {
+		t.Errorf("copyDir error = %v, want ErrWalkTooDeep", err)
+	}
+}
+
+// writeBombArchive writes an archive at path holding one entry of size zero bytes, which
+// compresses to a tiny fraction of its size
+func writeBombArchive(t *testing.T, path string, size int64) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zeros := io.LimitReader(zeroReader{}, size)
+
+	if filepath.Ext(path) == ".zip" {
+		zipWriter := zip.NewWriter(file)
+		entry, err := zipWriter.Create("bomb.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(entry, zeros); err != nil {
+			t.Fatal(err)
+		}
+		if err := zipWriter.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "bomb.bin", Mode: 0644, Size: size, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(tarWriter, zeros); err != nil {
+		t.Fatal(err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// zeroReader reads an endless stream of zero bytes
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// TestExtractRejectsArchiveBomb checks that an archive unpacking past the extraction limit
+// is stopped, even in best-effort mode, and leaves no partial file behind
+func TestExtractRejectsArchiveBomb(t *testing.T) {
+	useTestConfig(t, &config.Config{IncludeBackupDirs: true, ExtractMaxMB: 1})
+
+	for _, name := range []string{"bomb.zip", "bomb.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			archivePath := filepath.Join(dir, name)
+			writeBombArchive(t, archivePath, 64<<20)
+
+			report := &extractReport{
+				BestEffort: true,
+				MaxBytes:   config.GetExtractMaxBytes(),
+				Failures:   make([]map[string]string, 0),
+			}
+			extracted, _, err := extractArchive(archivePath, dir, nil, report, true)
+			if !errors.Is(err, errExtractTooLarge) {
+				t.Fatalf("extractArchive error = %v, want errExtractTooLarge", err)
+			}
+			if extracted {
+				t.Error("extractArchive reported the bomb as extracted")
+			}
+			if _, err := os.Stat(filepath.Join(dir, "bomb.bin")); !os.IsNotExist(err) {
+				t.Errorf("partial bomb.bin left behind: %v", err)
+			}
+			if _, err := os.Stat(archivePath); err != nil {
+				t.Errorf("archive was removed after a failed extraction: %v", err)
+			}
+		})
+	}
+}
+
+// TestResolvePathStaysInFileRoot checks that paths above a server's file root are refused
+// rather than resolved to panel paths starting with ".."
+func TestResolvePathStaysInFileRoot(t *testing.T) {
+	setupTestDB(t)
+	useTestConfig(t, &config.Config{IncludeBackupDirs: true})
+
+	folder := t.TempDir()
+	writeTestFile(t, folder, "config/secrets.yml", "token: x")
+	writeTestFile(t, folder, "data/world/level.dat", "level")
+	server := &models.Server{Name: "survival", FolderPath: folder, FileRoot: "data", StartupCommand: "java -jar server.jar", UserID: 1}
+	if err := models.DB.Create(server).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	resolve := func(path string) (int, map[string]interface{}) {
+		r := httptest.NewRequest(http.MethodGet, "/server/survival/files/resolve?path="+url.QueryEscape(path), nil)
+		r = mux.SetURLVars(r, map[string]string{"name": server.Name})
+		r = r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, server.UserID))
+		w := httptest.NewRecorder()
+		ResolvePath(w, r)
+
+		var response map[string]interface{}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, response
+	}
+
+	for _, path := range []string{"/..", "/../config", "/world/../../config/secrets.yml", folder, filepath.Join(folder, "config")} {
+		code, response := resolve(path)
+		if code != http.StatusForbidden || response["code"] != ErrCodePathOutsideRoot {
+			t.Errorf("ResolvePath(%q) = %d %v, want %d %s", path, code, response, http.StatusForbidden, ErrCodePathOutsideRoot)
+		}
+	}
+
+	code, response := resolve(filepath.Join(folder, "data", "world"))
+	if code != http.StatusOK || response["path"] != "/world" {
+		t.Errorf("ResolvePath inside the file root = %d %v, want %d with path /world", code, response, http.StatusOK)
+	}
+}
+
+// writeTestFile creates a file, and the folders leading to it, under root
+func writeTestFile(t *testing.T, root, name, content string) {
+	t.Helper()
+
+	fullPath := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// BenchmarkCopyFiles compares copying many small files one by one and with the default
+// number of workers
+func BenchmarkCopyFiles(b *testing.B) {
+	src := b.TempDir()
+	content := make([]byte, 4096)
+	var files []copyPair
+	for i := 0; i < 2000; i++ {
+		name := fmt.Sprintf("region-%04d.dat", i)
+		if err := os.WriteFile(filepath.Join(src, name), content, 0644); err != nil {
+			b.Fatal(err)
+		}
+		files = append(files, copyPair{src: filepath.Join(src, name), dst: name})
+	}
+
+	for _, workers := range []int{1, config.DefaultCopyConcurrency} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dst := filepath.Join(b.TempDir(), "copy")
+				if err := os.Mkdir(dst, 0755); err != nil {
+					b.Fatal(err)
+				}
+				batch := make([]copyPair, len(files))
+				for j, file := range files {
+					batch[j] = copyPair{src: file.src, dst: filepath.Join(dst, file.dst)}
+				}
+				if err := copyFilesParallel(batch, workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
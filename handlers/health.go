@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"seiapanel/models"
+	"seiapanel/services"
+)
+
+// Health reports whether the panel is ready to serve, for load balancers and uptime checks. It
+// needs no login, so it only says which part is unhealthy; the schedule page shows the details.
+func Health(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	databaseOK := models.PingDatabase() == nil
+	schedulerRunning, schedulerErr := services.SchedulerStatus()
+
+	status := "ok"
+	switch {
+	case !databaseOK || !schedulerRunning:
+		status = "down"
+	case schedulerErr != nil:
+		status = "degraded"
+	}
+	if status == "down" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   status,
+		"database": databaseOK,
+		"scheduler": map[string]interface{}{
+			"running": schedulerRunning,
+			"healthy": schedulerRunning && schedulerErr == nil,
+		},
+	})
+}
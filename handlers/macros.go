@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ListCommandMacros returns all command macros of a server
+func ListCommandMacros(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	macros, err := models.GetCommandMacrosByServerID(server.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to retrieve macros",
+		})
+		return
+	}
+
+	formattedMacros := make([]map[string]interface{}, 0, len(macros))
+	for _, macro := range macros {
+		formattedMacros = append(formattedMacros, map[string]interface{}{
+			"macro":   macro,
+			"running": services.IsMacroRunning(macro.ID),
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"macros":  formattedMacros,
+	})
+}
+
+// CreateCommandMacro creates a command macro. Steps are sent as a JSON array of
+// {"command", "delay_seconds"} objects in the "steps" form field.
+func CreateCommandMacro(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	steps, ok := parseMacroForm(w, r)
+	if !ok {
+		return
+	}
+
+	macro, err := models.CreateCommandMacro(server.ID, r.FormValue("name"), steps)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, models.ErrMacroNameTaken) {
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Macro created successfully",
+		"macro":   macro,
+	})
+}
+
+// GetCommandMacro returns a single command macro
+func GetCommandMacro(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, macro, ok := loadServerMacro(w, r)
+	if !ok {
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"macro":   macro,
+		"running": services.IsMacroRunning(macro.ID),
+	})
+}
+
+// UpdateCommandMacro replaces a macro's name and steps
+func UpdateCommandMacro(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, macro, ok := loadServerMacro(w, r)
+	if !ok {
+		return
+	}
+
+	steps, ok := parseMacroForm(w, r)
+	if !ok {
+		return
+	}
+
+	if err := macro.Update(r.FormValue("name"), steps); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, models.ErrMacroNameTaken) {
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Macro updated successfully",
+		"macro":   macro,
+	})
+}
+
+// DeleteCommandMacro deletes a command macro
+func DeleteCommandMacro(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, macro, ok := loadServerMacro(w, r)
+	if !ok {
+		return
+	}
+
+	if err := macro.Delete(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to delete macro",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Macro deleted successfully",
+	})
+}
+
+// ExecuteCommandMacro starts sending a macro's commands to the running server. The run
+// continues in the background and its outcome is recorded in the audit log.
+func ExecuteCommandMacro(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	server, macro, ok := loadServerMacro(w, r)
+	if !ok {
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	clientIP := middleware.ClientIP(r)
+	total := len(macro.Steps)
+
+	err := services.RunCommandMacro(server, macro, func(sent int, err error) {
+		details := fmt.Sprintf("%s: %d/%d command(s) sent", macro.Name, sent, total)
+		if err != nil {
+			details += ": " + err.Error()
+		}
+		models.CreateAuditLog(userID, server.ID, "server.macro", models.AuditSourceSession, err == nil, details, clientIP)
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Macro '%s' started (%d command(s))", macro.Name, total),
+	})
+}
+
+// parseMacroForm reads the steps of a macro from the "steps" form field, writing an error
+// response and returning false when they can't be parsed
+func parseMacroForm(w http.ResponseWriter, r *http.Request) ([]models.MacroStep, bool) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return nil, false
+	}
+
+	var steps []models.MacroStep
+	if err := json.Unmarshal([]byte(r.FormValue("steps")), &steps); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid steps data",
+		})
+		return nil, false
+	}
+	return steps, true
+}
+
+// loadServerMacro resolves the server and macro of a macro route, writing an error response
+// and returning false when either is missing or the macro belongs to another server
+func loadServerMacro(w http.ResponseWriter, r *http.Request) (*models.Server, *models.CommandMacro, bool) {
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return nil, nil, false
+	}
+
+	macroID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid macro ID",
+		})
+		return nil, nil, false
+	}
+
+	macro, err := models.GetCommandMacroByID(uint(macroID))
+	if err != nil || macro.ServerID != server.ID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Macro not found",
+		})
+		return nil, nil, false
+	}
+
+	return server, macro, true
+}
@@ -20,7 +20,7 @@ func ResourcePage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 
 	tmpl, err := template.ParseFiles("templates/resource.html")
 	if err != nil {
@@ -119,6 +119,16 @@ func GetSystemStats(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
+	// Volumes holding the server root and backup folders, from the disk space monitor
+	volumes, checkedAt := services.GetDiskVolumes()
+	alertFree, _ := config.GetDiskAlert()
+	response["volumes"] = volumes
+	response["volume_alert_free"] = alertFree
+	response["volumes_checked_at"] = checkedAt
+
+	// Open file descriptors of the panel process, from the file descriptor monitor
+	response["file_descriptors"] = services.GetFDState()
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
\ No newline at end of file
@@ -2,9 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"html/template"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"seiapanel/middleware"
 	"seiapanel/models"
@@ -60,6 +65,7 @@ func ListSchedules(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -71,13 +77,146 @@ func ListSchedules(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to retrieve schedules",
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
 
+	// Schedules keep their own enabled flag; a pause applies on top of it
+	paused := false
+	if user, err := models.GetUserByID(userID); err == nil {
+		paused = user.SchedulesPaused
+	}
+
+	response := map[string]interface{}{
+		"success":        true,
+		"schedules":      schedules,
+		"paused":         paused,
+		"scheduler_down": false,
+	}
+
+	// Schedules can be saved while the scheduler is down, but none of them will run
+	if running, err := services.SchedulerStatus(); !running || err != nil {
+		response["scheduler_down"] = !running
+		if err != nil {
+			response["scheduler_error"] = err.Error()
+		}
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetScheduleEntries returns how each of a server's schedules is registered in the cron
+// engine, so drift between the database and the scheduler can be spotted
+func GetScheduleEntries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	scheduleService := services.GetScheduleService()
+	if scheduleService == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Schedule service not available",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	// Get schedules
+	schedules, err := models.GetSchedulesByServerID(server.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to retrieve schedules",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	entries := scheduleService.GetScheduleEntries(schedules)
+
+	driftCount := 0
+	for _, entry := range entries {
+		if entry.Drift {
+			driftCount++
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"entries":     entries,
+		"drift_count": driftCount,
+	})
+}
+
+// ReconcileSchedules re-syncs the cron engine with a server's schedules in the database
+func ReconcileSchedules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	scheduleService := services.GetScheduleService()
+	if scheduleService == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Schedule service not available",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	// Get schedules
+	schedules, err := models.GetSchedulesByServerID(server.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to retrieve schedules",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	fixed, failed := scheduleService.ReconcileSchedules(schedules)
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":   true,
-		"schedules": schedules,
+		"success": len(failed) == 0,
+		"message": fmt.Sprintf("Reconciled %d schedules, %d were out of sync", len(schedules), len(fixed)),
+		"fixed":   fixed,
+		"failed":  failed,
+		"entries": scheduleService.GetScheduleEntries(schedules),
 	})
 }
 
@@ -97,6 +236,7 @@ func GetSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -108,6 +248,7 @@ func GetSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid schedule ID",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -119,6 +260,7 @@ func GetSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Schedule not found",
+			"code":    ErrCodeScheduleNotFound,
 		})
 		return
 	}
@@ -129,6 +271,7 @@ func GetSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
 		})
 		return
 	}
@@ -139,6 +282,187 @@ func GetSchedule(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// PreviewSchedule lists the next times a schedule would fire, without saving anything - AJAX
+// JSON response. The cron fields are given like when creating a schedule, or "schedule_id"
+// previews an existing schedule. "count" sets how many times to return.
+func PreviewSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	count := 10
+	if countStr := r.FormValue("count"); countStr != "" {
+		parsed, err := strconv.Atoi(countStr)
+		if err != nil || parsed < 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid count",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+		count = min(parsed, 100)
+	}
+
+	fields := models.Schedule{
+		CronMinute:     strings.TrimSpace(r.FormValue("cron_minute")),
+		CronHour:       strings.TrimSpace(r.FormValue("cron_hour")),
+		CronDayOfMonth: strings.TrimSpace(r.FormValue("cron_day_of_month")),
+		CronMonth:      strings.TrimSpace(r.FormValue("cron_month")),
+		CronDayOfWeek:  strings.TrimSpace(r.FormValue("cron_day_of_week")),
+	}
+	if scheduleIDStr := r.FormValue("schedule_id"); scheduleIDStr != "" {
+		scheduleID, err := strconv.ParseUint(scheduleIDStr, 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid schedule ID",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+
+		schedule, err := models.GetScheduleByID(uint(scheduleID))
+		if err != nil || schedule.ServerID != server.ID {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Schedule not found",
+				"code":    ErrCodeScheduleNotFound,
+			})
+			return
+		}
+		fields = *schedule
+	} else {
+		// Same checks as creating the schedule, so a preview never passes where a save would fail
+		for _, field := range []struct{ name, value string }{
+			{"minute", fields.CronMinute},
+			{"hour", fields.CronHour},
+			{"day_of_month", fields.CronDayOfMonth},
+			{"month", fields.CronMonth},
+			{"day_of_week", fields.CronDayOfWeek},
+		} {
+			if err := models.ValidateCronField(field.name, field.value); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"success": false,
+					"error":   err.Error(),
+					"code":    ErrCodeInvalidRequest,
+				})
+				return
+			}
+		}
+	}
+
+	expression := fields.GetCronExpression()
+	runs, err := services.PreviewCronRuns(expression, time.Now(), count)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Invalid cron expression: %v", err),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"expression":  expression,
+		"description": fields.Describe(),
+		"runs":        runs,
+	})
+}
+
+// GetScheduleRuns returns the run history of a schedule, newest first
+func GetScheduleRuns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	scheduleIDStr := vars["id"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse schedule ID
+	scheduleID, err := strconv.ParseUint(scheduleIDStr, 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid schedule ID",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Get schedule
+	schedule, err := models.GetScheduleByID(uint(scheduleID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Schedule not found",
+			"code":    ErrCodeScheduleNotFound,
+		})
+		return
+	}
+
+	// Verify schedule belongs to this server
+	if schedule.ServerID != server.ID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
+		})
+		return
+	}
+
+	runs, err := models.GetScheduleRuns(schedule.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to retrieve run history",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"runs":    runs,
+	})
+}
+
 // CreateSchedule creates a new schedule
 func CreateSchedule(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -154,6 +478,7 @@ func CreateSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -164,6 +489,7 @@ func CreateSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -182,6 +508,14 @@ func CreateSchedule(w http.ResponseWriter, r *http.Request) {
 	// Parse enabled flag
 	enabled := enabledStr == "true" || enabledStr == "1"
 
+	// Catch-up of missed runs is opt-in
+	catchUpStr := r.FormValue("catch_up")
+	catchUp := catchUpStr == "true" || catchUpStr == "1"
+
+	// Overlapping runs are allowed unless the schedule opts out
+	skipOverlapStr := r.FormValue("skip_overlap")
+	skipOverlap := skipOverlapStr == "true" || skipOverlapStr == "1"
+
 	// Create schedule
 	schedule, err := models.CreateSchedule(
 		server.ID,
@@ -192,15 +526,27 @@ func CreateSchedule(w http.ResponseWriter, r *http.Request) {
 		cronMonth,
 		cronDayOfWeek,
 		enabled,
+		catchUp,
+		skipOverlap,
 		action,
 		command,
 	)
 
+	if errors.Is(err, models.ErrScheduleNameTaken) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    ErrCodeConflict,
+		})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   err.Error(),
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -246,6 +592,7 @@ func UpdateSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -257,6 +604,7 @@ func UpdateSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid schedule ID",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -268,6 +616,7 @@ func UpdateSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Schedule not found",
+			"code":    ErrCodeScheduleNotFound,
 		})
 		return
 	}
@@ -278,6 +627,7 @@ func UpdateSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
 		})
 		return
 	}
@@ -288,6 +638,7 @@ func UpdateSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Error parsing form",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -306,6 +657,18 @@ func UpdateSchedule(w http.ResponseWriter, r *http.Request) {
 	// Parse enabled flag
 	enabled := enabledStr == "true" || enabledStr == "1"
 
+	// Keep the current catch-up setting unless the form provides one
+	catchUp := schedule.CatchUp
+	if catchUpStr := r.FormValue("catch_up"); catchUpStr != "" {
+		catchUp = catchUpStr == "true" || catchUpStr == "1"
+	}
+
+	// Keep the current overlap setting unless the form provides one
+	skipOverlap := schedule.SkipOverlap
+	if skipOverlapStr := r.FormValue("skip_overlap"); skipOverlapStr != "" {
+		skipOverlap = skipOverlapStr == "true" || skipOverlapStr == "1"
+	}
+
 	// Update schedule
 	err = schedule.UpdateSchedule(
 		name,
@@ -315,15 +678,27 @@ func UpdateSchedule(w http.ResponseWriter, r *http.Request) {
 		cronMonth,
 		cronDayOfWeek,
 		enabled,
+		catchUp,
+		skipOverlap,
 		action,
 		command,
 	)
 
+	if errors.Is(err, models.ErrScheduleNameTaken) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    ErrCodeConflict,
+		})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   err.Error(),
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -366,6 +741,7 @@ func DeleteSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -377,6 +753,7 @@ func DeleteSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid schedule ID",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -388,6 +765,7 @@ func DeleteSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Schedule not found",
+			"code":    ErrCodeScheduleNotFound,
 		})
 		return
 	}
@@ -398,6 +776,7 @@ func DeleteSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
 		})
 		return
 	}
@@ -414,6 +793,7 @@ func DeleteSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to delete schedule",
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
@@ -440,6 +820,7 @@ func ToggleSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -451,6 +832,7 @@ func ToggleSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid schedule ID",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -462,6 +844,7 @@ func ToggleSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Schedule not found",
+			"code":    ErrCodeScheduleNotFound,
 		})
 		return
 	}
@@ -472,6 +855,7 @@ func ToggleSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
 		})
 		return
 	}
@@ -482,6 +866,7 @@ func ToggleSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Failed to toggle schedule",
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
@@ -500,6 +885,81 @@ func ToggleSchedule(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// DuplicateSchedule creates a disabled copy of a schedule. The copy isn't registered with the
+// scheduler until it is enabled.
+func DuplicateSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	scheduleIDStr := vars["id"]
+	userID := middleware.GetUserID(r)
+
+	// Get server
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
+		})
+		return
+	}
+
+	// Parse schedule ID
+	scheduleID, err := strconv.ParseUint(scheduleIDStr, 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid schedule ID",
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	// Get schedule
+	schedule, err := models.GetScheduleByID(uint(scheduleID))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Schedule not found",
+			"code":    ErrCodeScheduleNotFound,
+		})
+		return
+	}
+
+	// Verify schedule belongs to this server
+	if schedule.ServerID != server.ID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
+		})
+		return
+	}
+
+	duplicate, err := schedule.Duplicate()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to duplicate schedule: " + err.Error(),
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"message":  "Schedule duplicated successfully",
+		"schedule": duplicate,
+	})
+}
+
 // ExecuteSchedule executes a schedule manually
 func ExecuteSchedule(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -516,6 +976,7 @@ func ExecuteSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Server not found",
+			"code":    ErrCodeServerNotFound,
 		})
 		return
 	}
@@ -527,6 +988,7 @@ func ExecuteSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Invalid schedule ID",
+			"code":    ErrCodeInvalidRequest,
 		})
 		return
 	}
@@ -538,6 +1000,7 @@ func ExecuteSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Schedule not found",
+			"code":    ErrCodeScheduleNotFound,
 		})
 		return
 	}
@@ -548,6 +1011,7 @@ func ExecuteSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Access denied",
+			"code":    ErrCodeAccessDenied,
 		})
 		return
 	}
@@ -561,6 +1025,7 @@ func ExecuteSchedule(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"error":   "Schedule service not available",
+			"code":    ErrCodeInternal,
 		})
 		return
 	}
@@ -569,4 +1034,178 @@ func ExecuteSchedule(w http.ResponseWriter, r *http.Request) {
 		"success": true,
 		"message": "Schedule executed successfully",
 	})
-}
\ No newline at end of file
+}
+
+// PauseSchedules pauses all of the user's schedules and backup policies
+func PauseSchedules(w http.ResponseWriter, r *http.Request) {
+	setSchedulesPaused(w, r, true)
+}
+
+// ResumeSchedules resumes the user's schedules and backup policies on their normal timing
+func ResumeSchedules(w http.ResponseWriter, r *http.Request) {
+	setSchedulesPaused(w, r, false)
+}
+
+// setSchedulesPaused updates the user's pause flag - AJAX JSON response
+func setSchedulesPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "User not found",
+			"code":    ErrCodeNotFound,
+		})
+		return
+	}
+
+	if err := user.SetSchedulesPaused(paused); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to update schedules",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	action := "schedules.resume"
+	message := "Schedules resumed"
+	icon := "▶️ "
+	if paused {
+		action = "schedules.pause"
+		message = "Schedules paused"
+		icon = "⏸️ "
+	}
+	models.CreateAuditLog(user.ID, 0, action, models.AuditSourceSession, true, "", middleware.ClientIP(r))
+	log.Printf("%s %s by %s", icon, message, user.Username)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": message,
+		"paused":  paused,
+	})
+}
+
+// GetUpcomingRuns lists the next executions across all of the user's enabled schedules in
+// chronological order - AJAX JSON response. "limit" sets how many runs to return.
+func GetUpcomingRuns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Invalid limit",
+				"code":    ErrCodeInvalidRequest,
+			})
+			return
+		}
+		limit = min(parsed, 200)
+	}
+
+	user, err := models.GetUserByID(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "User not found",
+			"code":    ErrCodeNotFound,
+		})
+		return
+	}
+
+	servers, err := models.GetServersByUserID(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to load servers",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	scheduleService := services.GetScheduleService()
+	if scheduleService == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Schedule service not available",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	serverNames := make(map[uint]string, len(servers))
+	enabled := make([]models.Schedule, 0)
+	for _, server := range servers {
+		serverNames[server.ID] = server.Name
+		schedules, err := models.GetSchedulesByServerID(server.ID)
+		if err != nil {
+			continue
+		}
+		for _, schedule := range schedules {
+			if schedule.Enabled {
+				enabled = append(enabled, schedule)
+			}
+		}
+	}
+
+	upcoming := scheduleService.GetUpcomingRuns(enabled, limit)
+	runs := make([]map[string]interface{}, 0, len(upcoming))
+	for _, run := range upcoming {
+		runs = append(runs, map[string]interface{}{
+			"schedule_id":   run.ScheduleID,
+			"schedule_name": run.Name,
+			"server_name":   serverNames[run.ServerID],
+			"action":        run.Action,
+			"time":          run.Time,
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"paused":  user.SchedulesPaused,
+		"runs":    runs,
+	})
+}
+
+// RestartScheduler stops the schedule service and starts it again from the database, for
+// recovering from a failed start - AJAX JSON response
+func RestartScheduler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	err := services.RestartScheduler()
+	running, _ := services.SchedulerStatus()
+	models.CreateAuditLog(userID, 0, "scheduler.restart", models.AuditSourceSession, running, "", middleware.ClientIP(r))
+
+	if !running {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Scheduler failed to start: " + err.Error(),
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Scheduler restarted",
+	}
+	if err != nil {
+		response["warning"] = err.Error()
+	}
+	json.NewEncoder(w).Encode(response)
+}
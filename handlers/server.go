@@ -2,12 +2,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"html/template"
+	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"seiapanel/config"
 	"seiapanel/middleware"
@@ -26,6 +33,9 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// consoleConns tracks open console WebSockets to enforce the configured limits
+var consoleConns = middleware.NewConnLimiter()
+
 // Dashboard renders the home/dashboard page with server list
 func Dashboard(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
@@ -47,7 +57,7 @@ func Dashboard(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 
 	tmpl, err := template.ParseFiles("templates/dashboard.html")
 	if err != nil {
@@ -56,10 +66,13 @@ func Dashboard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := map[string]interface{}{
-		"User":    user,
-		"Servers": servers,
-		"Success": session.Flashes("success"),
-		"Error":   session.Flashes("error"),
+		"User":        user,
+		"Servers":     servers,
+		"ServerCount": len(servers),
+		"ServerLimit": config.GetMaxServersPerUser(),
+		"Success":     session.Flashes("success"),
+		"Error":       session.Flashes("error"),
+		"Version":     config.Version,
 	}
 	session.Save(r, w)
 
@@ -74,10 +87,17 @@ func scanAndSyncServers(userID uint, serverPath string) ([]models.Server, error)
 		existingServers = []models.Server{}
 	}
 
-	// Create map of existing servers
+	// Create map of existing servers by folder name, which stays the same when a server is renamed
+	// Imported servers may live deeper in the root; those are kept while their folder exists
 	serverMap := make(map[string]*models.Server)
+	nestedServers := make(map[uint]bool)
 	for i := range existingServers {
-		serverMap[existingServers[i].Name] = &existingServers[i]
+		if isSubPath(filepath.Dir(existingServers[i].FolderPath), filepath.Clean(serverPath)) {
+			info, err := os.Stat(existingServers[i].FolderPath)
+			nestedServers[existingServers[i].ID] = err == nil && info.IsDir()
+			continue
+		}
+		serverMap[filepath.Base(existingServers[i].FolderPath)] = &existingServers[i]
 	}
 
 	// Scan directories
@@ -95,11 +115,7 @@ func scanAndSyncServers(userID uint, serverPath string) ([]models.Server, error)
 			fullPath := filepath.Join(serverPath, serverName)
 
 			// Skip hidden directories and common non-server folders
-			if strings.HasPrefix(serverName, ".") || 
-			   serverName == "node_modules" || 
-			   serverName == "cache" ||
-			   serverName == "logs" ||
-			   serverName == "backups" {
+			if isIgnoredServerFolder(serverName) {
 				continue
 			}
 
@@ -117,7 +133,11 @@ func scanAndSyncServers(userID uint, serverPath string) ([]models.Server, error)
 				// Find startup script
 				startupCmd := findStartupCommand(fullPath)
 				if startupCmd != "" {
-					// Create new server entry
+					// Create new server entry, unless the user is at their server limit
+					if err := checkServerLimit(userID); err != nil {
+						log.Printf("⚠️  Not adding server folder %s: %v", serverName, err)
+						continue
+					}
 					models.CreateServer(serverName, fullPath, startupCmd, userID)
 				}
 			}
@@ -126,7 +146,13 @@ func scanAndSyncServers(userID uint, serverPath string) ([]models.Server, error)
 
 	// Delete servers that are no longer in the current path
 	for _, server := range existingServers {
-		if !foundServers[server.Name] {
+		if exists, nested := nestedServers[server.ID]; nested {
+			if !exists {
+				models.DB.Delete(&server)
+			}
+			continue
+		}
+		if !foundServers[filepath.Base(server.FolderPath)] {
 			// Server is not in the new path, delete it
 			models.DB.Delete(&server)
 		}
@@ -136,6 +162,31 @@ func scanAndSyncServers(userID uint, serverPath string) ([]models.Server, error)
 	return models.GetServersByUserID(userID)
 }
 
+// checkServerLimit returns an error when the user already has as many servers as allowed
+func checkServerLimit(userID uint) error {
+	limit := config.GetMaxServersPerUser()
+	if limit == 0 {
+		return nil
+	}
+	count, err := models.CountServersByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to count servers: %w", err)
+	}
+	if count >= int64(limit) {
+		return fmt.Errorf("server limit reached (%d of %d)", count, limit)
+	}
+	return nil
+}
+
+// isIgnoredServerFolder reports whether a folder in the server root is never treated as a server
+func isIgnoredServerFolder(name string) bool {
+	return strings.HasPrefix(name, ".") ||
+		name == "node_modules" ||
+		name == "cache" ||
+		name == "logs" ||
+		name == "backups"
+}
+
 // findStartupCommand looks for common startup scripts/commands
 func findStartupCommand(serverPath string) string {
 	// Check for common script files
@@ -181,7 +232,7 @@ func ServerConsolePage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 
 	tmpl, err := template.ParseFiles("templates/console.html")
 	if err != nil {
@@ -213,7 +264,11 @@ func StartServer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := services.StartServer(server); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, services.ErrOperationInProgress) {
+			w.WriteHeader(http.StatusConflict)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
@@ -305,6 +360,13 @@ func SendCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := server.CheckConsoleCommand(command); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
 	if err := services.SendCommand(server, command); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
@@ -315,6 +377,44 @@ func SendCommand(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "Command sent successfully"})
 }
 
+// ReloadServer sends the server's configured reload command - AJAX JSON response
+func ReloadServer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	if err := services.ReloadServer(server); err != nil {
+		status := http.StatusConflict
+		if errors.Is(err, services.ErrNoReloadCommand) {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Reload command sent",
+		"command": server.ReloadCommand,
+	})
+}
+
 // GetLogs retrieves server logs
 func GetLogs(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -330,11 +430,147 @@ func GetLogs(w http.ResponseWriter, r *http.Request) {
 	logs := services.GetLogs(server)
 
 	w.Header().Set("Content-Type", "application/json")
+
+	// Optional time window (RFC3339 or YYYY-MM-DD), only usable when lines can be parsed
+	var since, until time.Time
+	for param, bound := range map[string]*time.Time{"since": &since, "until": &until} {
+		value := r.URL.Query().Get(param)
+		if value == "" {
+			continue
+		}
+		t, err := parseTimeParam(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": fmt.Sprintf("Invalid %s timestamp (use RFC3339 or YYYY-MM-DD)", param),
+			})
+			return
+		}
+		*bound = t
+	}
+
+	parser, err := services.NewLogParser(server)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "Invalid log pattern: " + err.Error(),
+		})
+		return
+	}
+
+	if parser == nil {
+		if !since.IsZero() || !until.IsZero() {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": "Time filters need a log pattern configured for this server",
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"logs": logs,
+		})
+		return
+	}
+
+	entries := services.FilterLogEntries(parser.Parse(logs, time.Now()), since, until)
+	filteredLogs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		filteredLogs = append(filteredLogs, entry.Raw)
+	}
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"logs": logs,
+		"logs":    filteredLogs,
+		"entries": entries,
 	})
 }
 
+// DownloadLogs sends the server log as a file attachment. It serves logs/latest.log when the
+// server writes one, falling back to the console buffer, and with rotated=true prepends the
+// rotated logs. Range requests are supported so large logs can be resumed.
+func DownloadLogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	rotated := r.URL.Query().Get("rotated")
+	includeRotated := rotated == "true" || rotated == "1"
+
+	logPath := existingLogPath(server)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	switch {
+	case includeRotated:
+		exportPath, err := services.ExportServerLogs(server)
+		if err != nil {
+			log.Printf("❌ Failed to export logs for '%s': %v", server.Name, err)
+			http.Error(w, "Failed to export logs", http.StatusInternalServerError)
+			return
+		}
+		defer os.Remove(exportPath)
+
+		file, err := os.Open(exportPath)
+		if err != nil {
+			http.Error(w, "Failed to open log export", http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+
+		fileName := fmt.Sprintf("%s-logs-%s.log", server.Name, time.Now().Format("20060102-150405"))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+		http.ServeContent(w, r, fileName, time.Now(), file)
+
+	case logPath != "":
+		// Hold the lock for the whole transfer so the file isn't rewritten mid-download
+		unlock := services.LockFileShared(logPath)
+		defer unlock()
+
+		file, err := os.Open(logPath)
+		if err != nil {
+			http.Error(w, "Failed to open log file", http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			http.Error(w, "Failed to access log file", http.StatusInternalServerError)
+			return
+		}
+
+		fileName := fmt.Sprintf("%s-latest.log", server.Name)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+		// The server keeps appending, so only serve the bytes present when the request started
+		http.ServeContent(w, r, fileName, info.ModTime(), io.NewSectionReader(file, 0, info.Size()))
+
+	default:
+		logs := services.GetLogs(server)
+		content := strings.Join(logs, "\n")
+		if len(logs) > 0 {
+			content += "\n"
+		}
+
+		fileName := fmt.Sprintf("%s-console-%s.log", server.Name, time.Now().Format("20060102-150405"))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+		http.ServeContent(w, r, fileName, time.Now(), strings.NewReader(content))
+	}
+}
+
+// existingLogPath returns the server's latest.log path, or "" when it doesn't write one
+func existingLogPath(server *models.Server) string {
+	logPath := services.ServerLogPath(server)
+	if info, err := os.Stat(logPath); err != nil || info.IsDir() {
+		return ""
+	}
+	return logPath
+}
+
 // GetServerStats retrieves server statistics (memory, CPU, etc.)
 func GetServerStats(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -378,19 +614,36 @@ func ConsoleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
+	// Reject over-limit connections after the upgrade so the client gets a proper close code
+	perUser, perServer := config.GetWebSocketLimits()
+	if !consoleConns.Acquire(userID, server.Name, perUser, perServer) {
+		log.Printf("⚠️  Rejected console WebSocket for %s: connection limit reached", server.Name)
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "Too many open console connections"),
+			time.Now().Add(time.Second))
+		return
+	}
+	defer consoleConns.Release(userID, server.Name)
+
 	// Register this connection to receive console updates
 	services.AddConsoleListener(server, conn)
 	defer services.RemoveConsoleListener(server, conn)
 
-	// Keep connection alive and handle ping/pong
+	// Ping the client so a connection dropped by a proxy is noticed and cleaned up
+	stopKeepalive := services.KeepWebSocketAlive(conn)
+	defer stopKeepalive()
+
+	// Read until the client goes away or stops answering pings
 	for {
 		messageType, message, err := conn.ReadMessage()
 		if err != nil {
 			break
 		}
-		
+
 		// Handle ping from client
 		if messageType == websocket.TextMessage && string(message) == "ping" {
+			conn.SetReadDeadline(time.Now().Add(services.WSPongWait))
+			conn.SetWriteDeadline(time.Now().Add(services.WSWriteWait))
 			conn.WriteMessage(websocket.TextMessage, []byte("pong"))
 		}
 	}
@@ -414,7 +667,7 @@ func StartupPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 
 	tmpl, err := template.ParseFiles("templates/startup.html")
 	if err != nil {
@@ -471,6 +724,59 @@ func UpdateStartup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, submitted := r.Form["reload_command"]; submitted {
+		if err := server.UpdateReloadCommand(r.FormValue("reload_command")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	if _, submitted := r.Form["auto_start_on_boot"]; submitted {
+		autoStart := r.FormValue("auto_start_on_boot")
+		if err := server.UpdateAutoStart(autoStart == "true" || autoStart == "1"); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Error updating auto-start: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	if _, submitted := r.Form["log_pattern"]; submitted {
+		if err := server.UpdateLogFormat(r.FormValue("log_pattern"), r.FormValue("log_time_layout")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	// Launch options are only changed when submitted, since empty values are meaningful (reset)
+	workingDir, extraArgs := server.WorkingDir, server.ExtraArgs
+	if _, submitted := r.Form["working_dir"]; submitted {
+		workingDir = r.FormValue("working_dir")
+	}
+	if _, submitted := r.Form["extra_args"]; submitted {
+		extraArgs = r.FormValue("extra_args")
+	}
+	if workingDir != server.WorkingDir || extraArgs != server.ExtraArgs {
+		if err := server.UpdateLaunchOptions(workingDir, extraArgs); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
 	if err := server.UpdateStartupCommand(command); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -480,9 +786,602 @@ func UpdateStartup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":            true,
+		"message":            "Startup command updated successfully",
+		"command":            command,
+		"working_dir":        server.WorkingDir,
+		"extra_args":         server.ExtraArgs,
+		"reload_command":     server.ReloadCommand,
+		"auto_start_on_boot": server.AutoStartOnBoot,
+		"log_pattern":        server.LogPattern,
+		"log_time_layout":    server.LogTimeLayout,
+	})
+}
+
+// UpdateServerTags replaces the server's tags - AJAX JSON response
+func UpdateServerTags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return
+	}
+
+	// Tags are submitted comma-separated; backup policies pick up changes on their next run
+	if err := server.UpdateTags(strings.Split(r.FormValue("tags"), ",")); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error updating tags: " + err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Server tags updated successfully",
+		"tags":    server.GetTags(),
+	})
+}
+
+// serverNamePattern restricts renamed servers to names that are safe in URLs and as folder names
+var serverNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// RenameServer changes a server's name - AJAX JSON response. The folder, schedules and
+// backups stay attached since they are keyed by the server ID and folder path.
+func RenameServer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	newName := strings.TrimSpace(r.FormValue("new_name"))
+	if !serverNamePattern.MatchString(newName) || isIgnoredServerFolder(newName) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid server name. Use up to 64 letters, digits, dots, dashes or underscores.",
+		})
+		return
+	}
+
+	if newName == server.Name {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "The new name is the same as the current name",
+		})
+		return
+	}
+
+	// The running process holds on to the server record, so only rename stopped servers
+	if services.IsServerRunning(server) || services.GetServerTransition(server) != "" {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Stop the server before renaming it",
+		})
+		return
+	}
+
+	if _, err := models.GetServerByNameAnyUser(newName); err == nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "A server with this name already exists",
+		})
+		return
+	}
+
+	// A folder with the new name would be picked up as a separate server by the dashboard scan
+	siblingPath := filepath.Join(filepath.Dir(server.FolderPath), newName)
+	if siblingPath != server.FolderPath {
+		if _, err := os.Stat(siblingPath); err == nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "A folder with this name already exists in the server folder",
+			})
+			return
+		}
+	}
+
+	oldName := server.Name
+	if err := server.Rename(newName); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to rename server",
+		})
+		return
+	}
+
+	models.CreateAuditLog(userID, server.ID, "server.rename", models.AuditSourceSession, true, fmt.Sprintf("%s -> %s", oldName, newName), middleware.ClientIP(r))
+	log.Printf("✅ Server renamed: %s -> %s", oldName, newName)
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
-		"message": "Startup command updated successfully",
-		"command": command,
+		"message": "Server renamed. Update any webhook trigger URLs that use the old name.",
+		"server":  server,
 	})
-}
\ No newline at end of file
+}
+
+// UpdateFileRoot sets the file manager root subpath - AJAX JSON response
+func UpdateFileRoot(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return
+	}
+
+	if err := server.UpdateFileRoot(r.FormValue("file_root")); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"message":   "File root updated successfully",
+		"file_root": server.FileRoot,
+	})
+}
+
+// UpdateFileModes updates the modes for files and directories created through the panel - AJAX JSON response
+func UpdateFileModes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return
+	}
+
+	if err := server.UpdateFileModes(r.FormValue("file_mode"), r.FormValue("dir_mode")); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"message":   "File permissions updated successfully",
+		"file_mode": fmt.Sprintf("%04o", server.FilePerm()),
+		"dir_mode":  fmt.Sprintf("%04o", server.DirPerm()),
+	})
+}
+
+// UpdateConsolePolicy updates which commands may be sent from the live console - AJAX JSON response.
+// Scheduled commands, macros and the power buttons are not affected.
+func UpdateConsolePolicy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return
+	}
+
+	// Lists may be separated by commas or new lines
+	splitList := func(value string) []string {
+		return strings.FieldsFunc(value, func(c rune) bool {
+			return c == ',' || c == '\n' || c == '\r'
+		})
+	}
+
+	if err := server.UpdateConsolePolicy(splitList(r.FormValue("console_allowed")), splitList(r.FormValue("console_blocked"))); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error updating console policy: " + err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":         true,
+		"message":         "Console command policy updated successfully",
+		"console_allowed": server.GetConsoleAllowed(),
+		"console_blocked": server.GetConsoleBlocked(),
+	})
+}
+
+// UpdateAlertSettings updates the server's resource alert thresholds - AJAX JSON response
+func UpdateAlertSettings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return
+	}
+
+	// Thresholds of 0 disable that alert
+	cpuPercent, err1 := strconv.ParseFloat(r.FormValue("alert_cpu_percent"), 64)
+	memPercent, err2 := strconv.ParseFloat(r.FormValue("alert_mem_percent"), 64)
+	duration, err3 := strconv.Atoi(r.FormValue("alert_duration"))
+	if err1 != nil || err2 != nil || err3 != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Alert thresholds and duration must be numbers",
+		})
+		return
+	}
+
+	if err := server.UpdateAlertSettings(cpuPercent, memPercent, duration); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Alert settings updated successfully",
+		"data": map[string]interface{}{
+			"alert_cpu_percent": server.AlertCPUPercent,
+			"alert_mem_percent": server.AlertMemPercent,
+			"alert_duration":    server.AlertDuration,
+		},
+	})
+}
+
+// CreateServer creates a server in the configured server root - AJAX JSON response.
+// Servers are discovered by folder name, so the folder is always <root>/<name>: an existing
+// folder is adopted, otherwise an empty one is created.
+func CreateServer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return
+	}
+
+	serverPath := config.GetServerPath()
+	if serverPath == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server folder path is not configured",
+		})
+		return
+	}
+
+	if err := checkServerLimit(userID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// The name doubles as the folder name, so it must be a single safe path segment
+	name := strings.TrimSpace(r.FormValue("name"))
+	if !serverNamePattern.MatchString(name) || isIgnoredServerFolder(name) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid server name: use letters, digits, dots, dashes and underscores",
+		})
+		return
+	}
+
+	if _, err := models.GetServerByNameAnyUser(name); err == nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "A server with this name already exists",
+		})
+		return
+	}
+
+	folderPath := filepath.Join(serverPath, name)
+	if filepath.Dir(folderPath) != filepath.Clean(serverPath) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid server name",
+		})
+		return
+	}
+
+	// A renamed server keeps its folder, so the folder may belong to a server with another name
+	if _, err := models.GetServerByFolderPath(folderPath); err == nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "A server already uses this folder",
+		})
+		return
+	}
+
+	// Fall back to a detected startup script or jar for adopted folders. Everything is
+	// checked before the folder is created, so a rejected request leaves nothing behind.
+	startupCommand := strings.TrimSpace(r.FormValue("startup_command"))
+	if startupCommand == "" {
+		startupCommand = findStartupCommand(folderPath)
+	}
+	if startupCommand == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Startup command is required",
+		})
+		return
+	}
+
+	_, statErr := os.Stat(folderPath)
+	createdFolder := os.IsNotExist(statErr)
+	if err := os.MkdirAll(folderPath, 0755); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to create server folder",
+		})
+		return
+	}
+
+	server, err := models.CreateServer(name, folderPath, startupCommand, userID)
+	if err != nil {
+		if createdFolder {
+			os.Remove(folderPath)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to create server",
+		})
+		return
+	}
+
+	models.CreateAuditLog(userID, server.ID, "server.create", models.AuditSourceSession, true, folderPath, middleware.ClientIP(r))
+	log.Printf("✅ Server created: %s (%s)", server.Name, server.FolderPath)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Server created successfully",
+		"server":  server,
+	})
+}
+
+// DeleteServer deletes a server record with its schedules and backup records - AJAX JSON
+// response. The "confirm" form value must repeat the server name. With delete_files=true
+// the server folder and backup files are removed too; otherwise a folder left in the
+// server root is picked up again by the next dashboard scan.
+func DeleteServer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return
+	}
+
+	if r.FormValue("confirm") != server.Name {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Confirmation does not match the server name",
+		})
+		return
+	}
+
+	// Hold the server for the whole delete, so a backup, restore or start can't begin on a
+	// folder that is being removed
+	if err := services.BeginServerOperation(server.ID, "delete"); err != nil {
+		writeOperationInProgressError(w, err)
+		return
+	}
+	defer services.EndServerOperation(server.ID)
+
+	if state := services.GetServerTransition(server); state != "" {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Cannot delete while server is %s. Please wait and try again.", state),
+		})
+		return
+	}
+	if services.IsServerRunning(server) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Stop the server before deleting it",
+		})
+		return
+	}
+
+	deleteFilesStr := r.FormValue("delete_files")
+	deleteFiles := deleteFilesStr == "true" || deleteFilesStr == "1"
+
+	// Only ever remove folders that sit inside the configured server root
+	serverPath := config.GetServerPath()
+	if deleteFiles && (serverPath == "" || filepath.Dir(filepath.Clean(server.FolderPath)) != filepath.Clean(serverPath)) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server folder is outside the server root, refusing to delete files",
+		})
+		return
+	}
+
+	schedules, _ := models.GetSchedulesByServerID(server.ID)
+	backups, _ := models.GetBackupsByServerID(server.ID)
+
+	if err := server.Delete(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to delete server",
+		})
+		return
+	}
+
+	// Drop the server's schedules from cron now that their records are gone
+	if scheduleService := services.GetScheduleService(); scheduleService != nil {
+		for _, schedule := range schedules {
+			scheduleService.RemoveSchedule(schedule.ID)
+		}
+	}
+
+	fileErrors := make([]string, 0)
+	if deleteFiles {
+		for _, backup := range backups {
+			if err := os.Remove(backup.FilePath); err != nil && !os.IsNotExist(err) {
+				fileErrors = append(fileErrors, fmt.Sprintf("%s: %v", backup.FileName, err))
+			}
+		}
+		if err := os.RemoveAll(server.FolderPath); err != nil {
+			fileErrors = append(fileErrors, fmt.Sprintf("%s: %v", server.FolderPath, err))
+		}
+	}
+
+	details := "record only"
+	if deleteFiles {
+		details = "record, files and backups"
+	}
+	models.CreateAuditLog(userID, server.ID, "server.delete", models.AuditSourceSession, len(fileErrors) == 0, details, middleware.ClientIP(r))
+	log.Printf("✅ Server deleted: %s (%s)", server.Name, details)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":           true,
+		"message":           "Server deleted successfully",
+		"deleted_files":     deleteFiles,
+		"deleted_schedules": len(schedules),
+		"deleted_backups":   len(backups),
+		"errors":            fileErrors,
+	})
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ExportServerConfig downloads a server's panel configuration - startup settings, schedules and
+// backup settings - as a JSON bundle another panel can import. Server files are not included.
+func ExportServerConfig(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	bundle, err := services.NewServerConfigBundle(server)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to export configuration: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		http.Error(w, "Failed to export configuration", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-config.json\"", server.Name))
+	w.Write(data)
+}
+
+// ImportServerConfig recreates a server from an exported configuration bundle, posted as the
+// "file" upload or the "bundle" field, for an existing folder given as "path" - AJAX JSON
+// response. "name" overrides the bundled name. Conflicts with existing servers are all reported
+// at once; with dry_run=true nothing is created. Settings the new folder can't satisfy, such
+// as a missing working directory, are skipped and reported as warnings.
+func ImportServerConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	data, err := readServerConfigBundle(w, r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	bundle, err := services.ParseServerConfigBundle(data)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	folderPath, err := resolveImportPath(r.FormValue("path"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    ErrCodeInvalidRequest,
+		})
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		name = bundle.Server.Name
+	}
+
+	// Collect every conflict so they can all be resolved before retrying
+	conflicts := make([]string, 0)
+	if err := checkServerLimit(userID); err != nil {
+		conflicts = append(conflicts, err.Error())
+	}
+	if !serverNamePattern.MatchString(name) || isIgnoredServerFolder(name) {
+		conflicts = append(conflicts, "invalid server name: use letters, digits, dots, dashes and underscores")
+	} else if _, err := models.GetServerByNameAnyUser(name); err == nil {
+		conflicts = append(conflicts, "a server named "+name+" already exists")
+	}
+	if err := checkImportOverlap(folderPath); err != nil {
+		conflicts = append(conflicts, err.Error())
+	}
+	if len(conflicts) > 0 {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   false,
+			"error":     "The configuration can't be imported: " + strings.Join(conflicts, "; "),
+			"code":      ErrCodeConflict,
+			"conflicts": conflicts,
+		})
+		return
+	}
+
+	if r.FormValue("dry_run") == "true" {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"dry_run":   true,
+			"name":      name,
+			"path":      folderPath,
+			"schedules": len(bundle.Schedules),
+			"conflicts": conflicts,
+		})
+		return
+	}
+
+	server, err := models.CreateServer(name, folderPath, bundle.Server.StartupCommand, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to create server",
+			"code":    ErrCodeInternal,
+		})
+		return
+	}
+
+	warnings := applyServerConfigBundle(r, server, bundle)
+
+	// Schedules are restored like those of a backup manifest
+	restored := 0
+	for _, saved := range bundle.Schedules {
+		schedule, err := restoreManifestSchedule(server.ID, saved, true)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("schedule %q: %v", saved.Name, err))
+			continue
+		}
+		if schedule.Enabled {
+			if scheduleService := services.GetScheduleService(); scheduleService != nil {
+				if err := scheduleService.AddSchedule(*schedule); err != nil {
+					log.Printf("⚠️  Imported schedule '%s' could not be scheduled: %v", schedule.Name, err)
+				}
+			}
+		}
+		restored++
+	}
+
+	models.CreateAuditLog(userID, server.ID, "server.import_config", models.AuditSourceSession, len(warnings) == 0, folderPath, middleware.ClientIP(r))
+	log.Printf("✅ Server imported from configuration bundle: %s (%s, %d warning(s))", server.Name, server.FolderPath, len(warnings))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"message":   fmt.Sprintf("Server imported with %d schedule(s)", restored),
+		"server":    server,
+		"schedules": restored,
+		"warnings":  warnings,
+	})
+}
+
+// readServerConfigBundle returns the bundle posted as an upload or form field
+func readServerConfigBundle(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxServerConfigBundleSize)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(services.MaxServerConfigBundleSize); err != nil {
+			return nil, errors.New("error parsing form")
+		}
+		if file, _, err := r.FormFile("file"); err == nil {
+			defer file.Close()
+			data, err := io.ReadAll(file)
+			if err != nil {
+				return nil, errors.New("failed to read uploaded bundle")
+			}
+			return data, nil
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return nil, errors.New("error parsing form")
+	}
+
+	bundle := r.FormValue("bundle")
+	if strings.TrimSpace(bundle) == "" {
+		return nil, errors.New("no configuration bundle provided")
+	}
+	return []byte(bundle), nil
+}
+
+// applyServerConfigBundle applies a bundle's settings to a newly created server through the
+// same validation as editing them, returning a warning for each setting that was skipped
+func applyServerConfigBundle(r *http.Request, server *models.Server, bundle *services.ServerConfigBundle) []string {
+	settings := bundle.Server
+	warnings := make([]string, 0)
+	apply := func(setting string, err error) {
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", setting, err))
+		}
+	}
+
+	apply("launch options", server.UpdateLaunchOptions(settings.WorkingDir, settings.ExtraArgs))
+	apply("reload command", server.UpdateReloadCommand(settings.ReloadCommand))
+	apply("file root", server.UpdateFileRoot(settings.FileRoot))
+	apply("file modes", server.UpdateFileModes(settings.FileMode, settings.DirMode))
+	apply("tags", server.UpdateTags(settings.Tags))
+	apply("log format", server.UpdateLogFormat(settings.LogPattern, settings.LogTimeLayout))
+	apply("console policy", server.UpdateConsolePolicy(settings.ConsoleAllowed, settings.ConsoleBlocked))
+	apply("protected paths", server.UpdateProtectedPaths(settings.ProtectedPaths))
+	apply("auto-start", server.UpdateAutoStart(settings.AutoStartOnBoot))
+	if settings.AlertDuration > 0 {
+		apply("alerts", server.UpdateAlertSettings(settings.AlertCPUPercent, settings.AlertMemPercent, settings.AlertDuration))
+	}
+
+	// The backup folder may not exist on this host; fall back to the default one
+	backupPath := services.BundleBackupPath(bundle, server.FolderPath)
+	if backupPath != "" {
+		if err := services.ValidateBackupPath(r.Context(), backupPath, server.FolderPath); err != nil {
+			apply("backup path", err)
+			backupPath = ""
+		}
+	}
+	backups := settings.Backups
+	apply("backup settings", server.UpdateBackupSettings(backupPath, backups.MaxBackups, backups.WrapInFolder, backups.AutoBackupOnStop))
+
+	return warnings
+}
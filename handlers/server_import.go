@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"seiapanel/config"
+	"seiapanel/middleware"
+	"seiapanel/models"
+)
+
+// DetectImportSettings reports what an import of an existing folder would use, so the
+// import form can be pre-filled - AJAX JSON response
+func DetectImportSettings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	folderPath, err := resolveImportPath(r.URL.Query().Get("path"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":         true,
+		"path":            folderPath,
+		"name":            filepath.Base(folderPath),
+		"startup_command": findStartupCommand(folderPath),
+	})
+}
+
+// ImportServer adopts an existing folder under the server root as a new server, without
+// copying or modifying its files - AJAX JSON response. The startup command falls back to
+// a detected startup script or jar.
+func ImportServer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID := middleware.GetUserID(r)
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return
+	}
+
+	if err := checkServerLimit(userID); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	folderPath, err := resolveImportPath(r.FormValue("path"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// The name defaults to the folder name
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		name = filepath.Base(folderPath)
+	}
+	if !serverNamePattern.MatchString(name) || isIgnoredServerFolder(name) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid server name: use letters, digits, dots, dashes and underscores",
+		})
+		return
+	}
+
+	if _, err := models.GetServerByNameAnyUser(name); err == nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "A server with this name already exists",
+		})
+		return
+	}
+
+	if err := checkImportOverlap(folderPath); err != nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	startupCommand := strings.TrimSpace(r.FormValue("startup_command"))
+	detected := false
+	if startupCommand == "" {
+		startupCommand = findStartupCommand(folderPath)
+		detected = startupCommand != ""
+	}
+	if startupCommand == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "No startup script or server jar found, please provide a startup command",
+		})
+		return
+	}
+
+	server, err := models.CreateServer(name, folderPath, startupCommand, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to import server",
+		})
+		return
+	}
+
+	models.CreateAuditLog(userID, server.ID, "server.import", models.AuditSourceSession, true, folderPath, middleware.ClientIP(r))
+	log.Printf("✅ Server imported: %s (%s)", server.Name, server.FolderPath)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":          true,
+		"message":          "Server imported successfully",
+		"server":           server,
+		"startup_detected": detected,
+	})
+}
+
+// resolveImportPath turns a folder given relative to the server root (or as an absolute path
+// inside it) into a clean absolute path, checking it is an existing directory under the root
+func resolveImportPath(input string) (string, error) {
+	serverPath := config.GetServerPath()
+	if serverPath == "" {
+		return "", errors.New("server folder path is not configured")
+	}
+
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", errors.New("folder path is required")
+	}
+
+	root := filepath.Clean(serverPath)
+	folderPath := filepath.Clean(input)
+	if !filepath.IsAbs(folderPath) {
+		folderPath = filepath.Join(root, folderPath)
+	}
+
+	rel, err := filepath.Rel(root, folderPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("folder must be inside the server root")
+	}
+
+	// Symlinks could point the server outside the root
+	resolved, err := filepath.EvalSymlinks(folderPath)
+	if err != nil {
+		return "", errors.New("folder does not exist")
+	}
+	if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+		if rel, err := filepath.Rel(resolvedRoot, resolved); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return "", errors.New("folder must be inside the server root")
+		}
+	}
+
+	info, err := os.Stat(folderPath)
+	if err != nil || !info.IsDir() {
+		return "", errors.New("folder does not exist")
+	}
+
+	return folderPath, nil
+}
+
+// checkImportOverlap rejects folders that already belong to a server, contain one, or are
+// used as a backup folder
+func checkImportOverlap(folderPath string) error {
+	servers, err := models.GetAllServers()
+	if err != nil {
+		return errors.New("failed to check existing servers")
+	}
+
+	for _, server := range servers {
+		existing := filepath.Clean(server.FolderPath)
+		if existing == folderPath {
+			return errors.New("a server already uses this folder")
+		}
+		if isSubPath(folderPath, existing) {
+			return errors.New("folder is inside the folder of server " + server.Name)
+		}
+		if isSubPath(existing, folderPath) {
+			return errors.New("folder contains the folder of server " + server.Name)
+		}
+		if server.BackupPath != "" && filepath.Clean(server.BackupPath) == folderPath {
+			return errors.New("folder is the backup folder of server " + server.Name)
+		}
+	}
+	return nil
+}
+
+// isSubPath reports whether p is strictly inside root
+func isSubPath(p, root string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
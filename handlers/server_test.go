@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"seiapanel/config"
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// createServerRequest posts the create server form as user 1
+func createServerRequest(form url.Values) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/servers/create", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, uint(1)))
+	w := httptest.NewRecorder()
+	CreateServer(w, r)
+	return w
+}
+
+// TestCreateServerRejectedLeavesNoFolder checks that a request rejected for its name or
+// startup command doesn't leave a folder behind
+func TestCreateServerRejectedLeavesNoFolder(t *testing.T) {
+	setupTestDB(t)
+	root := t.TempDir()
+	useTestConfig(t, &config.Config{ServerFolderPath: root})
+
+	tests := []struct {
+		name           string
+		startupCommand string
+	}{
+		{"survival", ""},
+		{"bad name", "java -jar server.jar"},
+		{"-leading-dash", "java -jar server.jar"},
+		{"..", "java -jar server.jar"},
+	}
+	for _, test := range tests {
+		w := createServerRequest(url.Values{"name": {test.name}, "startup_command": {test.startupCommand}})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("CreateServer(%q, %q) status = %d, want %d", test.name, test.startupCommand, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("rejected requests left %d entries in the server root", len(entries))
+	}
+
+	w := createServerRequest(url.Values{"name": {"survival"}, "startup_command": {"java -jar server.jar"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("valid CreateServer status = %d (%s)", w.Code, w.Body)
+	}
+	if _, err := os.Stat(filepath.Join(root, "survival")); err != nil {
+		t.Errorf("valid CreateServer did not create the folder: %v", err)
+	}
+}
+
+// TestDeleteServerRejectedDuringBackup checks that a server can't be deleted while a backup
+// of it is running, and that nothing is removed
+func TestDeleteServerRejectedDuringBackup(t *testing.T) {
+	setupTestDB(t)
+	root := t.TempDir()
+	useTestConfig(t, &config.Config{ServerFolderPath: root, IncludeBackupDirs: true})
+
+	folder := filepath.Join(root, "survival")
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatal(err)
+	}
+	server := &models.Server{Name: "survival", FolderPath: folder, StartupCommand: "java -jar server.jar", UserID: 1}
+	if err := models.DB.Create(server).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := services.BeginServerOperation(server.ID, "backup"); err != nil {
+		t.Fatal(err)
+	}
+	form := url.Values{"confirm": {server.Name}, "delete_files": {"true"}}
+	r := httptest.NewRequest(http.MethodPost, "/server/survival/delete", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = mux.SetURLVars(r, map[string]string{"name": server.Name})
+	r = r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, server.UserID))
+	w := httptest.NewRecorder()
+	DeleteServer(w, r)
+	services.EndServerOperation(server.ID)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("delete during backup: status = %d, want %d (%s)", w.Code, http.StatusConflict, w.Body)
+	}
+	if _, err := os.Stat(folder); err != nil {
+		t.Errorf("delete during backup removed the server folder: %v", err)
+	}
+	if _, err := models.GetServerByName(server.Name, server.UserID); err != nil {
+		t.Errorf("delete during backup removed the server record: %v", err)
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"html/template"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"seiapanel/config"
 	"seiapanel/middleware"
 	"seiapanel/models"
+	"seiapanel/services"
 )
 
 // SettingsPage renders the settings page
@@ -20,7 +24,7 @@ func SettingsPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, _ := config.GetSessionStore().Get(r, "auth-session")
+	session, _ := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 
 	tmpl, err := template.ParseFiles("templates/settings.html")
 	if err != nil {
@@ -28,11 +32,25 @@ func SettingsPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	runKeep, runMaxAge := config.GetScheduleRunRetention()
+
+	// Tell the operator up front when file operations will be refused by the OS
+	rootAccessError := ""
+	if path := config.GetServerPath(); path != "" {
+		if err := checkFolderAccess(path); err != nil {
+			rootAccessError = err.Error()
+		}
+	}
+
 	data := map[string]interface{}{
-		"User":        user,
-		"CurrentPath": config.GetServerPath(),
-		"Success":     session.Flashes("success"),
-		"Error":       session.Flashes("error"),
+		"User":            user,
+		"CurrentPath":     config.GetServerPath(),
+		"RootAccessError": rootAccessError,
+		"WebhookURL":      config.GetNotifyWebhookURL(),
+		"RunKeep":         runKeep,
+		"RunMaxAge":       runMaxAge,
+		"Success":         session.Flashes("success"),
+		"Error":           session.Flashes("error"),
 	}
 	session.Save(r, w)
 
@@ -105,10 +123,170 @@ func UpdateServerPath(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response := map[string]interface{}{
+		"success":  true,
+		"message":  "Server folder path updated successfully",
+		"path":     path,
+		"writable": true,
+	}
+	if err := checkFolderAccess(path); err != nil {
+		response["writable"] = false
+		response["warning"] = err.Error()
+	}
+
 	// Return success response
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateNotificationSettings updates the notification webhook URL - AJAX JSON response
+func UpdateNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return
+	}
+
+	webhookURL := strings.TrimSpace(r.FormValue("webhook_url"))
+
+	// Empty URL disables notifications
+	if webhookURL != "" && !strings.HasPrefix(webhookURL, "http://") && !strings.HasPrefix(webhookURL, "https://") {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Webhook URL must start with http:// or https://",
+		})
+		return
+	}
+
+	// Optionally verify the webhook before saving it
+	if webhookURL != "" && r.FormValue("test") == "true" {
+		if err := services.SendTestNotification(webhookURL); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Test notification failed: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	if err := config.UpdateNotifyWebhookURL(webhookURL); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error updating notification settings: " + err.Error(),
+		})
+		return
+	}
+
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
-		"message": "Server folder path updated successfully",
-		"path":    path,
+		"message": "Notification settings updated successfully",
+	})
+}
+
+// TestNotification sends a sample notification to the saved webhook and reports the delivery
+// result - AJAX JSON response. Only the saved URL is tested, so the endpoint can't be used to
+// probe arbitrary addresses; save a new URL before testing it.
+func TestNotification(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	webhookURL := config.GetNotifyWebhookURL()
+	if webhookURL == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "No notification webhook is configured",
+		})
+		return
+	}
+	if !strings.HasPrefix(webhookURL, "http://") && !strings.HasPrefix(webhookURL, "https://") {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Webhook URL must start with http:// or https://",
+		})
+		return
+	}
+
+	started := time.Now()
+	statusCode, err := services.DeliverTestNotification(webhookURL)
+	result := map[string]interface{}{
+		"success":     err == nil,
+		"target":      redactURL(webhookURL),
+		"status_code": statusCode,
+		"duration_ms": time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		result["error"] = "Test notification failed: " + err.Error()
+		w.WriteHeader(http.StatusBadGateway)
+	} else {
+		result["message"] = "Test notification delivered"
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// UpdateHistoryRetention updates how long schedule run history is kept - AJAX JSON response
+func UpdateHistoryRetention(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse form data
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error parsing form",
+		})
+		return
+	}
+
+	keep, err := strconv.Atoi(r.FormValue("keep_runs"))
+	if err != nil || keep < 1 || keep > 1000 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Runs to keep must be between 1 and 1000",
+		})
+		return
+	}
+
+	// Empty max age means no age limit
+	maxAgeDays := 0
+	if value := strings.TrimSpace(r.FormValue("max_age_days")); value != "" {
+		maxAgeDays, err = strconv.Atoi(value)
+		if err != nil || maxAgeDays < 0 || maxAgeDays > 3650 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   "Maximum age must be between 0 and 3650 days",
+			})
+			return
+		}
+	}
+
+	if err := config.UpdateScheduleRunRetention(keep, maxAgeDays); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Error updating history retention: " + err.Error(),
+		})
+		return
+	}
+
+	// Apply the new retention right away rather than at the next hourly cleanup
+	go services.PruneScheduleRunHistory()
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":      true,
+		"message":      "History retention updated successfully",
+		"keep_runs":    keep,
+		"max_age_days": maxAgeDays,
 	})
-}
\ No newline at end of file
+}
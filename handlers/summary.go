@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// Summary defaults and limits
+const (
+	summaryDefaultLogLines = 50
+	summaryMaxLogLines     = 500
+	summaryBackupCount     = 3
+)
+
+// summarySchedule is an enabled schedule with its next fire time
+type summarySchedule struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Action    string     `json:"action"`
+	NextRun   *time.Time `json:"next_run"`
+	LastRunAt *time.Time `json:"last_run_at"`
+}
+
+// GetServerSummary returns a one-shot snapshot of a server: status and resource usage, the
+// tail of its console log, its latest backups and its enabled schedules. The parts are
+// gathered concurrently; a part that fails is reported in "errors" instead of failing the
+// whole summary.
+func GetServerSummary(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	logLines := summaryDefaultLogLines
+	if value := r.URL.Query().Get("lines"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			logLines = min(n, summaryMaxLogLines)
+		}
+	}
+
+	var (
+		wg        sync.WaitGroup
+		errMu     sync.Mutex
+		errs      = make(map[string]string)
+		stats     *services.ServerStats
+		logs      []string
+		backups   []models.Backup
+		schedules = make([]summarySchedule, 0)
+	)
+	fail := func(part string, err error) {
+		errMu.Lock()
+		errs[part] = err.Error()
+		errMu.Unlock()
+	}
+
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		s, err := services.GetServerStats(server)
+		if err != nil {
+			fail("stats", err)
+			return
+		}
+		stats = s
+	}()
+	go func() {
+		defer wg.Done()
+		all := services.GetLogs(server)
+		logs = all[max(0, len(all)-logLines):]
+	}()
+	go func() {
+		defer wg.Done()
+		all, err := models.GetBackupsByServerID(server.ID)
+		if err != nil {
+			fail("backups", err)
+			return
+		}
+		backups = all[:min(len(all), summaryBackupCount)]
+	}()
+	go func() {
+		defer wg.Done()
+		all, err := models.GetSchedulesByServerID(server.ID)
+		if err != nil {
+			fail("schedules", err)
+			return
+		}
+
+		nextRuns := make(map[uint]*time.Time)
+		if scheduleService := services.GetScheduleService(); scheduleService != nil {
+			for _, entry := range scheduleService.GetScheduleEntries(all) {
+				nextRuns[entry.ScheduleID] = entry.NextRun
+			}
+		}
+
+		for _, schedule := range all {
+			if !schedule.Enabled {
+				continue
+			}
+			schedules = append(schedules, summarySchedule{
+				ID:        schedule.ID,
+				Name:      schedule.Name,
+				Action:    schedule.Action,
+				NextRun:   nextRuns[schedule.ID],
+				LastRunAt: schedule.LastRunAt,
+			})
+		}
+	}()
+	wg.Wait()
+
+	status := "offline"
+	if stats != nil {
+		status = stats.State
+	}
+	if backups == nil {
+		backups = []models.Backup{}
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"name":      server.Name,
+		"status":    status,
+		"uptime":    server.FormatUptime(),
+		"stats":     stats,
+		"logs":      logs,
+		"backups":   backups,
+		"schedules": schedules,
+	}
+	if len(errs) > 0 {
+		response["errors"] = errs
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"seiapanel/middleware"
+	"seiapanel/models"
+	"seiapanel/services"
+
+	"github.com/gorilla/mux"
+)
+
+// Trigger requests must be signed within this window, and each signature is accepted only
+// once while it is inside it, so a captured request can't be replayed
+const triggerMaxSkew = 5 * time.Minute
+
+// triggerSignatures maps each accepted signature to when its timestamp leaves the window
+var (
+	triggerSignatures    = make(map[string]time.Time)
+	triggerSignaturesMux sync.Mutex
+)
+
+// triggerLimiter limits signed webhook triggers per server
+var triggerLimiter = middleware.NewRateLimiter(10, time.Minute)
+
+// triggerFailLimiter limits failed webhook triggers per client IP, so guessing signatures
+// is throttled without unsigned requests using up a server's triggers
+var triggerFailLimiter = middleware.NewRateLimiter(10, time.Minute)
+
+// RegenerateTriggerSecret creates a new webhook trigger secret for a server.
+// The secret is only returned once, in this response.
+func RegenerateTriggerSecret(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to generate secret",
+		})
+		return
+	}
+	secret := hex.EncodeToString(b)
+
+	if err := server.UpdateTriggerSecret(secret); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to save secret",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Trigger secret generated. Store it now, it won't be shown again.",
+		"secret":  secret,
+	})
+}
+
+// DisableTrigger removes the webhook trigger secret, disabling triggers for a server
+func DisableTrigger(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	userID := middleware.GetUserID(r)
+
+	server, err := models.GetServerByName(serverName, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Server not found",
+		})
+		return
+	}
+
+	if err := server.UpdateTriggerSecret(""); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to disable triggers",
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Webhook triggers disabled",
+	})
+}
+
+// TriggerServerAction starts, stops or restarts a server from a signed webhook.
+// Callers sign "<X-Seia-Timestamp>.<body>" with HMAC-SHA256 using the server's trigger
+// secret and send the hex digest as "X-Seia-Signature: sha256=<digest>".
+func TriggerServerAction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	serverName := vars["name"]
+	action := vars["action"]
+	clientIP := middleware.ClientIP(r)
+
+	// Clients that keep failing are turned away before any work is done
+	if triggerFailLimiter.Exceeded(clientIP) {
+		writeTriggerRateLimited(w)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64*1024))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Failed to read request body",
+		})
+		return
+	}
+
+	// Unknown servers and servers without a secret get the same response as a bad signature
+	server, err := models.GetServerByNameAnyUser(serverName)
+	if err != nil || server.TriggerSecret == "" || !validTriggerSignature(r, body, server.TriggerSecret) {
+		triggerFailLimiter.Record(clientIP)
+		if server != nil && server.TriggerSecret != "" {
+			models.CreateAuditLog(server.UserID, server.ID, "server."+action, models.AuditSourceWebhook, false, "invalid signature", clientIP)
+		}
+		log.Printf("⚠️  Rejected webhook trigger %s for %s from %s", action, serverName, clientIP)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Invalid signature",
+		})
+		return
+	}
+
+	// A signature already acted on is a replay, even while its timestamp is still valid
+	if !claimTriggerSignature(r, server.ID) {
+		triggerFailLimiter.Record(clientIP)
+		models.CreateAuditLog(server.UserID, server.ID, "server."+action, models.AuditSourceWebhook, false, "replayed signature", clientIP)
+		log.Printf("⚠️  Rejected replayed webhook trigger %s for %s from %s", action, serverName, clientIP)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Signature already used",
+		})
+		return
+	}
+
+	// Only signed requests count toward the server's limit
+	if !triggerLimiter.Allow("server:" + server.Name) {
+		writeTriggerRateLimited(w)
+		return
+	}
+
+	switch action {
+	case "start":
+		err = services.StartServer(server)
+	case "stop":
+		if !services.IsServerRunning(server) {
+			err = fmt.Errorf("server is not running")
+		} else {
+			err = services.StopServer(server)
+		}
+	case "restart":
+		if !services.IsServerRunning(server) {
+			err = fmt.Errorf("server is not running")
+		} else {
+			err = services.RestartServer(server)
+		}
+	}
+
+	details := ""
+	if err != nil {
+		details = err.Error()
+	}
+	models.CreateAuditLog(server.UserID, server.ID, "server."+action, models.AuditSourceWebhook, err == nil, details, clientIP)
+
+	if err != nil {
+		log.Printf("❌ Webhook trigger %s failed for %s: %v", action, server.Name, err)
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	log.Printf("✅ Webhook trigger %s executed for %s", action, server.Name)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Server %s triggered successfully", action),
+	})
+}
+
+// writeTriggerRateLimited responds that too many trigger requests were made
+func writeTriggerRateLimited(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   "Too many trigger requests, try again later",
+	})
+}
+
+// validTriggerSignature checks the request's timestamp and HMAC signature against the secret
+func validTriggerSignature(r *http.Request, body []byte, secret string) bool {
+	timestampStr := r.Header.Get("X-Seia-Timestamp")
+	timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	skew := time.Since(time.Unix(timestamp, 0))
+	if skew > triggerMaxSkew || skew < -triggerMaxSkew {
+		return false
+	}
+
+	signature := strings.TrimPrefix(r.Header.Get("X-Seia-Signature"), "sha256=")
+	provided, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestampStr + "."))
+	mac.Write(body)
+
+	return hmac.Equal(provided, mac.Sum(nil))
+}
+
+// claimTriggerSignature records the signature of a validated request, returning false when it
+// was already accepted for the server. Signatures are forgotten once their timestamp leaves
+// the window, since the request would be rejected as too old by then.
+func claimTriggerSignature(r *http.Request, serverID uint) bool {
+	timestamp, err := strconv.ParseInt(r.Header.Get("X-Seia-Timestamp"), 10, 64)
+	if err != nil {
+		return false
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(r.Header.Get("X-Seia-Signature"), "sha256="))
+	if err != nil {
+		return false
+	}
+	key := fmt.Sprintf("%d:%x", serverID, signature)
+
+	triggerSignaturesMux.Lock()
+	defer triggerSignaturesMux.Unlock()
+
+	now := time.Now()
+	for seen, expires := range triggerSignatures {
+		if now.After(expires) {
+			delete(triggerSignatures, seen)
+		}
+	}
+
+	if _, used := triggerSignatures[key]; used {
+		return false
+	}
+	triggerSignatures[key] = time.Unix(timestamp, 0).Add(triggerMaxSkew)
+	return true
+}
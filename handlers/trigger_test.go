@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"seiapanel/models"
+
+	"github.com/gorilla/mux"
+)
+
+// TestTriggerRejectsReplay checks that a signed trigger is acted on once, and that sending
+// the same request again inside the timestamp window is refused
+func TestTriggerRejectsReplay(t *testing.T) {
+	setupTestDB(t)
+	if err := models.DB.AutoMigrate(&models.AuditLog{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		triggerSignaturesMux.Lock()
+		triggerSignatures = make(map[string]time.Time)
+		triggerSignaturesMux.Unlock()
+	})
+
+	secret := "trigger-secret"
+	server := &models.Server{Name: "survival", FolderPath: t.TempDir(), StartupCommand: "java -jar server.jar", TriggerSecret: secret, UserID: 1}
+	if err := models.DB.Create(server).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"reason":"nightly"}`
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + body))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	trigger := func(signature string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/server/survival/trigger/stop", strings.NewReader(body))
+		r.Header.Set("X-Seia-Timestamp", timestamp)
+		r.Header.Set("X-Seia-Signature", signature)
+		r = mux.SetURLVars(r, map[string]string{"name": server.Name, "action": "stop"})
+		w := httptest.NewRecorder()
+		TriggerServerAction(w, r)
+		return w
+	}
+
+	// The server isn't running, so the first request is accepted but can't stop it
+	if w := trigger(signature); w.Code != http.StatusConflict {
+		t.Fatalf("first trigger: status = %d, want %d (%s)", w.Code, http.StatusConflict, w.Body)
+	}
+	if w := trigger(signature); w.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed trigger: status = %d, want %d (%s)", w.Code, http.StatusUnauthorized, w.Body)
+	}
+	if w := trigger(strings.ToUpper(signature[len("sha256="):])); w.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed trigger in upper case: status = %d, want %d (%s)", w.Code, http.StatusUnauthorized, w.Body)
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"seiapanel/config"
+	"seiapanel/services"
+)
+
+// GetVersion returns the running panel version, optionally checking for a newer release
+func GetVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	response := map[string]interface{}{
+		"success":    true,
+		"version":    config.Version,
+		"commit":     config.Commit,
+		"build_date": config.BuildDate,
+	}
+
+	// Update check is opt-in per request since it contacts an external URL
+	if r.URL.Query().Get("check") == "true" {
+		update, err := services.CheckForUpdate()
+		if err != nil {
+			response["update_error"] = err.Error()
+		} else {
+			response["update"] = update
+		}
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -22,6 +22,25 @@ func main() {
 	// Initialize schedule service
 	services.InitScheduler()
 
+	// Start the servers flagged to run whenever the panel does
+	services.StartAutoStartServers()
+
+	// Remove backup mounts left over from a previous run
+	services.CleanupStaleBackupMounts()
+
+	// Remove file journal copies no journaled operation refers to
+	services.CleanupStaleFileJournal()
+
+	// Start resource alert monitor
+	services.StartResourceMonitor()
+
+	// Start free disk space monitor
+	services.StartDiskMonitor()
+
+	// Allow as many open files as permitted and watch how many are used
+	services.RaiseFileLimit()
+	services.StartFDMonitor()
+
 	// Create router
 	r := mux.NewRouter()
 
@@ -33,6 +52,15 @@ func main() {
 	r.HandleFunc("/login", handlers.Login).Methods("POST")
 	r.HandleFunc("/register", handlers.RegisterPage).Methods("GET")
 	r.HandleFunc("/register", handlers.Register).Methods("POST")
+	r.HandleFunc("/api/health", handlers.Health).Methods("GET")
+
+	// Webhook triggers (authenticated by HMAC signature instead of session)
+	r.HandleFunc("/server/{name}/trigger/{action:start|stop|restart}", handlers.TriggerServerAction).Methods("POST")
+
+	// JSON API (authenticated by API token or session)
+	api := r.PathPrefix("/api/servers").Subrouter()
+	api.Use(middleware.APIAuthMiddleware)
+	api.HandleFunc("/overview", handlers.ServersOverview).Methods("GET")
 
 	// Protected routes (authentication required)
 	protected := r.PathPrefix("/").Subrouter()
@@ -49,34 +77,86 @@ func main() {
 	// Resource monitoring
 	protected.HandleFunc("/resource", handlers.ResourcePage).Methods("GET")
 	protected.HandleFunc("/api/system/stats", handlers.GetSystemStats).Methods("GET")
+	protected.HandleFunc("/api/version", handlers.GetVersion).Methods("GET")
+	protected.HandleFunc("/api/config", handlers.GetPanelConfig).Methods("GET")
+	protected.HandleFunc("/api/schedules/pause", handlers.PauseSchedules).Methods("POST")
+	protected.HandleFunc("/api/schedules/resume", handlers.ResumeSchedules).Methods("POST")
+	protected.HandleFunc("/api/schedules/upcoming", handlers.GetUpcomingRuns).Methods("GET")
+	protected.HandleFunc("/api/scheduler/restart", handlers.RestartScheduler).Methods("POST")
+
+	// API tokens
+	protected.HandleFunc("/api/tokens", handlers.ListAPITokens).Methods("GET")
+	protected.HandleFunc("/api/tokens", handlers.CreateAPIToken).Methods("POST")
+	protected.HandleFunc("/api/tokens/{id}", handlers.DeleteAPIToken).Methods("DELETE")
+
+	// File templates
+	protected.HandleFunc("/api/file-templates", handlers.ListFileTemplates).Methods("GET")
+	protected.HandleFunc("/api/file-templates", handlers.CreateFileTemplate).Methods("POST")
+	protected.HandleFunc("/api/file-templates/{id}", handlers.UpdateFileTemplate).Methods("POST")
+	protected.HandleFunc("/api/file-templates/{id}", handlers.DeleteFileTemplate).Methods("DELETE")
 
 	// Settings
 	protected.HandleFunc("/settings", handlers.SettingsPage).Methods("GET")
 	protected.HandleFunc("/settings/update-path", handlers.UpdateServerPath).Methods("POST")
+	protected.HandleFunc("/settings/update-notifications", handlers.UpdateNotificationSettings).Methods("POST")
+	protected.HandleFunc("/notifications/test", handlers.TestNotification).Methods("POST")
+	protected.HandleFunc("/settings/update-history-retention", handlers.UpdateHistoryRetention).Methods("POST")
 
 	// Server management
+	protected.HandleFunc("/servers/create", handlers.CreateServer).Methods("POST")
+	protected.HandleFunc("/servers/import", handlers.ImportServer).Methods("POST")
+	protected.HandleFunc("/servers/import/detect", handlers.DetectImportSettings).Methods("GET")
+	protected.HandleFunc("/servers/import-config", handlers.ImportServerConfig).Methods("POST")
 	protected.HandleFunc("/server/{name}", handlers.ServerConsolePage).Methods("GET")
+	protected.HandleFunc("/server/{name}/delete", handlers.DeleteServer).Methods("POST")
 	protected.HandleFunc("/server/{name}/start", handlers.StartServer).Methods("POST")
 	protected.HandleFunc("/server/{name}/stop", handlers.StopServer).Methods("POST")
 	protected.HandleFunc("/server/{name}/restart", handlers.RestartServer).Methods("POST")
+	protected.HandleFunc("/server/{name}/reload", handlers.ReloadServer).Methods("POST")
 	protected.HandleFunc("/server/{name}/command", handlers.SendCommand).Methods("POST")
 	protected.HandleFunc("/server/{name}/logs", handlers.GetLogs).Methods("GET")
+	protected.HandleFunc("/server/{name}/logs/download", handlers.DownloadLogs).Methods("GET")
 	protected.HandleFunc("/server/{name}/stats", handlers.GetServerStats).Methods("GET")
+	protected.HandleFunc("/server/{name}/summary", handlers.GetServerSummary).Methods("GET")
+	protected.HandleFunc("/server/{name}/export-config", handlers.ExportServerConfig).Methods("GET")
 	protected.HandleFunc("/server/{name}/ws", handlers.ConsoleWebSocket).Methods("GET")
 
 	// Startup management
 	protected.HandleFunc("/server/{name}/startup", handlers.StartupPage).Methods("GET")
 	protected.HandleFunc("/server/{name}/startup/update", handlers.UpdateStartup).Methods("POST")
+	protected.HandleFunc("/server/{name}/tags", handlers.UpdateServerTags).Methods("POST")
+	protected.HandleFunc("/server/{name}/rename", handlers.RenameServer).Methods("POST")
+	protected.HandleFunc("/server/{name}/file-root", handlers.UpdateFileRoot).Methods("POST")
+	protected.HandleFunc("/server/{name}/file-modes", handlers.UpdateFileModes).Methods("POST")
+	protected.HandleFunc("/server/{name}/console-policy", handlers.UpdateConsolePolicy).Methods("POST")
+	protected.HandleFunc("/server/{name}/protected-paths", handlers.UpdateProtectedPaths).Methods("POST")
+	protected.HandleFunc("/server/{name}/audit", handlers.ListAuditLogs).Methods("GET")
+	protected.HandleFunc("/server/{name}/alerts", handlers.UpdateAlertSettings).Methods("POST")
+	protected.HandleFunc("/server/{name}/trigger/secret", handlers.RegenerateTriggerSecret).Methods("POST")
+	protected.HandleFunc("/server/{name}/trigger/secret", handlers.DisableTrigger).Methods("DELETE")
 
 	// Schedule management
 	protected.HandleFunc("/server/{name}/schedule", handlers.SchedulePage).Methods("GET")
 	protected.HandleFunc("/server/{name}/schedule/list", handlers.ListSchedules).Methods("GET")
 	protected.HandleFunc("/server/{name}/schedule/create", handlers.CreateSchedule).Methods("POST")
+	protected.HandleFunc("/server/{name}/schedule/entries", handlers.GetScheduleEntries).Methods("GET")
+	protected.HandleFunc("/server/{name}/schedule/reconcile", handlers.ReconcileSchedules).Methods("POST")
+	protected.HandleFunc("/server/{name}/schedule/preview", handlers.PreviewSchedule).Methods("GET", "POST")
 	protected.HandleFunc("/server/{name}/schedule/{id}", handlers.GetSchedule).Methods("GET")
 	protected.HandleFunc("/server/{name}/schedule/{id}/update", handlers.UpdateSchedule).Methods("POST")
 	protected.HandleFunc("/server/{name}/schedule/{id}/delete", handlers.DeleteSchedule).Methods("DELETE")
 	protected.HandleFunc("/server/{name}/schedule/{id}/toggle", handlers.ToggleSchedule).Methods("POST")
+	protected.HandleFunc("/server/{name}/schedule/{id}/duplicate", handlers.DuplicateSchedule).Methods("POST")
 	protected.HandleFunc("/server/{name}/schedule/{id}/execute", handlers.ExecuteSchedule).Methods("POST")
+	protected.HandleFunc("/server/{name}/schedule/{id}/runs", handlers.GetScheduleRuns).Methods("GET")
+
+	// Command macros
+	protected.HandleFunc("/server/{name}/macros/list", handlers.ListCommandMacros).Methods("GET")
+	protected.HandleFunc("/server/{name}/macros/create", handlers.CreateCommandMacro).Methods("POST")
+	protected.HandleFunc("/server/{name}/macros/{id}", handlers.GetCommandMacro).Methods("GET")
+	protected.HandleFunc("/server/{name}/macros/{id}/update", handlers.UpdateCommandMacro).Methods("POST")
+	protected.HandleFunc("/server/{name}/macros/{id}/delete", handlers.DeleteCommandMacro).Methods("DELETE")
+	protected.HandleFunc("/server/{name}/macros/{id}/execute", handlers.ExecuteCommandMacro).Methods("POST")
 
 	// Backups management
 	protected.HandleFunc("/server/{name}/backups", handlers.BackupsPage).Methods("GET")
@@ -84,33 +164,68 @@ func main() {
 	protected.HandleFunc("/server/{name}/backups/settings", handlers.UpdateBackupSettings).Methods("POST")
 	protected.HandleFunc("/server/{name}/backups/list", handlers.ListBackups).Methods("GET")
 	protected.HandleFunc("/server/{name}/backups/create", handlers.CreateBackup).Methods("POST")
+	protected.HandleFunc("/server/{name}/backups/job/{id}", handlers.GetFileJob).Methods("GET")
+	protected.HandleFunc("/server/{name}/backups/delete-filtered", handlers.DeleteFilteredBackups).Methods("POST")
+	protected.HandleFunc("/server/{name}/backups/reconcile", handlers.ReconcileBackups).Methods("POST")
+	protected.HandleFunc("/server/{name}/backups/rotation-preview", handlers.GetBackupRotationPreview).Methods("GET")
+	protected.HandleFunc("/server/{name}/backups/compare", handlers.CompareBackups).Methods("GET")
 	protected.HandleFunc("/server/{name}/backups/{id}", handlers.DeleteBackup).Methods("DELETE")
+	protected.HandleFunc("/server/{name}/backups/{id}/pin", handlers.PinBackup).Methods("POST")
 	protected.HandleFunc("/server/{name}/backups/download/{id}", handlers.DownloadBackup).Methods("GET")
 	protected.HandleFunc("/server/{name}/backups/restore/{id}", handlers.RestoreBackup).Methods("POST")
+	protected.HandleFunc("/server/{name}/backups/{id}/extract-file", handlers.ExtractBackupFile).Methods("GET", "POST")
+	protected.HandleFunc("/server/{name}/backups/{id}/validate", handlers.ValidateBackup).Methods("GET")
+	protected.HandleFunc("/server/{name}/backups/{id}/manifest", handlers.GetBackupManifest).Methods("GET")
+	protected.HandleFunc("/server/{name}/backups/{id}/restore-schedules", handlers.RestoreBackupSchedules).Methods("POST")
+	protected.HandleFunc("/server/{name}/backups/{id}/mount-temp", handlers.MountBackupTemp).Methods("POST")
+	protected.HandleFunc("/server/{name}/backups/mount-temp/{mount}", handlers.UnmountBackupTemp).Methods("DELETE")
+	protected.HandleFunc("/server/{name}/disk-impact", handlers.PreviewDiskImpact).Methods("GET")
+
+	// Backup policies (tag-based)
+	protected.HandleFunc("/api/backup-policies", handlers.ListBackupPolicies).Methods("GET")
+	protected.HandleFunc("/api/backup-policies/create", handlers.CreateBackupPolicy).Methods("POST")
+	protected.HandleFunc("/api/backup-policies/{id}/update", handlers.UpdateBackupPolicy).Methods("POST")
+	protected.HandleFunc("/api/backup-policies/{id}/delete", handlers.DeleteBackupPolicy).Methods("DELETE")
 
 	// File Manager
 	protected.HandleFunc("/server/{name}/files", handlers.FilesPage).Methods("GET")
 	protected.HandleFunc("/server/{name}/files/list", handlers.ListFiles).Methods("GET")
 	protected.HandleFunc("/server/{name}/files/navigate", handlers.NavigateFolder).Methods("GET")
+	protected.HandleFunc("/server/{name}/files/resolve", handlers.ResolvePath).Methods("GET")
 	
 	// File Manager Operations
 	protected.HandleFunc("/server/{name}/files/create-directory", handlers.CreateDirectory).Methods("POST")
 	protected.HandleFunc("/server/{name}/files/upload", handlers.UploadFile).Methods("POST")
+	protected.HandleFunc("/server/{name}/files/download-from-url", handlers.DownloadFromURL).Methods("POST")
 	protected.HandleFunc("/server/{name}/files/create-file", handlers.CreateNewFile).Methods("POST")
+	protected.HandleFunc("/server/{name}/files/touch", handlers.TouchFile).Methods("POST")
+	protected.HandleFunc("/server/{name}/files/from-template", handlers.WriteFileFromTemplate).Methods("POST")
 	protected.HandleFunc("/server/{name}/files/read", handlers.ReadFile).Methods("GET")
 	protected.HandleFunc("/server/{name}/files/write", handlers.WriteFile).Methods("POST")
+	protected.HandleFunc("/server/{name}/files/replace-in-files", handlers.ReplaceInFiles).Methods("POST")
 	protected.HandleFunc("/server/{name}/files/rename", handlers.RenameFile).Methods("POST")
 	protected.HandleFunc("/server/{name}/files/delete", handlers.DeleteFiles).Methods("POST")
 	protected.HandleFunc("/server/{name}/files/archive", handlers.ArchiveFiles).Methods("POST")
 	protected.HandleFunc("/server/{name}/files/unarchive", handlers.UnarchiveFile).Methods("POST")
+	protected.HandleFunc("/server/{name}/files/compress", handlers.CompressFile).Methods("POST")
 	protected.HandleFunc("/server/{name}/files/copy", handlers.CopyFiles).Methods("POST")
 	protected.HandleFunc("/server/{name}/files/move", handlers.MoveFiles).Methods("POST")
+	protected.HandleFunc("/server/{name}/files/journal", handlers.GetFileJournal).Methods("GET")
+	protected.HandleFunc("/server/{name}/files/undo", handlers.UndoFileOperation).Methods("POST")
 	protected.HandleFunc("/server/{name}/files/download", handlers.DownloadFile).Methods("GET")
+	protected.HandleFunc("/server/{name}/files/hexdump", handlers.HexDumpFile).Methods("GET")
+	protected.HandleFunc("/server/{name}/files/properties", handlers.GetFileProperties).Methods("GET")
+	protected.HandleFunc("/server/{name}/files/multitail", handlers.MultiTailFiles).Methods("GET")
+	protected.HandleFunc("/server/{name}/files/path-info", handlers.GetPathInfo).Methods("GET")
+	protected.HandleFunc("/server/{name}/files/download-selected", handlers.DownloadSelectedFiles).Methods("POST")
+	protected.HandleFunc("/server/{name}/files/export-tar", handlers.ExportTarGz).Methods("GET")
+	protected.HandleFunc("/server/{name}/files/thumbnail", handlers.GetFileThumbnail).Methods("GET")
+	protected.HandleFunc("/server/{name}/files/job/{id}", handlers.GetFileJob).Methods("GET")
 
 	// Logout
 	protected.HandleFunc("/logout", handlers.Logout).Methods("GET")
 
 	// Start server
-	log.Println("🚀 Seia Panel starting on http://0.0.0.0:6767")
-	log.Fatal(http.ListenAndServe(":6767", r))
+	log.Printf("🚀 Seia Panel %s (%s) starting on %s", config.Version, config.Commit, config.ListenAddr)
+	log.Fatal(http.ListenAndServe(config.ListenAddr, r))
 }
\ No newline at end of file
@@ -2,9 +2,12 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"seiapanel/config"
+	"seiapanel/models"
 )
 
 type contextKey string
@@ -15,7 +18,7 @@ const UserIDKey contextKey = "userID"
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Get session
-		session, err := config.GetSessionStore().Get(r, "auth-session")
+		session, err := config.GetSessionStore().Get(r, config.GetSessionCookieName())
 		if err != nil {
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
@@ -41,4 +44,41 @@ func GetUserID(r *http.Request) uint {
 		return 0
 	}
 	return userID
-}
\ No newline at end of file
+}
+
+// APIAuthMiddleware authenticates API requests with an "Authorization: Bearer <token>"
+// API token, falling back to the session so the panel UI can use the same endpoints
+func APIAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var userID uint
+
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			token, err := models.ValidateAPIToken(strings.TrimSpace(strings.TrimPrefix(auth, "Bearer ")))
+			if err != nil {
+				writeUnauthorized(w, "Invalid API token")
+				return
+			}
+			userID = token.UserID
+		} else if session, err := config.GetSessionStore().Get(r, config.GetSessionCookieName()); err == nil {
+			userID, _ = session.Values["user_id"].(uint)
+		}
+
+		if userID == 0 {
+			writeUnauthorized(w, "Authentication required")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), UserIDKey, userID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// writeUnauthorized writes a JSON 401 response for API clients
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   message,
+	})
+}
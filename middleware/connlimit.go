@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"sync"
+)
+
+// ConnLimiter tracks open long-lived connections (e.g. WebSockets) per user and per server
+type ConnLimiter struct {
+	users   map[uint]int
+	servers map[string]int
+	mu      sync.Mutex
+}
+
+// NewConnLimiter creates an empty connection registry
+func NewConnLimiter() *ConnLimiter {
+	return &ConnLimiter{
+		users:   make(map[uint]int),
+		servers: make(map[string]int),
+	}
+}
+
+// Acquire registers a connection if both the user and the server are below their limits.
+// A limit of 0 means unlimited. Every successful Acquire must be paired with Release.
+func (cl *ConnLimiter) Acquire(userID uint, serverName string, perUser, perServer int) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if perUser > 0 && cl.users[userID] >= perUser {
+		return false
+	}
+	if perServer > 0 && cl.servers[serverName] >= perServer {
+		return false
+	}
+
+	cl.users[userID]++
+	cl.servers[serverName]++
+	return true
+}
+
+// Release unregisters a connection registered with Acquire
+func (cl *ConnLimiter) Release(userID uint, serverName string) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.users[userID]--; cl.users[userID] <= 0 {
+		delete(cl.users, userID)
+	}
+	if cl.servers[serverName]--; cl.servers[serverName] <= 0 {
+		delete(cl.servers, serverName)
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter allows up to limit hits per key within a sliding window. Keys whose hits have
+// all left the window are removed, so the limiter only holds recently seen keys.
+type RateLimiter struct {
+	limit     int
+	window    time.Duration
+	hits      map[string][]time.Time
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// NewRateLimiter creates a rate limiter allowing limit hits per key per window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:     limit,
+		window:    window,
+		hits:      make(map[string][]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow records a hit for key and reports whether it is within the limit
+func (rl *RateLimiter) Allow(key string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	recent := rl.recentHits(key, now)
+	if len(recent) >= rl.limit {
+		return false
+	}
+
+	rl.hits[key] = append(recent, now)
+	return true
+}
+
+// Exceeded reports whether key has used up its hits in the window, without recording one
+func (rl *RateLimiter) Exceeded(key string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	return len(rl.recentHits(key, time.Now())) >= rl.limit
+}
+
+// Record records a hit for key whether or not it is within the limit
+func (rl *RateLimiter) Record(key string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	rl.hits[key] = append(rl.recentHits(key, now), now)
+}
+
+// recentHits drops the hits of key that fell out of the window and returns the rest, removing
+// the key when none are left. Once per window every other key is swept the same way. The
+// caller holds mu.
+func (rl *RateLimiter) recentHits(key string, now time.Time) []time.Time {
+	cutoff := now.Add(-rl.window)
+
+	if now.Sub(rl.lastSweep) >= rl.window {
+		for k, times := range rl.hits {
+			if len(times) == 0 || !times[len(times)-1].After(cutoff) {
+				delete(rl.hits, k)
+			}
+		}
+		rl.lastSweep = now
+	}
+
+	recent := rl.hits[key][:0]
+	for _, t := range rl.hits[key] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) == 0 {
+		delete(rl.hits, key)
+		return nil
+	}
+	rl.hits[key] = recent
+	return recent
+}
+
+// ClientIP returns the remote IP address of a request without the port
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// apiTokenPrefix marks SeiaPanel API tokens so they are easy to recognize in configs and logs
+const apiTokenPrefix = "seia_"
+
+// APIToken is a bearer token for API access without a session. Only a hash of the token is stored.
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"not null" json:"name"`
+	TokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	Preview    string     `gorm:"not null" json:"preview"` // First characters of the token, for identification
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIToken creates a new API token and returns it along with the plain token,
+// which is not stored and can't be retrieved again
+func CreateAPIToken(userID uint, name string) (*APIToken, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	plain := apiTokenPrefix + hex.EncodeToString(b)
+
+	token := &APIToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashAPIToken(plain),
+		Preview:   plain[:len(apiTokenPrefix)+6],
+	}
+
+	if err := DB.Create(token).Error; err != nil {
+		return nil, "", err
+	}
+
+	return token, plain, nil
+}
+
+// GetAPITokensByUserID retrieves all API tokens of a user
+func GetAPITokensByUserID(userID uint) ([]APIToken, error) {
+	var tokens []APIToken
+	if err := DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetAPITokenByID retrieves an API token by ID
+func GetAPITokenByID(id uint) (*APIToken, error) {
+	var token APIToken
+	if err := DB.First(&token, id).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ValidateAPIToken looks up the token matching a plain token and records its use
+func ValidateAPIToken(plain string) (*APIToken, error) {
+	var token APIToken
+	if err := DB.Where("token_hash = ?", hashAPIToken(plain)).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	token.LastUsedAt = &now
+	DB.Model(&token).UpdateColumn("last_used_at", now)
+
+	return &token, nil
+}
+
+// Delete revokes an API token
+func (t *APIToken) Delete() error {
+	return DB.Delete(t).Error
+}
+
+// hashAPIToken hashes a plain token for storage. Tokens are long and random,
+// so a fast hash is enough and allows lookup by hash.
+func hashAPIToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+)
+
+// Audit log sources
+const (
+	AuditSourceSession = "session"
+	AuditSourceWebhook = "webhook"
+)
+
+// AuditLog records an action performed on a server
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	ServerID  uint      `gorm:"index:idx_audit_logs_server_created,priority:1" json:"server_id"`
+	Action    string    `gorm:"not null;index" json:"action"` // e.g. server.start, server.stop
+	Source    string    `gorm:"not null" json:"source"`       // session, webhook
+	Success   bool      `json:"success"`
+	Details   string    `json:"details"`
+	IPAddress string    `json:"ip_address"`
+	CreatedAt time.Time `gorm:"index;index:idx_audit_logs_server_created,priority:2" json:"created_at"`
+}
+
+// CreateAuditLog creates a new audit log entry
+func CreateAuditLog(userID, serverID uint, action, source string, success bool, details, ipAddress string) (*AuditLog, error) {
+	entry := &AuditLog{
+		UserID:    userID,
+		ServerID:  serverID,
+		Action:    action,
+		Source:    source,
+		Success:   success,
+		Details:   details,
+		IPAddress: ipAddress,
+	}
+
+	if err := DB.Create(entry).Error; err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// AuditLogQuery filters and pages audit log searches
+type AuditLogQuery struct {
+	UserID   uint
+	ServerID uint // 0 = all servers of the user
+	From     *time.Time
+	To       *time.Time
+	Search   string // Matched against action and details
+	Page     int    // 1-based
+	PerPage  int
+}
+
+// SearchAuditLogs returns one page of matching audit log entries (newest first) and the total match count
+func SearchAuditLogs(q AuditLogQuery) ([]AuditLog, int64, error) {
+	query := DB.Model(&AuditLog{}).Where("user_id = ?", q.UserID)
+	if q.ServerID != 0 {
+		query = query.Where("server_id = ?", q.ServerID)
+	}
+	if q.From != nil {
+		query = query.Where("created_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		query = query.Where("created_at <= ?", *q.To)
+	}
+	if q.Search != "" {
+		like := "%" + q.Search + "%"
+		query = query.Where("action LIKE ? OR details LIKE ?", like, like)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []AuditLog
+	offset := (q.Page - 1) * q.PerPage
+	if err := query.Order("created_at DESC").Offset(offset).Limit(q.PerPage).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -10,7 +10,8 @@ type Backup struct {
 	ServerID  uint      `gorm:"not null" json:"server_id"`
 	FileName  string    `gorm:"not null" json:"file_name"`
 	FilePath  string    `gorm:"not null" json:"file_path"`
-	FileSize  int64     `json:"file_size"` // Size in bytes
+	FileSize  int64     `json:"file_size"`                   // Size in bytes
+	Pinned    bool      `gorm:"default:false" json:"pinned"` // Pinned backups are never rotated
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -30,6 +31,32 @@ func CreateBackup(serverID uint, fileName, filePath string, fileSize int64) (*Ba
 	return backup, nil
 }
 
+// ImportBackup creates a backup record for an existing archive file, dated createdAt
+func ImportBackup(serverID uint, fileName, filePath string, fileSize int64, createdAt time.Time) (*Backup, error) {
+	backup := &Backup{
+		ServerID:  serverID,
+		FileName:  fileName,
+		FilePath:  filePath,
+		FileSize:  fileSize,
+		CreatedAt: createdAt,
+	}
+
+	if err := DB.Create(backup).Error; err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+// IsBackupPathTracked reports whether any backup record points at the given file
+func IsBackupPathTracked(filePath string) (bool, error) {
+	var count int64
+	if err := DB.Model(&Backup{}).Where("file_path = ?", filePath).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // GetBackupsByServerID retrieves all backups for a specific server
 func GetBackupsByServerID(serverID uint) ([]Backup, error) {
 	var backups []Backup
@@ -48,15 +75,30 @@ func GetBackupByID(id uint) (*Backup, error) {
 	return &backup, nil
 }
 
+// SetPinned pins or unpins a backup
+func (b *Backup) SetPinned(pinned bool) error {
+	b.Pinned = pinned
+	return DB.Model(b).Update("pinned", pinned).Error
+}
+
 // DeleteBackup deletes a backup record and its file
 func (b *Backup) Delete() error {
 	return DB.Delete(b).Error
 }
 
-// GetOldestBackup gets the oldest backup for a server
+// GetOldestBackup gets the oldest unpinned backup for a server
 func GetOldestBackup(serverID uint) (*Backup, error) {
 	var backup Backup
-	if err := DB.Where("server_id = ?", serverID).Order("created_at ASC").First(&backup).Error; err != nil {
+	if err := DB.Where("server_id = ? AND pinned = ?", serverID, false).Order("created_at ASC").First(&backup).Error; err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// GetLatestBackup gets the newest backup for a server
+func GetLatestBackup(serverID uint) (*Backup, error) {
+	var backup Backup
+	if err := DB.Where("server_id = ?", serverID).Order("created_at DESC").First(&backup).Error; err != nil {
 		return nil, err
 	}
 	return &backup, nil
@@ -69,4 +111,13 @@ func CountBackups(serverID uint) (int64, error) {
 		return 0, err
 	}
 	return count, nil
-}
\ No newline at end of file
+}
+
+// CountUnpinnedBackups counts the backups of a server that count towards its backup limit
+func CountUnpinnedBackups(serverID uint) (int64, error) {
+	var count int64
+	if err := DB.Model(&Backup{}).Where("server_id = ? AND pinned = ?", serverID, false).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
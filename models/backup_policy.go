@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BackupPolicy backs up every server carrying a tag on a shared cron schedule
+type BackupPolicy struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	UserID         uint      `gorm:"not null;index" json:"user_id"`
+	Name           string    `gorm:"not null" json:"name"`
+	Tag            string    `gorm:"not null;index" json:"tag"`
+	CronMinute     string    `gorm:"not null" json:"cron_minute"`
+	CronHour       string    `gorm:"not null" json:"cron_hour"`
+	CronDayOfMonth string    `gorm:"not null" json:"cron_day_of_month"`
+	CronMonth      string    `gorm:"not null" json:"cron_month"`
+	CronDayOfWeek  string    `gorm:"not null" json:"cron_day_of_week"`
+	KeepBackups    int       `gorm:"default:1" json:"keep_backups"` // Backups to keep per server
+	Enabled        bool      `gorm:"default:true" json:"enabled"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CreateBackupPolicy creates a new tag-based backup policy
+func CreateBackupPolicy(userID uint, name, tag, cronMinute, cronHour, cronDayOfMonth, cronMonth, cronDayOfWeek string, keepBackups int, enabled bool) (*BackupPolicy, error) {
+	policy := &BackupPolicy{UserID: userID}
+	if err := policy.apply(name, tag, cronMinute, cronHour, cronDayOfMonth, cronMonth, cronDayOfWeek, keepBackups, enabled); err != nil {
+		return nil, err
+	}
+
+	if err := DB.Create(policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// GetBackupPoliciesByUserID retrieves all backup policies for a user
+func GetBackupPoliciesByUserID(userID uint) ([]BackupPolicy, error) {
+	var policies []BackupPolicy
+	if err := DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// GetBackupPolicyByID retrieves a backup policy by its ID
+func GetBackupPolicyByID(id uint) (*BackupPolicy, error) {
+	var policy BackupPolicy
+	if err := DB.First(&policy, id).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetAllEnabledBackupPolicies retrieves all enabled backup policies
+func GetAllEnabledBackupPolicies() ([]BackupPolicy, error) {
+	var policies []BackupPolicy
+	if err := DB.Where("enabled = ?", true).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// Update updates a backup policy
+func (p *BackupPolicy) Update(name, tag, cronMinute, cronHour, cronDayOfMonth, cronMonth, cronDayOfWeek string, keepBackups int, enabled bool) error {
+	if err := p.apply(name, tag, cronMinute, cronHour, cronDayOfMonth, cronMonth, cronDayOfWeek, keepBackups, enabled); err != nil {
+		return err
+	}
+	return DB.Save(p).Error
+}
+
+// Delete deletes a backup policy
+func (p *BackupPolicy) Delete() error {
+	return DB.Delete(p).Error
+}
+
+// GetCronExpression returns the cron expression string
+func (p *BackupPolicy) GetCronExpression() string {
+	return fmt.Sprintf("%s %s %s %s %s",
+		p.CronMinute,
+		p.CronHour,
+		p.CronDayOfMonth,
+		p.CronMonth,
+		p.CronDayOfWeek,
+	)
+}
+
+// apply validates and assigns policy fields
+func (p *BackupPolicy) apply(name, tag, cronMinute, cronHour, cronDayOfMonth, cronMonth, cronDayOfWeek string, keepBackups int, enabled bool) error {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+
+	if name == "" {
+		return errors.New("policy name is required")
+	}
+	if tag == "" {
+		return errors.New("policy tag is required")
+	}
+	if strings.Contains(tag, ",") {
+		return errors.New("policy tag cannot contain commas")
+	}
+	if keepBackups < 1 {
+		return errors.New("keep backups must be at least 1")
+	}
+
+	if err := ValidateCronField("minute", cronMinute); err != nil {
+		return err
+	}
+	if err := ValidateCronField("hour", cronHour); err != nil {
+		return err
+	}
+	if err := ValidateCronField("day_of_month", cronDayOfMonth); err != nil {
+		return err
+	}
+	if err := ValidateCronField("month", cronMonth); err != nil {
+		return err
+	}
+	if err := ValidateCronField("day_of_week", cronDayOfWeek); err != nil {
+		return err
+	}
+
+	p.Name = name
+	p.Tag = tag
+	p.CronMinute = cronMinute
+	p.CronHour = cronHour
+	p.CronDayOfMonth = cronDayOfMonth
+	p.CronMonth = cronMonth
+	p.CronDayOfWeek = cronDayOfWeek
+	p.KeepBackups = keepBackups
+	p.Enabled = enabled
+	return nil
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Command macro limits
+const (
+	MaxMacroSteps        = 50
+	MaxMacroDelaySeconds = 300
+)
+
+// MacroStep is one console command of a macro and the pause that follows it
+type MacroStep struct {
+	Command      string `json:"command"`
+	DelaySeconds int    `json:"delay_seconds"` // Wait after this command before sending the next
+}
+
+// CommandMacro is a named, ordered sequence of console commands for a server
+type CommandMacro struct {
+	ID        uint        `gorm:"primaryKey" json:"id"`
+	ServerID  uint        `gorm:"not null;index;uniqueIndex:idx_command_macros_server_name" json:"server_id"`
+	Name      string      `gorm:"not null;uniqueIndex:idx_command_macros_server_name" json:"name"`
+	Steps     []MacroStep `gorm:"serializer:json;not null" json:"steps"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// ErrMacroNameTaken is returned when a server already has a macro with the given name
+var ErrMacroNameTaken = errors.New("a macro with this name already exists on this server")
+
+// CreateCommandMacro creates a new command macro
+func CreateCommandMacro(serverID uint, name string, steps []MacroStep) (*CommandMacro, error) {
+	macro := &CommandMacro{ServerID: serverID}
+	if err := macro.apply(name, steps); err != nil {
+		return nil, err
+	}
+
+	if err := DB.Create(macro).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrMacroNameTaken
+		}
+		return nil, err
+	}
+
+	return macro, nil
+}
+
+// GetCommandMacrosByServerID retrieves all macros for a server, by name
+func GetCommandMacrosByServerID(serverID uint) ([]CommandMacro, error) {
+	var macros []CommandMacro
+	if err := DB.Where("server_id = ?", serverID).Order("name ASC").Find(&macros).Error; err != nil {
+		return nil, err
+	}
+	return macros, nil
+}
+
+// GetCommandMacroByID retrieves a macro by its ID
+func GetCommandMacroByID(id uint) (*CommandMacro, error) {
+	var macro CommandMacro
+	if err := DB.First(&macro, id).Error; err != nil {
+		return nil, err
+	}
+	return &macro, nil
+}
+
+// Update updates a command macro
+func (m *CommandMacro) Update(name string, steps []MacroStep) error {
+	if err := m.apply(name, steps); err != nil {
+		return err
+	}
+	if err := DB.Save(m).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrMacroNameTaken
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a command macro
+func (m *CommandMacro) Delete() error {
+	return DB.Delete(m).Error
+}
+
+// apply validates and assigns macro fields
+func (m *CommandMacro) apply(name string, steps []MacroStep) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("macro name is required")
+	}
+	if len(steps) == 0 {
+		return errors.New("macro needs at least one command")
+	}
+	if len(steps) > MaxMacroSteps {
+		return errors.New("macro has too many commands")
+	}
+
+	cleaned := make([]MacroStep, 0, len(steps))
+	for _, step := range steps {
+		command := strings.TrimSpace(step.Command)
+		if command == "" {
+			return errors.New("macro commands cannot be empty")
+		}
+		if strings.ContainsAny(command, "\r\n") {
+			return errors.New("macro commands must be a single line each")
+		}
+		if step.DelaySeconds < 0 || step.DelaySeconds > MaxMacroDelaySeconds {
+			return errors.New("macro delays must be between 0 and 300 seconds")
+		}
+		cleaned = append(cleaned, MacroStep{Command: command, DelaySeconds: step.DelaySeconds})
+	}
+
+	m.Name = name
+	m.Steps = cleaned
+	return nil
+}
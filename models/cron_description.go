@@ -0,0 +1,232 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var monthNames = []string{"", "January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December"}
+
+var weekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// DescribeCron converts cron fields into a plain-English description (e.g. "At 3:00 AM, every day").
+// It understands the *, */n, a,b,c, a-b and single value forms accepted by ValidateCronField.
+func DescribeCron(minute, hour, dayOfMonth, month, dayOfWeek string) string {
+	parts := []string{describeCronTime(minute, hour)}
+
+	dayParts := make([]string, 0, 3)
+	if dayOfMonth == "*" && dayOfWeek == "*" {
+		dayParts = append(dayParts, "every day")
+	} else {
+		if dayOfMonth != "*" {
+			dayParts = append(dayParts, describeCronDayOfMonth(dayOfMonth))
+		}
+		if dayOfWeek != "*" {
+			dayParts = append(dayParts, describeCronDayOfWeek(dayOfWeek))
+		}
+	}
+	if month != "*" {
+		dayParts = append(dayParts, describeCronMonth(month))
+	}
+
+	parts = append(parts, dayParts...)
+	return strings.Join(parts, ", ")
+}
+
+// describeCronTime describes the minute and hour fields together
+func describeCronTime(minute, hour string) string {
+	// A fixed minute combined with fixed hours reads best as clock times
+	if isSingleCronValue(minute) {
+		m, _ := strconv.Atoi(minute)
+
+		if isSingleCronValue(hour) || isCronList(hour) {
+			times := make([]string, 0)
+			for _, h := range strings.Split(hour, ",") {
+				hv, _ := strconv.Atoi(strings.TrimSpace(h))
+				times = append(times, formatClock(hv, m))
+			}
+			return "At " + joinCronList(times)
+		}
+
+		phrase := fmt.Sprintf("At %d minutes past the hour", m)
+		if hourPhrase := describeCronHour(hour); hourPhrase != "" {
+			phrase += ", " + hourPhrase
+		}
+		return phrase
+	}
+
+	var phrase string
+	switch {
+	case minute == "*":
+		phrase = "Every minute"
+	case strings.HasPrefix(minute, "*/"):
+		phrase = fmt.Sprintf("Every %s minutes", strings.TrimPrefix(minute, "*/"))
+	case isCronList(minute):
+		phrase = "At minutes " + joinCronList(splitCronList(minute))
+	case isCronRange(minute):
+		start, end := splitCronRange(minute)
+		phrase = fmt.Sprintf("Every minute from %s through %s", start, end)
+	default:
+		phrase = "At minute " + minute
+	}
+
+	if hourPhrase := describeCronHour(hour); hourPhrase != "" {
+		phrase += ", " + hourPhrase
+	}
+	return phrase
+}
+
+// describeCronHour describes the hour field when it isn't folded into a clock time
+func describeCronHour(hour string) string {
+	switch {
+	case hour == "*":
+		return ""
+	case strings.HasPrefix(hour, "*/"):
+		return fmt.Sprintf("every %s hours", strings.TrimPrefix(hour, "*/"))
+	case isCronRange(hour):
+		start, end := splitCronRange(hour)
+		s, _ := strconv.Atoi(start)
+		e, _ := strconv.Atoi(end)
+		return fmt.Sprintf("between %s and %s", formatClock(s, 0), formatClock(e, 59))
+	case isCronList(hour):
+		hours := make([]string, 0)
+		for _, h := range splitCronList(hour) {
+			hv, _ := strconv.Atoi(h)
+			hours = append(hours, formatHour(hv))
+		}
+		return "during the " + joinCronList(hours) + " hours"
+	default:
+		hv, _ := strconv.Atoi(hour)
+		return fmt.Sprintf("between %s and %s", formatClock(hv, 0), formatClock(hv, 59))
+	}
+}
+
+// describeCronDayOfMonth describes the day-of-month field
+func describeCronDayOfMonth(value string) string {
+	switch {
+	case strings.HasPrefix(value, "*/"):
+		return fmt.Sprintf("every %s days", strings.TrimPrefix(value, "*/"))
+	case isCronList(value):
+		return "on days " + joinCronList(splitCronList(value)) + " of the month"
+	case isCronRange(value):
+		start, end := splitCronRange(value)
+		return fmt.Sprintf("on days %s through %s of the month", start, end)
+	default:
+		return fmt.Sprintf("on day %s of the month", value)
+	}
+}
+
+// describeCronDayOfWeek describes the day-of-week field
+func describeCronDayOfWeek(value string) string {
+	switch {
+	case strings.HasPrefix(value, "*/"):
+		return fmt.Sprintf("every %s days of the week", strings.TrimPrefix(value, "*/"))
+	case isCronList(value):
+		return "only on " + joinCronList(mapCronNames(splitCronList(value), weekdayNames))
+	case isCronRange(value):
+		start, end := splitCronRange(value)
+		return fmt.Sprintf("%s through %s", cronName(start, weekdayNames), cronName(end, weekdayNames))
+	default:
+		return "only on " + cronName(value, weekdayNames)
+	}
+}
+
+// describeCronMonth describes the month field
+func describeCronMonth(value string) string {
+	switch {
+	case strings.HasPrefix(value, "*/"):
+		return fmt.Sprintf("every %s months", strings.TrimPrefix(value, "*/"))
+	case isCronList(value):
+		return "only in " + joinCronList(mapCronNames(splitCronList(value), monthNames))
+	case isCronRange(value):
+		start, end := splitCronRange(value)
+		return fmt.Sprintf("%s through %s", cronName(start, monthNames), cronName(end, monthNames))
+	default:
+		return "only in " + cronName(value, monthNames)
+	}
+}
+
+// isSingleCronValue reports whether a field is a single numeric value
+func isSingleCronValue(value string) bool {
+	_, err := strconv.Atoi(value)
+	return err == nil
+}
+
+// isCronList reports whether a field is a comma-separated list
+func isCronList(value string) bool {
+	return strings.Contains(value, ",")
+}
+
+// isCronRange reports whether a field is an a-b range
+func isCronRange(value string) bool {
+	return !isCronList(value) && strings.Contains(value, "-")
+}
+
+// splitCronList splits a list field into trimmed values
+func splitCronList(value string) []string {
+	parts := strings.Split(value, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// splitCronRange splits a range field into its start and end
+func splitCronRange(value string) (string, string) {
+	parts := strings.SplitN(value, "-", 2)
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+// cronName maps a numeric value to a name, falling back to the raw value
+func cronName(value string, names []string) string {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n >= len(names) || names[n] == "" {
+		return value
+	}
+	return names[n]
+}
+
+// mapCronNames maps every value in a list to its name
+func mapCronNames(values []string, names []string) []string {
+	mapped := make([]string, len(values))
+	for i, v := range values {
+		mapped[i] = cronName(v, names)
+	}
+	return mapped
+}
+
+// joinCronList joins items as "a, b and c"
+func joinCronList(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// formatClock formats an hour and minute as a 12-hour clock time (e.g. "3:05 PM")
+func formatClock(hour, minute int) string {
+	period := "AM"
+	if hour >= 12 {
+		period = "PM"
+	}
+	h := hour % 12
+	if h == 0 {
+		h = 12
+	}
+	return fmt.Sprintf("%d:%02d %s", h, minute, period)
+}
+
+// formatHour formats an hour as a 12-hour value (e.g. "3 PM")
+func formatHour(hour int) string {
+	period := "AM"
+	if hour >= 12 {
+		period = "PM"
+	}
+	h := hour % 12
+	if h == 0 {
+		h = 12
+	}
+	return fmt.Sprintf("%d %s", h, period)
+}
@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestDescribeCron(t *testing.T) {
+	tests := []struct {
+		minute, hour, dayOfMonth, month, dayOfWeek string
+		want                                       string
+	}{
+		{"0", "3", "*", "*", "*", "At 3:00 AM, every day"},
+		{"*", "*", "*", "*", "*", "Every minute, every day"},
+		{"*/15", "*", "*", "*", "*", "Every 15 minutes, every day"},
+		{"30", "9,17", "*", "*", "1-5", "At 9:30 AM and 5:30 PM, Monday through Friday"},
+		{"0", "*/2", "*", "*", "*", "At 0 minutes past the hour, every 2 hours, every day"},
+		{"0", "8-18", "*", "*", "*", "At 0 minutes past the hour, between 8:00 AM and 6:59 PM, every day"},
+		{"0", "12", "*", "6", "0", "At 12:00 PM, only on Sunday, only in June"},
+		{"0", "0", "1,15", "1-3", "*", "At 12:00 AM, on days 1 and 15 of the month, January through March"},
+	}
+	for _, test := range tests {
+		got := DescribeCron(test.minute, test.hour, test.dayOfMonth, test.month, test.dayOfWeek)
+		if got != test.want {
+			t.Errorf("DescribeCron(%q, %q, %q, %q, %q) = %q, want %q",
+				test.minute, test.hour, test.dayOfMonth, test.month, test.dayOfWeek, got, test.want)
+		}
+	}
+}
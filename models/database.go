@@ -31,8 +31,13 @@ func InitDatabase() {
 
 	log.Println("✅ Database connected successfully")
 
+	// Schedule names became unique per server; fix up older databases first
+	if err := renameDuplicateScheduleNames(); err != nil {
+		log.Fatal("Failed to prepare schedules for migration:", err)
+	}
+
 	// Auto migrate models
-	err = DB.AutoMigrate(&User{}, &Server{}, &Backup{}, &Schedule{})
+	err = DB.AutoMigrate(&User{}, &Server{}, &Backup{}, &Schedule{}, &ScheduleRun{}, &BackupPolicy{}, &AuditLog{}, &APIToken{}, &CommandMacro{}, &FileTemplate{}, &FileJournalEntry{})
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
@@ -43,4 +48,13 @@ func InitDatabase() {
 // GetDB returns the database instance
 func GetDB() *gorm.DB {
 	return DB
-}
\ No newline at end of file
+}
+
+// PingDatabase checks that the database connection is usable
+func PingDatabase() error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Ping()
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB points DB at a fresh, fully migrated database in a temporary folder for one test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "app.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	err = db.AutoMigrate(&User{}, &Server{}, &Backup{}, &Schedule{}, &ScheduleRun{}, &BackupPolicy{}, &AuditLog{}, &APIToken{}, &CommandMacro{}, &FileTemplate{}, &FileJournalEntry{})
+	if err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	previous := DB
+	DB = db
+	t.Cleanup(func() {
+		DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+}
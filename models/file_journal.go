@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// FileJournalEntry is a journaled delete, move or overwrite in a server folder, kept across
+// restarts so it can still be undone and reviewed
+type FileJournalEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ServerID  uint      `gorm:"not null;index" json:"server_id"`
+	Op        string    `gorm:"not null" json:"op"`     // delete, move, overwrite
+	Items     string    `gorm:"type:text" json:"items"` // JSON list of the affected files
+	Undoable  bool      `json:"undoable"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateFileJournalEntry records a journaled file operation
+func CreateFileJournalEntry(entry *FileJournalEntry) error {
+	return DB.Create(entry).Error
+}
+
+// GetFileJournalEntries retrieves a server's journaled file operations, newest first
+func GetFileJournalEntries(serverID uint) ([]FileJournalEntry, error) {
+	var entries []FileJournalEntry
+	if err := DB.Where("server_id = ?", serverID).Order("id DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetAllFileJournalEntries retrieves the journaled file operations of every server, oldest first
+func GetAllFileJournalEntries() ([]FileJournalEntry, error) {
+	var entries []FileJournalEntry
+	if err := DB.Order("id ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// UpdateFileJournalEntry saves changes to a journaled file operation
+func UpdateFileJournalEntry(entry *FileJournalEntry) error {
+	return DB.Save(entry).Error
+}
+
+// DeleteFileJournalEntry removes a journaled file operation
+func DeleteFileJournalEntry(id uint) error {
+	return DB.Delete(&FileJournalEntry{}, id).Error
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxFileTemplateSize bounds the content of a file template
+const MaxFileTemplateSize = 1024 * 1024
+
+// FileTemplatePlaceholder matches a {{variable}} placeholder in a file template
+var FileTemplatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// FileTemplate is a named file content, such as a baseline server.properties, that can be
+// written into any of the user's servers
+type FileTemplate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index;uniqueIndex:idx_file_templates_user_name" json:"user_id"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_file_templates_user_name" json:"name"`
+	Content   string    `gorm:"not null" json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ErrFileTemplateNameTaken is returned when the user already has a template with the given name
+var ErrFileTemplateNameTaken = errors.New("a template with this name already exists")
+
+// CreateFileTemplate creates a new file template
+func CreateFileTemplate(userID uint, name, content string) (*FileTemplate, error) {
+	template := &FileTemplate{UserID: userID}
+	if err := template.apply(name, content); err != nil {
+		return nil, err
+	}
+
+	if err := DB.Create(template).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrFileTemplateNameTaken
+		}
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// GetFileTemplatesByUserID retrieves all file templates of a user, by name
+func GetFileTemplatesByUserID(userID uint) ([]FileTemplate, error) {
+	var templates []FileTemplate
+	if err := DB.Where("user_id = ?", userID).Order("name ASC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// GetFileTemplateByID retrieves a file template by its ID, only if it belongs to the user
+func GetFileTemplateByID(id, userID uint) (*FileTemplate, error) {
+	var template FileTemplate
+	if err := DB.Where("id = ? AND user_id = ?", id, userID).First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// Update updates a file template
+func (t *FileTemplate) Update(name, content string) error {
+	if err := t.apply(name, content); err != nil {
+		return err
+	}
+	if err := DB.Save(t).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrFileTemplateNameTaken
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete deletes a file template
+func (t *FileTemplate) Delete() error {
+	return DB.Delete(t).Error
+}
+
+// Placeholders returns the distinct variable names used in the template, in order of first use
+func (t *FileTemplate) Placeholders() []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range FileTemplatePlaceholder.FindAllStringSubmatch(t.Content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// Render substitutes variables into the template. Placeholders without a value are left as
+// they are and returned, so the caller can report them.
+func (t *FileTemplate) Render(variables map[string]string) (string, []string) {
+	missing := make([]string, 0)
+	seen := make(map[string]bool)
+	content := FileTemplatePlaceholder.ReplaceAllStringFunc(t.Content, func(placeholder string) string {
+		name := FileTemplatePlaceholder.FindStringSubmatch(placeholder)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return placeholder
+	})
+	return content, missing
+}
+
+// apply validates and assigns template fields
+func (t *FileTemplate) apply(name, content string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("template name is required")
+	}
+	if len(content) > MaxFileTemplateSize {
+		return errors.New("template content is larger than 1 MiB")
+	}
+
+	t.Name = name
+	t.Content = content
+	return nil
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+)
+
+// ServerActivity holds per-server aggregates for the servers overview
+type ServerActivity struct {
+	BackupCount    int64
+	LatestBackupAt *time.Time
+	ScheduleCount  int64
+	LastActivityAt *time.Time
+}
+
+// GetServerActivity loads backup, schedule and activity aggregates for many servers
+// with one grouped query per table instead of one query per server
+func GetServerActivity(serverIDs []uint) (map[uint]*ServerActivity, error) {
+	activity := make(map[uint]*ServerActivity, len(serverIDs))
+	for _, id := range serverIDs {
+		activity[id] = &ServerActivity{}
+	}
+	if len(serverIDs) == 0 {
+		return activity, nil
+	}
+
+	var backupRows []struct {
+		ServerID uint
+		Count    int64
+		Latest   *string
+	}
+	if err := DB.Model(&Backup{}).
+		Select("server_id, COUNT(*) AS count, MAX(created_at) AS latest").
+		Where("server_id IN ?", serverIDs).
+		Group("server_id").
+		Scan(&backupRows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range backupRows {
+		activity[row.ServerID].BackupCount = row.Count
+		activity[row.ServerID].LatestBackupAt = parseAggregateTime(row.Latest)
+	}
+
+	var scheduleRows []struct {
+		ServerID uint
+		Count    int64
+	}
+	if err := DB.Model(&Schedule{}).
+		Select("server_id, COUNT(*) AS count").
+		Where("server_id IN ?", serverIDs).
+		Group("server_id").
+		Scan(&scheduleRows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range scheduleRows {
+		activity[row.ServerID].ScheduleCount = row.Count
+	}
+
+	// Last activity is the newest audit log entry or schedule run
+	var auditRows []struct {
+		ServerID uint
+		Latest   *string
+	}
+	if err := DB.Model(&AuditLog{}).
+		Select("server_id, MAX(created_at) AS latest").
+		Where("server_id IN ?", serverIDs).
+		Group("server_id").
+		Scan(&auditRows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range auditRows {
+		activity[row.ServerID].touch(parseAggregateTime(row.Latest))
+	}
+
+	var runRows []struct {
+		ServerID uint
+		Latest   *string
+	}
+	if err := DB.Model(&ScheduleRun{}).
+		Select("server_id, MAX(finished_at) AS latest").
+		Where("server_id IN ?", serverIDs).
+		Group("server_id").
+		Scan(&runRows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range runRows {
+		activity[row.ServerID].touch(parseAggregateTime(row.Latest))
+	}
+
+	return activity, nil
+}
+
+// touch moves LastActivityAt forward to t if t is newer
+func (a *ServerActivity) touch(t *time.Time) {
+	if t != nil && (a.LastActivityAt == nil || t.After(*a.LastActivityAt)) {
+		a.LastActivityAt = t
+	}
+}
+
+// aggregateTimeLayouts are the formats SQLite may return for MAX() over a datetime column,
+// which comes back as text rather than a typed time
+var aggregateTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+}
+
+// parseAggregateTime parses a time returned by an aggregate query, or nil if it can't
+func parseAggregateTime(value *string) *time.Time {
+	if value == nil {
+		return nil
+	}
+	for _, layout := range aggregateTimeLayouts {
+		if t, err := time.Parse(layout, *value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
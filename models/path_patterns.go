@@ -0,0 +1,41 @@
+package models
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SplitPathPatterns splits a comma-separated list of glob patterns, dropping empty entries
+func SplitPathPatterns(list string) []string {
+	patterns := make([]string, 0)
+	for _, pattern := range strings.Split(list, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// MatchPathPattern reports which of the glob patterns, if any, matches a path inside root.
+// Patterns containing a slash match the path relative to root, others match the file name
+// anywhere in it.
+func MatchPathPattern(root, path string, patterns []string) (string, bool) {
+	relPath, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", false
+	}
+	relPath = filepath.ToSlash(relPath)
+	name := filepath.Base(path)
+
+	for _, pattern := range patterns {
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = relPath
+			pattern = strings.TrimPrefix(pattern, "/")
+		}
+		if matched, _ := filepath.Match(pattern, target); matched {
+			return pattern, true
+		}
+	}
+	return "", false
+}
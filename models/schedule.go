@@ -3,30 +3,93 @@ package models
 import (
 	"errors"
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Schedule represents a scheduled task for a server
 type Schedule struct {
-	ID             uint      `gorm:"primaryKey" json:"id"`
-	ServerID       uint      `gorm:"not null;index" json:"server_id"`
-	Name           string    `gorm:"not null" json:"name"`
-	CronMinute     string    `gorm:"not null" json:"cron_minute"`     // 0-59 or *
-	CronHour       string    `gorm:"not null" json:"cron_hour"`       // 0-23 or *
-	CronDayOfMonth string    `gorm:"not null" json:"cron_day_of_month"` // 1-31 or *
-	CronMonth      string    `gorm:"not null" json:"cron_month"`      // 1-12 or *
-	CronDayOfWeek  string    `gorm:"not null" json:"cron_day_of_week"`  // 0-6 (0=Sunday) or *
-	Enabled        bool      `gorm:"default:true" json:"enabled"`
-	Action         string    `gorm:"not null" json:"action"` // send_command, start_server, restart_server, stop_server
-	Command        string    `gorm:"default:''" json:"command"` // Only used for send_command action
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	ServerID       uint       `gorm:"not null;index;uniqueIndex:idx_schedules_server_name" json:"server_id"`
+	Name           string     `gorm:"not null;uniqueIndex:idx_schedules_server_name" json:"name"`
+	CronMinute     string     `gorm:"not null" json:"cron_minute"`       // 0-59 or *
+	CronHour       string     `gorm:"not null" json:"cron_hour"`         // 0-23 or *
+	CronDayOfMonth string     `gorm:"not null" json:"cron_day_of_month"` // 1-31 or *
+	CronMonth      string     `gorm:"not null" json:"cron_month"`        // 1-12 or *
+	CronDayOfWeek  string     `gorm:"not null" json:"cron_day_of_week"`  // 0-6 (0=Sunday) or *
+	Enabled        bool       `gorm:"default:true" json:"enabled"`
+	Action         string     `gorm:"not null" json:"action"`            // send_command, start_server, restart_server, reload_server, stop_server, backup
+	Command        string     `gorm:"default:''" json:"command"`         // Only used for send_command action
+	Description    string     `gorm:"-" json:"description"`              // Plain-English cron description (not stored)
+	CatchUp        bool       `gorm:"default:false" json:"catch_up"`     // Run once on startup if a fire time was missed during downtime
+	SkipOverlap    bool       `gorm:"default:false" json:"skip_overlap"` // Skip a run while the previous run is still in progress
+	LastRunAt      *time.Time `json:"last_run_at"`                       // Last successful run
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// ErrScheduleNameTaken is returned when a server already has a schedule with the given name
+var ErrScheduleNameTaken = errors.New("a schedule with this name already exists on this server")
+
+// scheduleNameTaken reports whether another schedule of the server uses name
+func scheduleNameTaken(serverID uint, name string, excludeID uint) (bool, error) {
+	var count int64
+	err := DB.Model(&Schedule{}).Where("server_id = ? AND name = ? AND id != ?", serverID, name, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+// isUniqueViolation reports whether err is a unique constraint failure, for inserts
+// that race past the name check
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// renameDuplicateScheduleNames suffixes duplicate schedule names within a server (e.g.
+// "Restart (2)") so the unique index can be created on databases from older versions
+func renameDuplicateScheduleNames() error {
+	if !DB.Migrator().HasTable(&Schedule{}) {
+		return nil
+	}
+
+	var schedules []Schedule
+	if err := DB.Order("server_id, id").Find(&schedules).Error; err != nil {
+		return err
+	}
+
+	taken := make(map[string]bool, len(schedules))
+	for _, schedule := range schedules {
+		taken[fmt.Sprintf("%d/%s", schedule.ServerID, schedule.Name)] = true
+	}
+
+	seen := make(map[string]bool, len(schedules))
+	for _, schedule := range schedules {
+		key := fmt.Sprintf("%d/%s", schedule.ServerID, schedule.Name)
+		if !seen[key] {
+			seen[key] = true
+			continue
+		}
+
+		newName := schedule.Name
+		for n := 2; taken[fmt.Sprintf("%d/%s", schedule.ServerID, newName)]; n++ {
+			newName = fmt.Sprintf("%s (%d)", schedule.Name, n)
+		}
+		taken[fmt.Sprintf("%d/%s", schedule.ServerID, newName)] = true
+
+		if err := DB.Model(&Schedule{}).Where("id = ?", schedule.ID).UpdateColumn("name", newName).Error; err != nil {
+			return err
+		}
+		log.Printf("⚠️  Renamed duplicate schedule '%s' (ID: %d) to '%s'", schedule.Name, schedule.ID, newName)
+	}
+
+	return nil
 }
 
 // CreateSchedule creates a new schedule
-func CreateSchedule(serverID uint, name, cronMinute, cronHour, cronDayOfMonth, cronMonth, cronDayOfWeek string, enabled bool, action, command string) (*Schedule, error) {
+func CreateSchedule(serverID uint, name, cronMinute, cronHour, cronDayOfMonth, cronMonth, cronDayOfWeek string, enabled, catchUp, skipOverlap bool, action, command string) (*Schedule, error) {
 	// Validate inputs
 	if name == "" {
 		return nil, errors.New("schedule name is required")
@@ -49,7 +112,7 @@ func CreateSchedule(serverID uint, name, cronMinute, cronHour, cronDayOfMonth, c
 	}
 
 	// Validate action
-	validActions := []string{"send_command", "start_server", "restart_server", "stop_server", "backup"}
+	validActions := []string{"send_command", "start_server", "restart_server", "reload_server", "stop_server", "backup"}
 	isValidAction := false
 	for _, validAction := range validActions {
 		if action == validAction {
@@ -66,6 +129,12 @@ func CreateSchedule(serverID uint, name, cronMinute, cronHour, cronDayOfMonth, c
 		return nil, errors.New("command is required for send_command action")
 	}
 
+	if taken, err := scheduleNameTaken(serverID, name, 0); err != nil {
+		return nil, err
+	} else if taken {
+		return nil, ErrScheduleNameTaken
+	}
+
 	schedule := &Schedule{
 		ServerID:       serverID,
 		Name:           name,
@@ -75,17 +144,56 @@ func CreateSchedule(serverID uint, name, cronMinute, cronHour, cronDayOfMonth, c
 		CronMonth:      cronMonth,
 		CronDayOfWeek:  cronDayOfWeek,
 		Enabled:        enabled,
+		CatchUp:        catchUp,
+		SkipOverlap:    skipOverlap,
 		Action:         action,
 		Command:        command,
 	}
 
 	if err := DB.Create(schedule).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrScheduleNameTaken
+		}
 		return nil, err
 	}
 
+	// Create skips zero values for columns with a default, which would leave the schedule enabled
+	if !enabled {
+		if err := DB.Model(schedule).Update("enabled", false).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	schedule.Description = schedule.Describe()
 	return schedule, nil
 }
 
+// Duplicate creates a disabled copy of the schedule on the same server, named after the
+// original with a "(copy)" suffix that is numbered when already taken
+func (s *Schedule) Duplicate() (*Schedule, error) {
+	for n := 1; ; n++ {
+		name := s.Name + " (copy)"
+		if n > 1 {
+			name = fmt.Sprintf("%s (copy %d)", s.Name, n)
+		}
+
+		taken, err := scheduleNameTaken(s.ServerID, name, 0)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			continue
+		}
+
+		duplicate, err := CreateSchedule(s.ServerID, name, s.CronMinute, s.CronHour, s.CronDayOfMonth, s.CronMonth, s.CronDayOfWeek, false, s.CatchUp, s.SkipOverlap, s.Action, s.Command)
+		// Another request may have taken the name in the meantime
+		if errors.Is(err, ErrScheduleNameTaken) {
+			continue
+		}
+		return duplicate, err
+	}
+}
+
 // GetSchedulesByServerID retrieves all schedules for a specific server
 func GetSchedulesByServerID(serverID uint) ([]Schedule, error) {
 	var schedules []Schedule
@@ -105,7 +213,7 @@ func GetScheduleByID(id uint) (*Schedule, error) {
 }
 
 // UpdateSchedule updates a schedule
-func (s *Schedule) UpdateSchedule(name, cronMinute, cronHour, cronDayOfMonth, cronMonth, cronDayOfWeek string, enabled bool, action, command string) error {
+func (s *Schedule) UpdateSchedule(name, cronMinute, cronHour, cronDayOfMonth, cronMonth, cronDayOfWeek string, enabled, catchUp, skipOverlap bool, action, command string) error {
 	// Validate inputs
 	if name == "" {
 		return errors.New("schedule name is required")
@@ -128,7 +236,7 @@ func (s *Schedule) UpdateSchedule(name, cronMinute, cronHour, cronDayOfMonth, cr
 	}
 
 	// Validate action
-	validActions := []string{"send_command", "start_server", "restart_server", "stop_server", "backup"}
+	validActions := []string{"send_command", "start_server", "restart_server", "reload_server", "stop_server", "backup"}
 	isValidAction := false
 	for _, validAction := range validActions {
 		if action == validAction {
@@ -145,6 +253,12 @@ func (s *Schedule) UpdateSchedule(name, cronMinute, cronHour, cronDayOfMonth, cr
 		return errors.New("command is required for send_command action")
 	}
 
+	if taken, err := scheduleNameTaken(s.ServerID, name, s.ID); err != nil {
+		return err
+	} else if taken {
+		return ErrScheduleNameTaken
+	}
+
 	// Update fields
 	s.Name = name
 	s.CronMinute = cronMinute
@@ -153,10 +267,19 @@ func (s *Schedule) UpdateSchedule(name, cronMinute, cronHour, cronDayOfMonth, cr
 	s.CronMonth = cronMonth
 	s.CronDayOfWeek = cronDayOfWeek
 	s.Enabled = enabled
+	s.CatchUp = catchUp
+	s.SkipOverlap = skipOverlap
 	s.Action = action
 	s.Command = command
+	s.Description = s.Describe()
 
-	return DB.Save(s).Error
+	if err := DB.Save(s).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrScheduleNameTaken
+		}
+		return err
+	}
+	return nil
 }
 
 // ToggleEnabled toggles the enabled status of a schedule
@@ -171,8 +294,17 @@ func (s *Schedule) SetEnabled(enabled bool) error {
 	return DB.Save(s).Error
 }
 
-// Delete deletes a schedule
+// RecordRun stores the time of the last successful run without touching other fields
+func (s *Schedule) RecordRun(runAt time.Time) error {
+	s.LastRunAt = &runAt
+	return DB.Model(&Schedule{}).Where("id = ?", s.ID).UpdateColumn("last_run_at", runAt).Error
+}
+
+// Delete deletes a schedule and its run history
 func (s *Schedule) Delete() error {
+	if err := DB.Where("schedule_id = ?", s.ID).Delete(&ScheduleRun{}).Error; err != nil {
+		return err
+	}
 	return DB.Delete(s).Error
 }
 
@@ -187,6 +319,17 @@ func (s *Schedule) GetCronExpression() string {
 	)
 }
 
+// Describe returns a plain-English description of the schedule's cron fields
+func (s *Schedule) Describe() string {
+	return DescribeCron(s.CronMinute, s.CronHour, s.CronDayOfMonth, s.CronMonth, s.CronDayOfWeek)
+}
+
+// AfterFind fills in the computed description whenever a schedule is loaded
+func (s *Schedule) AfterFind(tx *gorm.DB) error {
+	s.Description = s.Describe()
+	return nil
+}
+
 // ValidateCronField validates a cron field value
 func ValidateCronField(fieldName, value string) error {
 	if value == "" {
@@ -305,4 +448,4 @@ func GetAllEnabledSchedules() ([]Schedule, error) {
 		return nil, err
 	}
 	return schedules, nil
-}
\ No newline at end of file
+}
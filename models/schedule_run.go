@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+)
+
+// Schedule run statuses
+const (
+	ScheduleRunSuccess        = "success"
+	ScheduleRunFailed         = "failed"
+	ScheduleRunSkipped        = "skipped"         // Action not applicable, e.g. server offline
+	ScheduleRunSkippedOverlap = "skipped_overlap" // Previous run of the same schedule still in progress
+	ScheduleRunRetrying       = "retrying"        // Attempt failed, another attempt follows
+)
+
+// Schedule run triggers
+const (
+	ScheduleTriggerCron    = "cron"
+	ScheduleTriggerManual  = "manual"
+	ScheduleTriggerCatchUp = "catch_up"
+)
+
+// ScheduleRun records one execution attempt of a schedule
+type ScheduleRun struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ScheduleID uint      `gorm:"not null;index" json:"schedule_id"`
+	ServerID   uint      `gorm:"not null;index" json:"server_id"`
+	Trigger    string    `gorm:"not null" json:"trigger"` // cron, manual, catch_up
+	Status     string    `gorm:"not null" json:"status"`  // success, failed, skipped, skipped_overlap, retrying
+	Message    string    `json:"message"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// CreateScheduleRun records a schedule run. History is trimmed periodically by PruneScheduleRuns.
+func CreateScheduleRun(scheduleID, serverID uint, trigger, status, message string, startedAt, finishedAt time.Time) (*ScheduleRun, error) {
+	run := &ScheduleRun{
+		ScheduleID: scheduleID,
+		ServerID:   serverID,
+		Trigger:    trigger,
+		Status:     status,
+		Message:    message,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+	}
+
+	if err := DB.Create(run).Error; err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// GetScheduleRuns retrieves the run history of a schedule, newest first
+func GetScheduleRuns(scheduleID uint) ([]ScheduleRun, error) {
+	var runs []ScheduleRun
+	if err := DB.Where("schedule_id = ?", scheduleID).Order("id DESC").Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// PruneScheduleRuns trims run history to the newest keep runs per schedule and, if maxAge
+// is positive, drops runs that finished longer ago than that. The latest successful run of
+// each schedule is always kept. Returns the number of runs deleted.
+func PruneScheduleRuns(keep int, maxAge time.Duration) (int64, error) {
+	lastSuccess := DB.Model(&ScheduleRun{}).Select("MAX(id)").Where("status = ?", ScheduleRunSuccess).Group("schedule_id")
+
+	var deleted int64
+
+	// Runs past the newest keep of their schedule
+	ranked := DB.Model(&ScheduleRun{}).Select("id, ROW_NUMBER() OVER (PARTITION BY schedule_id ORDER BY id DESC) AS rn")
+	overflow := DB.Table("(?) AS ranked", ranked).Select("id").Where("rn > ?", keep)
+	result := DB.Where("id IN (?) AND id NOT IN (?)", overflow, lastSuccess).Delete(&ScheduleRun{})
+	if result.Error != nil {
+		return deleted, result.Error
+	}
+	deleted += result.RowsAffected
+
+	if maxAge > 0 {
+		result = DB.Where("finished_at < ? AND id NOT IN (?)", time.Now().Add(-maxAge), lastSuccess).Delete(&ScheduleRun{})
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += result.RowsAffected
+	}
+
+	return deleted, nil
+}
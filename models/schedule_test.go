@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestScheduleNamesUniquePerServer checks that a schedule name can be used once per server
+func TestScheduleNamesUniquePerServer(t *testing.T) {
+	setupTestDB(t)
+
+	create := func(serverID uint, name string) (*Schedule, error) {
+		return CreateSchedule(serverID, name, "0", "3", "*", "*", "*", true, false, false, "backup", "")
+	}
+
+	if _, err := create(1, "Nightly backup"); err != nil {
+		t.Fatalf("first schedule: %v", err)
+	}
+	if _, err := create(1, "Nightly backup"); !errors.Is(err, ErrScheduleNameTaken) {
+		t.Errorf("same name on the same server: error = %v, want ErrScheduleNameTaken", err)
+	}
+	if _, err := create(2, "Nightly backup"); err != nil {
+		t.Errorf("same name on another server: %v", err)
+	}
+
+	// Renaming onto a taken name is refused as well, and the database backs the check
+	other, err := create(1, "Restart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = other.UpdateSchedule("Nightly backup", "0", "3", "*", "*", "*", true, false, false, "backup", "")
+	if !errors.Is(err, ErrScheduleNameTaken) {
+		t.Errorf("rename onto a taken name: error = %v, want ErrScheduleNameTaken", err)
+	}
+	duplicate := &Schedule{ServerID: 1, Name: "Nightly backup", CronMinute: "0", CronHour: "3", CronDayOfMonth: "*", CronMonth: "*", CronDayOfWeek: "*", Action: "backup"}
+	if err := DB.Create(duplicate).Error; err == nil {
+		t.Error("database accepted a duplicate schedule name")
+	}
+}
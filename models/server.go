@@ -2,24 +2,62 @@ package models
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Server represents a Minecraft server
 type Server struct {
-	ID             uint       `gorm:"primaryKey" json:"id"`
-	Name           string     `gorm:"unique;not null" json:"name"`
-	FolderPath     string     `gorm:"not null" json:"folder_path"`
-	StartupCommand string     `gorm:"not null" json:"startup_command"`
-	Status         string     `gorm:"default:'offline'" json:"status"` // online, offline
-	StartedAt      *time.Time `json:"started_at"`
-	BackupPath     string     `gorm:"default:''" json:"backup_path"`        // Backup directory path
-	MaxBackups     int        `gorm:"default:1" json:"max_backups"`         // Max number of backups (default 1, max 3)
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-	UserID         uint       `gorm:"not null" json:"user_id"`
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	Name             string     `gorm:"unique;not null" json:"name"`
+	FolderPath       string     `gorm:"not null" json:"folder_path"`
+	StartupCommand   string     `gorm:"not null" json:"startup_command"`
+	Status           string     `gorm:"default:'offline'" json:"status"` // online, offline
+	StartedAt        *time.Time `json:"started_at"`
+	BackupPath       string     `gorm:"default:''" json:"backup_path"`            // Backup directory path
+	MaxBackups       int        `gorm:"default:1" json:"max_backups"`             // Max number of backups (default 1, max 3)
+	Tags             string     `gorm:"default:''" json:"tags"`                   // Comma-separated tags (e.g. "production,survival")
+	WrapBackups      bool       `gorm:"default:false" json:"wrap_backups"`        // Wrap backup entries in a top-level server folder
+	TriggerSecret    string     `gorm:"default:''" json:"-"`                      // HMAC secret for webhook triggers (empty = disabled)
+	FileRoot         string     `gorm:"default:''" json:"file_root"`              // File manager root, relative to FolderPath (empty = FolderPath)
+	AlertCPUPercent  float64    `gorm:"default:0" json:"alert_cpu_percent"`       // CPU alert threshold (0 = disabled)
+	AlertMemPercent  float64    `gorm:"default:0" json:"alert_mem_percent"`       // Memory alert threshold, % of system RAM (0 = disabled)
+	AlertDuration    int        `gorm:"default:60" json:"alert_duration"`         // Seconds a threshold must stay breached before alerting
+	WorkingDir       string     `gorm:"default:''" json:"working_dir"`            // Launch directory, relative to FolderPath (empty = FolderPath)
+	ExtraArgs        string     `gorm:"default:''" json:"extra_args"`             // Arguments appended to the startup command at launch
+	AutoBackupOnStop bool       `gorm:"default:false" json:"auto_backup_on_stop"` // Take a backup after the server is stopped
+	FileMode         string     `gorm:"default:''" json:"file_mode"`              // Octal mode for files the panel creates (empty = 0644)
+	DirMode          string     `gorm:"default:''" json:"dir_mode"`               // Octal mode for directories the panel creates (empty = 0755)
+	ReloadCommand    string     `gorm:"default:''" json:"reload_command"`         // Console command that reloads the server in place (empty = not supported)
+	LogPattern       string     `gorm:"default:''" json:"log_pattern"`            // Regexp with a "time" group (and optional "level", "msg") splitting log lines (empty = raw lines)
+	LogTimeLayout    string     `gorm:"default:''" json:"log_time_layout"`        // Go time layout of the "time" group, e.g. 15:04:05
+	ConsoleAllowed   string     `gorm:"default:''" json:"console_allowed"`        // Comma-separated commands the live console may send (empty = any)
+	ConsoleBlocked   string     `gorm:"default:''" json:"console_blocked"`        // Comma-separated commands the live console may not send
+	ProtectedPaths   string     `gorm:"default:''" json:"protected_paths"`        // Comma-separated glob patterns of files the file manager may not change
+	AutoStartOnBoot  bool       `gorm:"default:false" json:"auto_start_on_boot"`  // Start the server when the panel starts
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	UserID           uint       `gorm:"not null" json:"user_id"`
 }
 
+// Backup count limits for a server
+const (
+	DefaultMaxBackups = 1
+	MaxBackupsLimit   = 3
+)
+
+// Default modes for files and directories the panel creates
+const (
+	DefaultFileMode os.FileMode = 0644
+	DefaultDirMode  os.FileMode = 0755
+)
+
 // CreateServer creates a new server entry
 func CreateServer(name, folderPath, startupCommand string, userID uint) (*Server, error) {
 	server := &Server{
@@ -27,7 +65,7 @@ func CreateServer(name, folderPath, startupCommand string, userID uint) (*Server
 		FolderPath:     folderPath,
 		StartupCommand: startupCommand,
 		Status:         "offline",
-		MaxBackups:     1, // Default value
+		MaxBackups:     DefaultMaxBackups,
 		BackupPath:     "", // Empty by default
 		UserID:         userID,
 	}
@@ -57,6 +95,43 @@ func GetServerByName(name string, userID uint) (*Server, error) {
 	return &server, nil
 }
 
+// GetServerByFolderPath retrieves the server that owns a folder, if any
+func GetServerByFolderPath(folderPath string) (*Server, error) {
+	var server Server
+	if err := DB.Where("folder_path = ?", filepath.Clean(folderPath)).First(&server).Error; err != nil {
+		return nil, err
+	}
+	return &server, nil
+}
+
+// GetServerByNameAnyUser retrieves a server by name without an owner check,
+// for callers that authenticate by other means (e.g. signed webhook triggers)
+func GetServerByNameAnyUser(name string) (*Server, error) {
+	var server Server
+	if err := DB.Where("name = ?", name).First(&server).Error; err != nil {
+		return nil, err
+	}
+	return &server, nil
+}
+
+// GetAllServers retrieves every server, for panel-wide maintenance tasks
+func GetAllServers() ([]Server, error) {
+	var servers []Server
+	if err := DB.Find(&servers).Error; err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// GetAutoStartServers retrieves the servers flagged to start when the panel starts, oldest first
+func GetAutoStartServers() ([]Server, error) {
+	var servers []Server
+	if err := DB.Where("auto_start_on_boot = ?", true).Order("id").Find(&servers).Error; err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
 // GetServersByUserID retrieves all servers for a user
 func GetServersByUserID(userID uint) ([]Server, error) {
 	var servers []Server
@@ -66,32 +141,443 @@ func GetServersByUserID(userID uint) ([]Server, error) {
 	return servers, nil
 }
 
+// CountServersByUserID returns how many servers a user has
+func CountServersByUserID(userID uint) (int64, error) {
+	var count int64
+	if err := DB.Model(&Server{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetServersByTag retrieves all servers of a user carrying the given tag
+func GetServersByTag(userID uint, tag string) ([]Server, error) {
+	var candidates []Server
+	if err := DB.Where("user_id = ? AND tags LIKE ?", userID, "%"+tag+"%").Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	// LIKE is only a prefilter; match whole tags exactly
+	servers := make([]Server, 0, len(candidates))
+	for _, server := range candidates {
+		if server.HasTag(tag) {
+			servers = append(servers, server)
+		}
+	}
+	return servers, nil
+}
+
+// GetTags returns the server's tags as a list
+func (s *Server) GetTags() []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(s.Tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// HasTag checks whether the server carries a tag (case-insensitive)
+func (s *Server) HasTag(tag string) bool {
+	for _, t := range s.GetTags() {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateTags replaces the server's tags
+func (s *Server) UpdateTags(tags []string) error {
+	cleaned := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+	}
+
+	s.Tags = strings.Join(cleaned, ",")
+	return DB.Save(s).Error
+}
+
+// FileRootPath returns the directory the file manager treats as its root.
+// The security boundary for file operations stays at FolderPath.
+func (s *Server) FileRootPath() string {
+	if s.FileRoot == "" {
+		return s.FolderPath
+	}
+
+	root := filepath.Join(s.FolderPath, s.FileRoot)
+	if !strings.HasPrefix(root, s.FolderPath) {
+		return s.FolderPath
+	}
+	return root
+}
+
+// Rename changes the server's name. The folder and all associations are keyed by
+// the server ID or folder path, so they are left as they are.
+func (s *Server) Rename(name string) error {
+	if err := DB.Model(s).Update("name", name).Error; err != nil {
+		return err
+	}
+	s.Name = name
+	return nil
+}
+
+// UpdateFileRoot sets the file manager root subpath (empty resets it to FolderPath)
+func (s *Server) UpdateFileRoot(fileRoot string) error {
+	fileRoot = strings.TrimSpace(fileRoot)
+	if fileRoot != "" {
+		fileRoot = filepath.Clean(strings.TrimPrefix(fileRoot, "/"))
+		if fileRoot == "." {
+			fileRoot = ""
+		} else if fileRoot == ".." || strings.HasPrefix(fileRoot, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("file root must be inside the server folder")
+		}
+
+		info, err := os.Stat(filepath.Join(s.FolderPath, fileRoot))
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("file root must be an existing directory")
+		}
+	}
+
+	s.FileRoot = fileRoot
+	return DB.Save(s).Error
+}
+
+// UpdateFileModes sets the octal modes for files and directories created through the panel.
+// Empty values restore the defaults.
+func (s *Server) UpdateFileModes(fileMode, dirMode string) error {
+	fileMode = strings.TrimSpace(fileMode)
+	dirMode = strings.TrimSpace(dirMode)
+
+	if fileMode != "" {
+		mode, err := parseOctalMode(fileMode)
+		if err != nil {
+			return fmt.Errorf("invalid file mode: %w", err)
+		}
+		if mode&0600 != 0600 {
+			return fmt.Errorf("invalid file mode: owner must be able to read and write")
+		}
+		fileMode = fmt.Sprintf("%04o", mode)
+	}
+	if dirMode != "" {
+		mode, err := parseOctalMode(dirMode)
+		if err != nil {
+			return fmt.Errorf("invalid directory mode: %w", err)
+		}
+		if mode&0700 != 0700 {
+			return fmt.Errorf("invalid directory mode: owner must be able to read, write and enter")
+		}
+		dirMode = fmt.Sprintf("%04o", mode)
+	}
+
+	s.FileMode = fileMode
+	s.DirMode = dirMode
+	return DB.Save(s).Error
+}
+
+// FilePerm returns the mode for files the panel creates in this server
+func (s *Server) FilePerm() os.FileMode {
+	if mode, err := parseOctalMode(s.FileMode); err == nil {
+		return mode
+	}
+	return DefaultFileMode
+}
+
+// DirPerm returns the mode for directories the panel creates in this server
+func (s *Server) DirPerm() os.FileMode {
+	if mode, err := parseOctalMode(s.DirMode); err == nil {
+		return mode
+	}
+	return DefaultDirMode
+}
+
+// parseOctalMode parses permission bits such as "644" or "0775". Special bits
+// (setuid, setgid, sticky) are rejected.
+func parseOctalMode(value string) (os.FileMode, error) {
+	if value == "" {
+		return 0, fmt.Errorf("mode is empty")
+	}
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an octal mode", value)
+	}
+	if mode > 0777 {
+		return 0, fmt.Errorf("%q must only set permission bits (at most 0777)", value)
+	}
+	return os.FileMode(mode), nil
+}
+
+// UpdateAlertSettings updates the server's resource alert thresholds
+func (s *Server) UpdateAlertSettings(cpuPercent, memPercent float64, duration int) error {
+	if cpuPercent < 0 || memPercent < 0 {
+		return fmt.Errorf("alert thresholds cannot be negative")
+	}
+	if memPercent > 100 {
+		return fmt.Errorf("memory alert threshold cannot exceed 100%%")
+	}
+	if duration < 10 {
+		return fmt.Errorf("alert duration must be at least 10 seconds")
+	}
+
+	s.AlertCPUPercent = cpuPercent
+	s.AlertMemPercent = memPercent
+	s.AlertDuration = duration
+	return DB.Save(s).Error
+}
+
+// HasAlerts reports whether any resource alert threshold is configured
+func (s *Server) HasAlerts() bool {
+	return s.AlertCPUPercent > 0 || s.AlertMemPercent > 0
+}
+
+// UpdateTriggerSecret sets the webhook trigger secret (empty disables triggers)
+func (s *Server) UpdateTriggerSecret(secret string) error {
+	s.TriggerSecret = secret
+	return DB.Save(s).Error
+}
+
 // UpdateStartupCommand updates the server's startup command
 func (s *Server) UpdateStartupCommand(command string) error {
 	s.StartupCommand = command
 	return DB.Save(s).Error
 }
 
+// LaunchDir returns the directory the server process is started in
+func (s *Server) LaunchDir() string {
+	if s.WorkingDir == "" {
+		return s.FolderPath
+	}
+
+	dir := filepath.Join(s.FolderPath, s.WorkingDir)
+	if !strings.HasPrefix(dir, s.FolderPath) {
+		return s.FolderPath
+	}
+	return dir
+}
+
+// LaunchArgs returns the startup command split into arguments, with the extra args appended
+func (s *Server) LaunchArgs() []string {
+	return append(strings.Fields(s.StartupCommand), strings.Fields(s.ExtraArgs)...)
+}
+
+// UpdateLaunchOptions sets the working directory (relative to FolderPath, empty resets it)
+// and the extra arguments used when starting the server
+func (s *Server) UpdateLaunchOptions(workingDir, extraArgs string) error {
+	workingDir = strings.TrimSpace(workingDir)
+	if workingDir != "" {
+		workingDir = filepath.Clean(strings.TrimPrefix(workingDir, "/"))
+		if workingDir == "." {
+			workingDir = ""
+		} else if workingDir == ".." || strings.HasPrefix(workingDir, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("working directory must be inside the server folder")
+		}
+
+		info, err := os.Stat(filepath.Join(s.FolderPath, workingDir))
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("working directory must be an existing directory")
+		}
+	}
+
+	// Arguments are passed to the process directly, never through a shell,
+	// so only line breaks need rejecting to keep the stored value single-line
+	extraArgs = strings.TrimSpace(extraArgs)
+	if strings.ContainsAny(extraArgs, "\r\n") {
+		return fmt.Errorf("extra arguments must be on a single line")
+	}
+
+	s.WorkingDir = workingDir
+	s.ExtraArgs = extraArgs
+	return DB.Save(s).Error
+}
+
+// UpdateReloadCommand sets the console command used to reload the server without a restart
+func (s *Server) UpdateReloadCommand(command string) error {
+	command = strings.TrimSpace(command)
+	if strings.ContainsAny(command, "\r\n") {
+		return fmt.Errorf("reload command must be a single line")
+	}
+
+	s.ReloadCommand = command
+	return DB.Save(s).Error
+}
+
+// UpdateAutoStart sets whether the server is started when the panel starts
+func (s *Server) UpdateAutoStart(enabled bool) error {
+	s.AutoStartOnBoot = enabled
+	return DB.Model(s).UpdateColumn("auto_start_on_boot", enabled).Error
+}
+
+// UpdateLogFormat sets how log lines are split into timestamp, level and message. An empty
+// pattern turns parsing off.
+func (s *Server) UpdateLogFormat(pattern, timeLayout string) error {
+	pattern = strings.TrimSpace(pattern)
+	timeLayout = strings.TrimSpace(timeLayout)
+
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid log pattern: %v", err)
+		}
+		if re.SubexpIndex("time") < 0 {
+			return fmt.Errorf("log pattern needs a (?P<time>...) group")
+		}
+		if timeLayout == "" {
+			return fmt.Errorf("log time layout is required with a log pattern")
+		}
+	} else {
+		timeLayout = ""
+	}
+
+	s.LogPattern = pattern
+	s.LogTimeLayout = timeLayout
+	return DB.Save(s).Error
+}
+
+// UpdateConsolePolicy sets which commands may be typed into the live console. Entries are
+// command names such as "stop" or "op"; an empty allowed list allows every command not blocked.
+func (s *Server) UpdateConsolePolicy(allowed, blocked []string) error {
+	s.ConsoleAllowed = strings.Join(cleanCommandNames(allowed), ",")
+	s.ConsoleBlocked = strings.Join(cleanCommandNames(blocked), ",")
+	return DB.Save(s).Error
+}
+
+// GetConsoleAllowed returns the commands the live console is limited to, empty for any
+func (s *Server) GetConsoleAllowed() []string {
+	return splitCommandNames(s.ConsoleAllowed)
+}
+
+// GetConsoleBlocked returns the commands the live console may not send
+func (s *Server) GetConsoleBlocked() []string {
+	return splitCommandNames(s.ConsoleBlocked)
+}
+
+// CheckConsoleCommand returns an error when the console policy forbids a command. Commands
+// are matched on their name, ignoring case, a leading slash and a namespace such as
+// "minecraft:", so "/Minecraft:stop now" counts as "stop".
+func (s *Server) CheckConsoleCommand(command string) error {
+	name := consoleCommandName(command)
+
+	for _, blocked := range s.GetConsoleBlocked() {
+		if name == blocked {
+			return fmt.Errorf("the %q command is blocked in the console for this server", name)
+		}
+	}
+
+	allowed := s.GetConsoleAllowed()
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, entry := range allowed {
+		if name == entry {
+			return nil
+		}
+	}
+	return fmt.Errorf("the %q command is not in the console's allowed commands for this server", name)
+}
+
+// consoleCommandName extracts the normalized command name from a console line
+func consoleCommandName(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	name := strings.ToLower(strings.TrimPrefix(fields[0], "/"))
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// cleanCommandNames normalizes and de-duplicates a list of command names
+func cleanCommandNames(names []string) []string {
+	cleaned := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = consoleCommandName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		cleaned = append(cleaned, name)
+	}
+	return cleaned
+}
+
+// splitCommandNames splits a stored comma-separated command list
+func splitCommandNames(list string) []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// UpdateProtectedPaths sets the glob patterns of files the file manager refuses to overwrite,
+// delete, rename or move. Patterns containing a slash match the path relative to the server
+// folder, others match the file name anywhere in it.
+func (s *Server) UpdateProtectedPaths(patterns []string) error {
+	cleaned := make([]string, 0, len(patterns))
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(filepath.ToSlash(pattern))
+		if pattern == "" || seen[pattern] {
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid protected path pattern %q", pattern)
+		}
+		seen[pattern] = true
+		cleaned = append(cleaned, pattern)
+	}
+
+	s.ProtectedPaths = strings.Join(cleaned, ",")
+	return DB.Save(s).Error
+}
+
+// GetProtectedPaths returns the glob patterns of protected files
+func (s *Server) GetProtectedPaths() []string {
+	return SplitPathPatterns(s.ProtectedPaths)
+}
+
+// ProtectedPattern reports which protected path pattern, if any, matches a file in the server folder
+func (s *Server) ProtectedPattern(path string) (string, bool) {
+	return MatchPathPattern(s.FolderPath, path, s.GetProtectedPaths())
+}
+
 // UpdateBackupSettings updates the server's backup settings
-func (s *Server) UpdateBackupSettings(backupPath string, maxBackups int) error {
-	// Validate maxBackups (1-3)
+func (s *Server) UpdateBackupSettings(backupPath string, maxBackups int, wrapInFolder, autoBackupOnStop bool) error {
+	// Validate maxBackups (1-MaxBackupsLimit)
 	if maxBackups < 1 {
 		maxBackups = 1
 	}
-	if maxBackups > 3 {
-		maxBackups = 3
+	if maxBackups > MaxBackupsLimit {
+		maxBackups = MaxBackupsLimit
 	}
 
 	s.BackupPath = backupPath
 	s.MaxBackups = maxBackups
+	s.WrapBackups = wrapInFolder
+	s.AutoBackupOnStop = autoBackupOnStop
 	return DB.Save(s).Error
 }
 
 // GetBackupSettings returns the backup settings for the server
 func (s *Server) GetBackupSettings() map[string]interface{} {
 	return map[string]interface{}{
-		"backup_path": s.BackupPath,
-		"max_backups": s.MaxBackups,
+		"backup_path":         s.BackupPath,
+		"max_backups":         s.MaxBackups,
+		"wrap_in_folder":      s.WrapBackups,
+		"auto_backup_on_stop": s.AutoBackupOnStop,
 	}
 }
 
@@ -122,7 +608,7 @@ func (s *Server) FormatUptime() string {
 	}
 
 	uptime := s.GetUptime()
-	
+
 	days := int(uptime.Hours() / 24)
 	hours := int(uptime.Hours()) % 24
 	minutes := int(uptime.Minutes()) % 60
@@ -147,7 +633,21 @@ func formatDurationM(m int) string {
 	return fmt.Sprintf("%dm", m)
 }
 
-// DeleteServer deletes a server
+// Delete deletes a server along with its schedules and backup records
 func (s *Server) Delete() error {
-	return DB.Delete(s).Error
-}
\ No newline at end of file
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("server_id = ?", s.ID).Delete(&ScheduleRun{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("server_id = ?", s.ID).Delete(&Schedule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("server_id = ?", s.ID).Delete(&Backup{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("server_id = ?", s.ID).Delete(&CommandMacro{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(s).Error
+	})
+}
@@ -2,31 +2,36 @@ package models
 
 import (
 	"errors"
+	"log"
 	"time"
 
+	"seiapanel/config"
+
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // User represents a user account
 type User struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Username  string    `gorm:"unique;not null" json:"username"`
-	Password  string    `gorm:"not null" json:"-"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	Username        string    `gorm:"unique;not null" json:"username"`
+	Password        string    `gorm:"not null" json:"-"`
+	SchedulesPaused bool      `gorm:"default:false" json:"schedules_paused"` // Skip all schedule and backup policy runs
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
-// CreateUser creates a new user with hashed password
-func CreateUser(username, password string) (*User, error) {
-	// Check if username already exists
-	var existingUser User
-	if err := DB.Where("username = ?", username).First(&existingUser).Error; err == nil {
-		return nil, errors.New("username already exists")
-	}
+// MaxUsers is the number of accounts allowed (SeiaPanel is a single-user system)
+const MaxUsers = 1
 
+// ErrUserLimitReached is returned when creating a user would exceed MaxUsers
+var ErrUserLimitReached = errors.New("registration is disabled, an account already exists")
+
+// CreateUser creates a new user with hashed password. The user limit is enforced here,
+// so it holds no matter which handler calls it.
+func CreateUser(username, password string) (*User, error) {
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), config.GetBcryptCost())
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +42,25 @@ func CreateUser(username, password string) (*User, error) {
 		Password: string(hashedPassword),
 	}
 
-	if err := DB.Create(user).Error; err != nil {
+	// Count and insert in one transaction so concurrent registrations can't both pass the check
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&User{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= MaxUsers {
+			return ErrUserLimitReached
+		}
+
+		// Check if username already exists
+		var existingUser User
+		if err := tx.Where("username = ?", username).First(&existingUser).Error; err == nil {
+			return errors.New("username already exists")
+		}
+
+		return tx.Create(user).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -61,9 +84,33 @@ func ValidateCredentials(username, password string) (*User, error) {
 		return nil, errors.New("invalid username or password")
 	}
 
+	// Upgrade hashes made with a lower cost while the plain password is at hand
+	if err := user.rehashPasswordIfNeeded(password); err != nil {
+		log.Printf("⚠️  Failed to rehash password for user %s: %v", user.Username, err)
+	}
+
 	return &user, nil
 }
 
+// rehashPasswordIfNeeded rehashes the password at the configured cost when the stored hash is weaker
+func (u *User) rehashPasswordIfNeeded(password string) error {
+	cost, err := bcrypt.Cost([]byte(u.Password))
+	if err != nil || cost >= config.GetBcryptCost() {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), config.GetBcryptCost())
+	if err != nil {
+		return err
+	}
+
+	if err := DB.Model(u).UpdateColumn("password", string(hashedPassword)).Error; err != nil {
+		return err
+	}
+	u.Password = string(hashedPassword)
+	return nil
+}
+
 // GetUserByID retrieves a user by ID
 func GetUserByID(id uint) (*User, error) {
 	var user User
@@ -94,6 +141,12 @@ func (u *User) UpdateUsername(newUsername string) error {
 	return DB.Save(u).Error
 }
 
+// SetSchedulesPaused pauses or resumes all of the user's scheduled automation
+func (u *User) SetSchedulesPaused(paused bool) error {
+	u.SchedulesPaused = paused
+	return DB.Model(u).UpdateColumn("schedules_paused", paused).Error
+}
+
 // UpdatePassword updates the user's password
 func (u *User) UpdatePassword(currentPassword, newPassword string) error {
 	// Verify current password
@@ -102,11 +155,11 @@ func (u *User) UpdatePassword(currentPassword, newPassword string) error {
 	}
 
 	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), config.GetBcryptCost())
 	if err != nil {
 		return err
 	}
 
 	u.Password = string(hashedPassword)
 	return DB.Save(u).Error
-}
\ No newline at end of file
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"seiapanel/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestValidateCredentialsUpgradesHash checks that a login with a hash below the configured
+// cost stores a new hash at that cost
+func TestValidateCredentialsUpgradesHash(t *testing.T) {
+	setupTestDB(t)
+
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{BcryptCost: bcrypt.MinCost + 1}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+
+	weakHash, err := bcrypt.GenerateFromPassword([]byte("hunter22"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DB.Create(&User{Username: "admin", Password: string(weakHash)}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ValidateCredentials("admin", "hunter22"); err != nil {
+		t.Fatalf("ValidateCredentials: %v", err)
+	}
+
+	stored, err := GetUserByUsername("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cost, err := bcrypt.Cost([]byte(stored.Password))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != bcrypt.MinCost+1 {
+		t.Errorf("stored hash cost = %d, want %d", cost, bcrypt.MinCost+1)
+	}
+	if _, err := ValidateCredentials("admin", "hunter22"); err != nil {
+		t.Errorf("ValidateCredentials with the upgraded hash: %v", err)
+	}
+}
+
+// TestCreateUserSingleUser checks that CreateUser itself refuses a second account
+func TestCreateUserSingleUser(t *testing.T) {
+	setupTestDB(t)
+
+	if _, err := CreateUser("admin", "hunter22"); err != nil {
+		t.Fatalf("first CreateUser: %v", err)
+	}
+	if _, err := CreateUser("intruder", "hunter22"); !errors.Is(err, ErrUserLimitReached) {
+		t.Fatalf("second CreateUser error = %v, want ErrUserLimitReached", err)
+	}
+
+	var count int64
+	if err := DB.Model(&User{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("user count = %d, want 1", count)
+	}
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"seiapanel/models"
+)
+
+// resourceSampleInterval is how often running servers are sampled for resource alerts
+const resourceSampleInterval = 10 * time.Second
+
+// clockTicksPerSecond is the kernel USER_HZ used by /proc/[pid]/stat times (100 on Linux)
+const clockTicksPerSecond = 100
+
+// AlertState is the current resource usage and alert status of a running server
+type AlertState struct {
+	CPUPercent    float64    `json:"cpu_percent"` // Percent of one core, like top
+	MemPercent    float64    `json:"mem_percent"` // Percent of total system memory
+	Breached      []string   `json:"breached"`    // Thresholds currently exceeded: cpu, memory
+	BreachedSince *time.Time `json:"breached_since"`
+	Alerting      bool       `json:"alerting"`
+	AlertingSince *time.Time `json:"alerting_since"`
+}
+
+// serverResourceState tracks sampling and debounce state for a server
+type serverResourceState struct {
+	AlertState
+	pid        int
+	lastTicks  uint64
+	lastSample time.Time
+	clearSince *time.Time
+}
+
+var (
+	resourceStates  = make(map[uint]*serverResourceState)
+	resourceMux     sync.Mutex
+	resourceMonOnce sync.Once
+)
+
+// StartResourceMonitor starts sampling running servers and evaluating their alert thresholds
+func StartResourceMonitor() {
+	resourceMonOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(resourceSampleInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				sampleRunningServers()
+			}
+		}()
+		log.Println("✅ Resource monitor started")
+	})
+}
+
+// GetAlertState returns a copy of a server's current alert state, or nil if it isn't being sampled
+func GetAlertState(serverID uint) *AlertState {
+	resourceMux.Lock()
+	defer resourceMux.Unlock()
+
+	state, exists := resourceStates[serverID]
+	if !exists {
+		return nil
+	}
+
+	snapshot := state.AlertState
+	snapshot.Breached = append([]string{}, state.Breached...)
+	return &snapshot
+}
+
+// sampleRunningServers samples every running server once
+func sampleRunningServers() {
+	// Snapshot running processes so the server lock isn't held while sampling
+	serverMux.Lock()
+	pids := make(map[uint]int, len(runningServers))
+	for id, sp := range runningServers {
+		pids[id] = sp.Cmd.Process.Pid
+	}
+	serverMux.Unlock()
+
+	var totalMemory uint64
+	if memStats, err := GetMemoryStats(); err == nil {
+		totalMemory = memStats.Total
+	}
+
+	// Reload settings so threshold changes apply without a restart. This is done before
+	// taking the lock, so readers of the resource states don't wait on the database.
+	servers := make(map[uint]*models.Server, len(pids))
+	for id := range pids {
+		if server, err := models.GetServerByID(id); err == nil {
+			servers[id] = server
+		}
+	}
+
+	resourceMux.Lock()
+	defer resourceMux.Unlock()
+
+	// Forget servers that stopped (a restart gets a fresh state)
+	for id, state := range resourceStates {
+		if pid, running := pids[id]; !running || pid != state.pid {
+			delete(resourceStates, id)
+		}
+	}
+
+	now := time.Now()
+	for id, pid := range pids {
+		state, exists := resourceStates[id]
+		if !exists {
+			state = &serverResourceState{pid: pid}
+			resourceStates[id] = state
+		}
+
+		ticks, err := getProcessCPUTicks(pid)
+		if err != nil {
+			continue
+		}
+		if !state.lastSample.IsZero() {
+			elapsed := now.Sub(state.lastSample).Seconds()
+			if elapsed > 0 && ticks >= state.lastTicks {
+				state.CPUPercent = float64(ticks-state.lastTicks) / clockTicksPerSecond / elapsed * 100
+			}
+		}
+		state.lastTicks = ticks
+		state.lastSample = now
+
+		if memoryKB, err := getProcessMemory(pid); err == nil && totalMemory > 0 {
+			state.MemPercent = float64(memoryKB*1024) / float64(totalMemory) * 100
+		}
+
+		if server, ok := servers[id]; ok {
+			evaluateAlert(server, state, now)
+		}
+	}
+}
+
+// evaluateAlert applies a server's thresholds to its latest sample. An alert fires once the
+// thresholds stay breached for the configured duration, and recovers once they stay clear
+// for the same duration, so brief spikes and dips don't spam notifications.
+func evaluateAlert(server *models.Server, state *serverResourceState, now time.Time) {
+	breached := make([]string, 0, 2)
+	if server.AlertCPUPercent > 0 && state.CPUPercent >= server.AlertCPUPercent {
+		breached = append(breached, "cpu")
+	}
+	if server.AlertMemPercent > 0 && state.MemPercent >= server.AlertMemPercent {
+		breached = append(breached, "memory")
+	}
+	state.Breached = breached
+
+	duration := time.Duration(server.AlertDuration) * time.Second
+
+	if len(breached) > 0 {
+		state.clearSince = nil
+		if state.BreachedSince == nil {
+			state.BreachedSince = &now
+		}
+
+		if !state.Alerting && now.Sub(*state.BreachedSince) >= duration {
+			state.Alerting = true
+			state.AlertingSince = &now
+			log.Printf("⚠️  Resource alert for '%s': %s (CPU %.1f%%, memory %.1f%%)", server.Name, strings.Join(breached, ", "), state.CPUPercent, state.MemPercent)
+			Notify(EventResourceAlert, server.Name,
+				fmt.Sprintf("Resource alert: %s over threshold for %s (CPU %.1f%%, memory %.1f%%)", strings.Join(breached, " and "), duration, state.CPUPercent, state.MemPercent),
+				alertNotificationData(server, state))
+		}
+		return
+	}
+
+	state.BreachedSince = nil
+	if !state.Alerting {
+		return
+	}
+
+	if state.clearSince == nil {
+		state.clearSince = &now
+	}
+	if now.Sub(*state.clearSince) >= duration {
+		state.Alerting = false
+		state.AlertingSince = nil
+		state.clearSince = nil
+		log.Printf("✅ Resource alert for '%s' recovered", server.Name)
+		Notify(EventResourceRecovery, server.Name,
+			fmt.Sprintf("Resource usage recovered (CPU %.1f%%, memory %.1f%%)", state.CPUPercent, state.MemPercent),
+			alertNotificationData(server, state))
+	}
+}
+
+// alertNotificationData builds the data payload for alert notifications
+func alertNotificationData(server *models.Server, state *serverResourceState) map[string]interface{} {
+	return map[string]interface{}{
+		"cpu_percent":       state.CPUPercent,
+		"mem_percent":       state.MemPercent,
+		"breached":          state.Breached,
+		"alert_cpu_percent": server.AlertCPUPercent,
+		"alert_mem_percent": server.AlertMemPercent,
+		"alert_duration":    server.AlertDuration,
+	}
+}
+
+// getProcessCPUTicks reads the total user+system CPU time of a process from /proc/[pid]/stat
+func getProcessCPUTicks(pid int) (uint64, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, err
+	}
+
+	// The command name may contain spaces, so parse fields after its closing parenthesis
+	content := string(data)
+	end := strings.LastIndex(content, ")")
+	if end < 0 {
+		return 0, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+
+	// Fields after ")" start at field 3 (state); utime and stime are fields 14 and 15
+	fields := strings.Fields(content[end+1:])
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return utime + stime, nil
+}
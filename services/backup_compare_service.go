@@ -0,0 +1,109 @@
+package services
+
+import (
+	"archive/tar"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BackupFileInfo describes a file as recorded in a backup archive
+type BackupFileInfo struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// BackupFileChange is a file present in both backups whose size or modification time differs
+type BackupFileChange struct {
+	Path   string         `json:"path"`
+	Before BackupFileInfo `json:"before"`
+	After  BackupFileInfo `json:"after"`
+}
+
+// BackupComparison lists how the files of one backup differ from an older one
+type BackupComparison struct {
+	Added     []BackupFileInfo   `json:"added"`
+	Removed   []BackupFileInfo   `json:"removed"`
+	Changed   []BackupFileChange `json:"changed"`
+	Unchanged int                `json:"unchanged"`
+}
+
+// ListBackupFiles reads a backup's entry headers and returns its files keyed by their path
+// relative to the server folder, without extracting anything. Folders and the manifest are
+// left out, and a top-level wrapper folder is stripped like restores do.
+func ListBackupFiles(backupFilePath string) (map[string]BackupFileInfo, error) {
+	rootPrefix, err := BackupRootFolder(backupFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]BackupFileInfo)
+	err = WalkBackupArchive(backupFilePath, func(header *tar.Header, content io.Reader) error {
+		if header.Typeflag == tar.TypeDir {
+			return nil
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if name == "" || isBackupManifestEntry(name, rootPrefix) {
+			return nil
+		}
+		if rootPrefix != "" {
+			name = strings.TrimPrefix(name, rootPrefix+"/")
+		}
+
+		files[name] = BackupFileInfo{
+			Path:    name,
+			Size:    header.Size,
+			ModTime: header.ModTime,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// CompareBackups lists the files added, removed and changed going from the older backup to
+// the newer one. Files count as changed when their size or modification time differs.
+func CompareBackups(olderFilePath, newerFilePath string) (*BackupComparison, error) {
+	before, err := ListBackupFiles(olderFilePath)
+	if err != nil {
+		return nil, err
+	}
+	after, err := ListBackupFiles(newerFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BackupComparison{
+		Added:   []BackupFileInfo{},
+		Removed: []BackupFileInfo{},
+		Changed: []BackupFileChange{},
+	}
+	for name, newer := range after {
+		older, ok := before[name]
+		switch {
+		case !ok:
+			result.Added = append(result.Added, newer)
+		case older.Size != newer.Size || older.ModTime.Unix() != newer.ModTime.Unix():
+			result.Changed = append(result.Changed, BackupFileChange{Path: name, Before: older, After: newer})
+		default:
+			result.Unchanged++
+		}
+	}
+	for name, older := range before {
+		if _, ok := after[name]; !ok {
+			result.Removed = append(result.Removed, older)
+		}
+	}
+
+	sort.Slice(result.Added, func(i, j int) bool { return result.Added[i].Path < result.Added[j].Path })
+	sort.Slice(result.Removed, func(i, j int) bool { return result.Removed[i].Path < result.Removed[j].Path })
+	sort.Slice(result.Changed, func(i, j int) bool { return result.Changed[i].Path < result.Changed[j].Path })
+
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"seiapanel/config"
+	"seiapanel/models"
+)
+
+// backupDirsTTL bounds how long the set of backup folders is reused between lookups
+const backupDirsTTL = 30 * time.Second
+
+var (
+	backupDirs         map[string]bool
+	backupDirsLoadedAt time.Time
+	backupDirsVersion  uint64 // Bumped on invalidation, so a load that raced it isn't kept
+	backupDirsMux      sync.Mutex
+)
+
+// IsBackupDir reports whether path is the backup folder of any server. Walks over server
+// folders (backups, folder sizes, archives, copies) skip these so a backup folder configured
+// inside a server folder doesn't end up in every new backup, unless include_backup_dirs is set.
+func IsBackupDir(path string) bool {
+	if config.GetIncludeBackupDirs() {
+		return false
+	}
+
+	backupDirsMux.Lock()
+	dirs, version := backupDirs, backupDirsVersion
+	stale := dirs == nil || time.Since(backupDirsLoadedAt) > backupDirsTTL
+	backupDirsMux.Unlock()
+
+	// Servers are loaded without holding the lock, so walks don't queue behind the query
+	if stale {
+		if loaded, err := loadBackupDirs(); err == nil {
+			dirs = loaded
+			backupDirsMux.Lock()
+			if backupDirsVersion == version {
+				backupDirs = loaded
+				backupDirsLoadedAt = time.Now()
+			}
+			backupDirsMux.Unlock()
+		}
+		// On error the previous set is kept rather than walking into backups
+	}
+
+	return dirs[filepath.Clean(path)]
+}
+
+// loadBackupDirs reads the backup folder of every server, as given and with links resolved
+func loadBackupDirs() (map[string]bool, error) {
+	servers, err := models.GetAllServers()
+	if err != nil {
+		return nil, err
+	}
+	dirs := make(map[string]bool, len(servers))
+	for _, server := range servers {
+		if server.BackupPath != "" {
+			dirs[filepath.Clean(server.BackupPath)] = true
+			dirs[resolvePath(server.BackupPath)] = true
+		}
+	}
+	return dirs, nil
+}
+
+// InvalidateBackupDirs drops the cached backup folders, for use after a backup path changes
+func InvalidateBackupDirs() {
+	backupDirsMux.Lock()
+	backupDirs = nil
+	backupDirsVersion++
+	backupDirsMux.Unlock()
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"seiapanel/config"
+	"seiapanel/models"
+)
+
+// TestBackupDirSkippedInServerFolder checks that a backup folder inside the server folder is
+// recognized and left out of backups and folder sizes, unless include_backup_dirs is set
+func TestBackupDirSkippedInServerFolder(t *testing.T) {
+	setupTestDB(t)
+
+	folder := t.TempDir()
+	backupPath := filepath.Join(folder, "backups")
+	writeTestFile(t, folder, "server.properties", "motd=hi")
+	writeTestFile(t, folder, "backups/old.tar.gz", "previous backup")
+
+	server := &models.Server{Name: "survival", FolderPath: folder, StartupCommand: "java -jar server.jar", BackupPath: backupPath}
+	if err := models.DB.Create(server).Error; err != nil {
+		t.Fatal(err)
+	}
+	InvalidateBackupDirs()
+
+	if !IsBackupDir(backupPath) {
+		t.Errorf("IsBackupDir(%q) = false, want true", backupPath)
+	}
+	if IsBackupDir(folder) {
+		t.Errorf("IsBackupDir(%q) = true for the server folder", folder)
+	}
+
+	stats, err := GetDirStats(context.Background(), folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.ItemCount != 1 {
+		t.Errorf("GetDirStats counted %d items, want only server.properties", stats.ItemCount)
+	}
+
+	archive, _, err := CreateTarGzBackup(folder, t.TempDir(), "backup.tar.gz", "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := ListBackupFiles(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := files["backups/old.tar.gz"]; ok {
+		t.Error("backup includes the previous backups")
+	}
+	if _, ok := files["server.properties"]; !ok {
+		t.Error("backup is missing server.properties")
+	}
+
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{IncludeBackupDirs: true}
+	t.Cleanup(func() { config.AppConfig = previousConfig })
+	if IsBackupDir(backupPath) {
+		t.Error("IsBackupDir = true with include_backup_dirs set")
+	}
+}
+
+// TestBackupDirsInvalidate checks that a changed backup path is picked up after invalidation
+func TestBackupDirsInvalidate(t *testing.T) {
+	setupTestDB(t)
+
+	folder := t.TempDir()
+	server := &models.Server{Name: "survival", FolderPath: folder, StartupCommand: "java -jar server.jar", BackupPath: filepath.Join(folder, "old")}
+	if err := models.DB.Create(server).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !IsBackupDir(filepath.Join(folder, "old")) {
+		t.Fatal("old backup path not recognized")
+	}
+
+	if err := models.DB.Model(server).Update("backup_path", filepath.Join(folder, "new")).Error; err != nil {
+		t.Fatal(err)
+	}
+	InvalidateBackupDirs()
+
+	if IsBackupDir(filepath.Join(folder, "old")) {
+		t.Error("old backup path still recognized after invalidation")
+	}
+	if !IsBackupDir(filepath.Join(folder, "new")) {
+		t.Error("new backup path not recognized after invalidation")
+	}
+}
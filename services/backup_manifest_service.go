@@ -0,0 +1,223 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"seiapanel/models"
+)
+
+// BackupManifestName is the archive entry, at the top of the server folder, that describes a backup.
+// It is written first so it can be read without scanning the whole archive, and is never restored.
+const BackupManifestName = ".seiapanel-manifest.json"
+
+// backupManifestVersion is bumped when the manifest layout changes incompatibly
+const backupManifestVersion = 1
+
+// maxBackupManifestSize bounds how much of a manifest entry is read
+const maxBackupManifestSize = 4 * 1024 * 1024
+
+// ErrBackupManifestNotFound is returned for backups made before manifests were written
+var ErrBackupManifestNotFound = errors.New("backup has no manifest")
+
+// BackupManifest records panel configuration alongside a backup's files
+type BackupManifest struct {
+	Version   int                `json:"version"`
+	Server    string             `json:"server"`
+	ServerID  uint               `json:"server_id,omitempty"` // Missing from manifests written before it was recorded
+	CreatedAt time.Time          `json:"created_at"`
+	Schedules []ManifestSchedule `json:"schedules"`
+	// RootFolder is the top-level folder every entry is wrapped in, "" when entries sit at the
+	// archive root. Missing from manifests written before it was recorded.
+	RootFolder *string `json:"root_folder,omitempty"`
+}
+
+// ManifestSchedule is a schedule definition as stored in a backup manifest
+type ManifestSchedule struct {
+	Name           string `json:"name"`
+	CronMinute     string `json:"cron_minute"`
+	CronHour       string `json:"cron_hour"`
+	CronDayOfMonth string `json:"cron_day_of_month"`
+	CronMonth      string `json:"cron_month"`
+	CronDayOfWeek  string `json:"cron_day_of_week"`
+	Enabled        bool   `json:"enabled"`
+	Action         string `json:"action"`
+	Command        string `json:"command"`
+	CatchUp        bool   `json:"catch_up"`
+	SkipOverlap    bool   `json:"skip_overlap"`
+}
+
+// NewBackupManifest describes the current configuration of a server for a new backup. When
+// the schedules can't be loaded the manifest is still returned without them, along with the
+// error, so the backup's server and layout are recorded either way.
+func NewBackupManifest(server *models.Server) (*BackupManifest, error) {
+	manifest := &BackupManifest{
+		Version:   backupManifestVersion,
+		Server:    server.Name,
+		ServerID:  server.ID,
+		CreatedAt: time.Now(),
+		Schedules: []ManifestSchedule{},
+	}
+
+	schedules, err := models.GetSchedulesByServerID(server.ID)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to load schedules: %w", err)
+	}
+	for _, schedule := range schedules {
+		manifest.Schedules = append(manifest.Schedules, ManifestSchedule{
+			Name:           schedule.Name,
+			CronMinute:     schedule.CronMinute,
+			CronHour:       schedule.CronHour,
+			CronDayOfMonth: schedule.CronDayOfMonth,
+			CronMonth:      schedule.CronMonth,
+			CronDayOfWeek:  schedule.CronDayOfWeek,
+			Enabled:        schedule.Enabled,
+			Action:         schedule.Action,
+			Command:        schedule.Command,
+			CatchUp:        schedule.CatchUp,
+			SkipOverlap:    schedule.SkipOverlap,
+		})
+	}
+	return manifest, nil
+}
+
+// writeBackupManifest adds the manifest entry to an archive, inside rootFolder when the backup is wrapped
+func writeBackupManifest(tarWriter *tar.Writer, manifest *BackupManifest, rootFolder string) error {
+	manifest.RootFolder = &rootFolder
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Join(rootFolder, BackupManifestName),
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  manifest.CreatedAt,
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = tarWriter.Write(data)
+	return err
+}
+
+// ReadBackupManifest reads the manifest of a backup, or returns ErrBackupManifestNotFound
+// for backups made before manifests were written
+func ReadBackupManifest(backupFilePath string) (*BackupManifest, error) {
+	manifest, _, err := readLeadingManifest(backupFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+	if manifest == nil {
+		return nil, ErrBackupManifestNotFound
+	}
+	if manifest.Version > backupManifestVersion {
+		return nil, fmt.Errorf("backup manifest version %d is newer than this panel supports", manifest.Version)
+	}
+	return manifest, nil
+}
+
+// readLeadingManifest reads the manifest from the start of a backup, where CreateTarGzBackup
+// writes it, along with the folder it was found in. A nil manifest means the backup has none.
+func readLeadingManifest(backupFilePath string) (*BackupManifest, string, error) {
+	file, err := os.Open(backupFilePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+
+	// The manifest is the first entry, or the second after the wrapper folder
+	rootFolder := ""
+	for i := 0; i < 2; i++ {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read tar header: %w", err)
+		}
+
+		name := strings.TrimPrefix(path.Clean(filepath.ToSlash(header.Name)), "./")
+		if i == 0 && header.Typeflag == tar.TypeDir && !strings.Contains(name, "/") {
+			rootFolder = name
+			continue
+		}
+		if header.Typeflag != tar.TypeReg || !isBackupManifestEntry(name, rootFolder) {
+			return nil, "", nil
+		}
+		if header.Size > maxBackupManifestSize {
+			return nil, "", fmt.Errorf("backup manifest is too large")
+		}
+
+		var manifest BackupManifest
+		if err := json.NewDecoder(io.LimitReader(tarReader, maxBackupManifestSize)).Decode(&manifest); err != nil {
+			return nil, "", err
+		}
+		return &manifest, rootFolder, nil
+	}
+	return nil, "", nil
+}
+
+// BackupRootFolder returns the top-level folder a backup's entries are wrapped in, or "" when
+// they sit at the archive root, as recorded by its manifest. Only backups without a manifest
+// fall back to guessing from the archive layout.
+func BackupRootFolder(backupFilePath string) (string, error) {
+	manifest, manifestFolder, err := readLeadingManifest(backupFilePath)
+	if err != nil {
+		return "", err
+	}
+	if manifest == nil {
+		return detectBackupRootFolder(backupFilePath)
+	}
+	if manifest.RootFolder != nil {
+		return *manifest.RootFolder, nil
+	}
+	// Older manifests sit in the wrapper folder, so where it was found tells the layout
+	return manifestFolder, nil
+}
+
+// ErrBackupServerMismatch is returned when a backup's manifest names a different server
+var ErrBackupServerMismatch = errors.New("backup was made of a different server")
+
+// CheckBackupServer verifies from its manifest that a backup archive was made of server, so a
+// file swapped on disk isn't restored over the wrong server. Renamed servers still match by
+// ID. Backups made before manifests were written can't be checked and pass.
+func CheckBackupServer(backupFilePath string, server *models.Server) error {
+	manifest, err := ReadBackupManifest(backupFilePath)
+	if errors.Is(err, ErrBackupManifestNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if manifest.Server == server.Name || (manifest.ServerID != 0 && manifest.ServerID == server.ID) {
+		return nil
+	}
+	return fmt.Errorf("%w: it contains %s, not %s", ErrBackupServerMismatch, manifest.Server, server.Name)
+}
+
+// isBackupManifestEntry reports whether a cleaned archive entry name is the manifest of a
+// backup wrapped in rootPrefix ("" when not wrapped)
+func isBackupManifestEntry(name, rootPrefix string) bool {
+	return name == path.Join(rootPrefix, BackupManifestName)
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"seiapanel/models"
+)
+
+// BackupMountTTL is how long a backup stays extracted for browsing before it is cleaned up
+const BackupMountTTL = 30 * time.Minute
+
+// backupMountPrefix names the temporary directories backups are extracted into
+const backupMountPrefix = "seiapanel-mount-"
+
+// BackupMount is a backup extracted into a temporary directory for read-only browsing
+type BackupMount struct {
+	ID        string    `json:"id"`
+	ServerID  uint      `json:"server_id"`
+	BackupID  uint      `json:"backup_id"`
+	Path      string    `json:"-"` // Extracted files, inside dir
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	dir       string
+	timer     *time.Timer
+}
+
+var (
+	backupMounts   = make(map[string]*BackupMount)
+	backupMountMux sync.Mutex
+)
+
+// MountBackup extracts a backup into a temporary directory that expires after BackupMountTTL.
+// Mounting a backup that is already mounted returns the existing mount with a fresh TTL.
+func MountBackup(backup *models.Backup) (*BackupMount, error) {
+	backupMountMux.Lock()
+	for _, mount := range backupMounts {
+		if mount.BackupID == backup.ID {
+			mount.ExpiresAt = time.Now().Add(BackupMountTTL)
+			mount.timer.Reset(BackupMountTTL)
+			snapshot := *mount
+			backupMountMux.Unlock()
+			return &snapshot, nil
+		}
+	}
+	backupMountMux.Unlock()
+
+	rootPrefix, err := BackupRootFolder(backup.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", backupMountPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	// Files go one level down, so no mount path is a string prefix of another and
+	// prefix-based path checks can't cross from one mount into the next
+	filesPath := filepath.Join(dir, "files")
+	if err := os.Mkdir(filesPath, 0755); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	if err := extractTarGzBackup(backup.FilePath, filesPath, rootPrefix); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to extract backup: %w", err)
+	}
+
+	now := time.Now()
+	mount := &BackupMount{
+		ID:        generateJobID(),
+		ServerID:  backup.ServerID,
+		BackupID:  backup.ID,
+		Path:      filesPath,
+		dir:       dir,
+		CreatedAt: now,
+		ExpiresAt: now.Add(BackupMountTTL),
+	}
+
+	backupMountMux.Lock()
+	mount.timer = time.AfterFunc(BackupMountTTL, func() {
+		UnmountBackup(mount.ID)
+	})
+	backupMounts[mount.ID] = mount
+	snapshot := *mount
+	backupMountMux.Unlock()
+
+	log.Printf("✅ Backup %s mounted for browsing at %s (expires %s)", backup.FileName, dir, mount.ExpiresAt.Format("15:04:05"))
+	return &snapshot, nil
+}
+
+// GetBackupMount returns a mount by ID if it belongs to the server and hasn't expired
+func GetBackupMount(serverID uint, id string) (*BackupMount, bool) {
+	backupMountMux.Lock()
+	defer backupMountMux.Unlock()
+
+	mount, exists := backupMounts[id]
+	if !exists || mount.ServerID != serverID || time.Now().After(mount.ExpiresAt) {
+		return nil, false
+	}
+	snapshot := *mount
+	return &snapshot, true
+}
+
+// UnmountBackup removes a mount and deletes its extracted files
+func UnmountBackup(id string) bool {
+	backupMountMux.Lock()
+	mount, exists := backupMounts[id]
+	if exists {
+		mount.timer.Stop()
+		delete(backupMounts, id)
+	}
+	backupMountMux.Unlock()
+
+	if !exists {
+		return false
+	}
+
+	if err := os.RemoveAll(mount.dir); err != nil {
+		log.Printf("⚠️  Failed to remove backup mount %s: %v", mount.dir, err)
+	} else {
+		log.Printf("🧹 Backup mount %s removed", mount.ID)
+	}
+	return true
+}
+
+// CleanupStaleBackupMounts removes mount directories left behind by a previous run of the panel
+func CleanupStaleBackupMounts() {
+	matches, err := filepath.Glob(filepath.Join(os.TempDir(), backupMountPrefix+"*"))
+	if err != nil {
+		return
+	}
+	for _, dir := range matches {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("⚠️  Failed to remove stale backup mount %s: %v", dir, err)
+		}
+	}
+	if len(matches) > 0 {
+		log.Printf("🧹 Removed %d stale backup mount(s)", len(matches))
+	}
+}
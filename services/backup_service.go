@@ -3,13 +3,20 @@ package services
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math/big"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"seiapanel/config"
 	"seiapanel/models"
+	"strings"
 	"time"
 )
 
@@ -34,44 +41,138 @@ func GenerateBackupFileName(serverName string) string {
 	return fmt.Sprintf("%s_%s_%s.tar.gz", serverName, dateStr, randomID)
 }
 
-// CreateTarGzBackup creates a tar.gz backup of the server folder
-func CreateTarGzBackup(sourcePath, backupPath, fileName string) (string, int64, error) {
+// CreateTarGzBackup creates a tar.gz backup of the server folder. When rootFolder is set,
+// every entry is placed under a top-level rootFolder/ directory so the archive extracts cleanly standalone.
+// A manifest, when given, is stored as the first entry of the server folder.
+// Progress (bytes read, current file) is reported to job when it is not nil.
+func CreateTarGzBackup(sourcePath, backupPath, fileName, rootFolder string, manifest *BackupManifest, job *Job) (string, int64, error) {
 	// Ensure backup directory exists
 	if err := os.MkdirAll(backupPath, 0755); err != nil {
 		return "", 0, fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
-	// Full backup file path
+	// Refuse to start on a volume already down to its reserved free space
+	if err := CheckDiskReserve(backupPath, 0); err != nil {
+		return "", 0, err
+	}
+
+	// Full backup file path; the archive is written under a partial name and renamed when complete
 	fullBackupPath := filepath.Join(backupPath, fileName)
+	partialPath := PartialPath(fullBackupPath)
 
 	// Create backup file
-	backupFile, err := os.Create(fullBackupPath)
+	backupFile, err := os.Create(partialPath)
 	if err != nil {
 		return "", 0, fmt.Errorf("failed to create backup file: %w", err)
 	}
 	defer backupFile.Close()
+	defer os.Remove(partialPath) // No-op once renamed
 
-	// Create gzip writer
-	gzipWriter := gzip.NewWriter(backupFile)
+	// Create gzip writer, stopping the backup before it eats into the reserved free space
+	gzipWriter := gzip.NewWriter(NewDiskReserveWriter(backupFile, backupPath))
 	defer gzipWriter.Close()
 
 	// Create tar writer
 	tarWriter := tar.NewWriter(gzipWriter)
 	defer tarWriter.Close()
 
+	// Add the top-level folder entry first
+	if rootFolder != "" {
+		sourceInfo, err := os.Stat(sourcePath)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to stat server folder: %w", err)
+		}
+		header, err := tar.FileInfoHeader(sourceInfo, "")
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to create tar header: %w", err)
+		}
+		header.Name = rootFolder + "/"
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return "", 0, fmt.Errorf("failed to write tar header: %w", err)
+		}
+	}
+
+	if manifest != nil {
+		if err := writeBackupManifest(tarWriter, manifest, rootFolder); err != nil {
+			return "", 0, fmt.Errorf("failed to write backup manifest: %w", err)
+		}
+	}
+
 	// Walk through source directory and add files to archive
-	err = filepath.Walk(sourcePath, func(file string, fi os.FileInfo, err error) error {
+	err = writeTarTree(context.Background(), tarWriter, sourcePath, rootFolder, job)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create tar.gz archive: %w", err)
+	}
+
+	// Flush the archive before moving it into place
+	if err := tarWriter.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to finish gzip stream: %w", err)
+	}
+	if err := backupFile.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to write backup file: %w", err)
+	}
+	if err := os.Rename(partialPath, fullBackupPath); err != nil {
+		return "", 0, fmt.Errorf("failed to move backup into place: %w", err)
+	}
+
+	// Get file size
+	fileInfo, err := os.Stat(fullBackupPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get backup file size: %w", err)
+	}
+
+	return fullBackupPath, fileInfo.Size(), nil
+}
+
+// writeTarTree adds everything under sourcePath to a tar stream, under rootFolder/ when it is
+// set. Backup folders and a stray manifest are left out. The walk stops with the context's
+// error when ctx is canceled. Progress is reported to job when it is not nil.
+func writeTarTree(ctx context.Context, tarWriter *tar.Writer, sourcePath, rootFolder string, job *Job) error {
+	guard := NewWalkGuard(sourcePath)
+	return filepath.Walk(sourcePath, func(file string, fi os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if fi.IsDir() {
+			if err := guard.EnterDir(file, fi); err != nil {
+				return err
+			}
+		}
 
 		// Skip if it's the source directory itself
 		if file == sourcePath {
 			return nil
 		}
 
+		// A manifest left in the folder by an outside extraction would clash with the new one
+		if file == filepath.Join(sourcePath, BackupManifestName) {
+			return nil
+		}
+
+		// Never archive backup folders, or each backup would contain all earlier ones
+		if fi.IsDir() && IsBackupDir(file) {
+			log.Printf("⚠️  Leaving backup folder %s out of the backup", file)
+			return filepath.SkipDir
+		}
+
+		// Symlinks are archived as links rather than followed, so a link to a folder can't
+		// loop the walk or pull in files from outside the server folder
+		link := ""
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+
 		// Create tar header
-		header, err := tar.FileInfoHeader(fi, "")
+		header, err := tar.FileInfoHeader(fi, link)
 		if err != nil {
 			return err
 		}
@@ -82,6 +183,9 @@ func CreateTarGzBackup(sourcePath, backupPath, fileName string) (string, int64,
 			return err
 		}
 		header.Name = relPath
+		if rootFolder != "" {
+			header.Name = filepath.Join(rootFolder, relPath)
+		}
 
 		// Write header
 		if err := tarWriter.WriteHeader(header); err != nil {
@@ -89,59 +193,293 @@ func CreateTarGzBackup(sourcePath, backupPath, fileName string) (string, int64,
 		}
 
 		// If it's a file, write its content
-		if !fi.IsDir() {
+		if fi.Mode().IsRegular() {
+			job.SetCurrentFile(relPath)
+
 			fileToArchive, err := os.Open(file)
 			if err != nil {
 				return err
 			}
 			defer fileToArchive.Close()
 
-			if _, err := io.Copy(tarWriter, fileToArchive); err != nil {
+			if _, err := io.Copy(tarWriter, job.TrackReader(fileToArchive)); err != nil {
 				return err
 			}
+			job.FileDone()
 		}
 
 		return nil
 	})
+}
+
+// StreamTarGz writes everything under sourcePath to w as a tar.gz, under rootFolder/ when it
+// is set, the same way backups are archived but without touching the disk. It stops with
+// the context's error when ctx is canceled, e.g. when the client disconnects.
+func StreamTarGz(ctx context.Context, w io.Writer, sourcePath, rootFolder string) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	if rootFolder != "" {
+		sourceInfo, err := os.Stat(sourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to stat folder: %w", err)
+		}
+		header, err := tar.FileInfoHeader(sourceInfo, "")
+		if err != nil {
+			return fmt.Errorf("failed to create tar header: %w", err)
+		}
+		header.Name = rootFolder + "/"
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write tar header: %w", err)
+		}
+	}
+
+	if err := writeTarTree(ctx, tarWriter, sourcePath, rootFolder, nil); err != nil {
+		return fmt.Errorf("failed to create tar.gz archive: %w", err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish tar archive: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish gzip stream: %w", err)
+	}
+	return nil
+}
 
+// RotateBackups deletes the oldest backups so a new one fits within the limit and returns
+// the backups it deleted, oldest first. Pinned backups are neither counted nor deleted.
+// With dryRun nothing is deleted and the backups that would be are returned instead.
+func RotateBackups(serverID uint, maxBackups int, dryRun bool) ([]models.Backup, error) {
+	// Newest first
+	allBackups, err := models.GetBackupsByServerID(serverID)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to create tar.gz archive: %w", err)
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	backups := make([]models.Backup, 0, len(allBackups))
+	for _, backup := range allBackups {
+		if !backup.Pinned {
+			backups = append(backups, backup)
+		}
 	}
 
-	// Get file size
-	fileInfo, err := os.Stat(fullBackupPath)
+	// Keep the newest maxBackups-1 so the next backup stays within the limit
+	keep := maxBackups - 1
+	if keep < 0 {
+		keep = 0
+	}
+	if len(backups) <= keep {
+		return nil, nil
+	}
+
+	excess := make([]models.Backup, 0, len(backups)-keep)
+	for i := len(backups) - 1; i >= keep; i-- {
+		excess = append(excess, backups[i])
+	}
+	if dryRun {
+		return excess, nil
+	}
+
+	for i := range excess {
+		// Delete the actual file
+		if err := os.Remove(excess[i].FilePath); err != nil {
+			// Log error but continue (file might already be deleted)
+			fmt.Printf("Warning: failed to delete backup file %s: %v\n", excess[i].FilePath, err)
+		}
+
+		// Delete database record
+		if err := excess[i].Delete(); err != nil {
+			return excess[:i], fmt.Errorf("failed to delete backup record: %w", err)
+		}
+	}
+
+	return excess, nil
+}
+
+// ServerBackupDir returns the folder new backups of a server are written to: its backup path,
+// or a subfolder named after the server when backups are kept in per-server folders. Existing
+// backups are always found through their recorded FilePath, wherever they were written.
+func ServerBackupDir(server *models.Server) string {
+	if config.GetBackupPerServerDirs() {
+		return filepath.Join(server.BackupPath, server.Name)
+	}
+	return server.BackupPath
+}
+
+// CreateServerBackup runs the full backup pipeline for a server: rotate, archive and record
+func CreateServerBackup(server *models.Server, maxBackups int) (*models.Backup, error) {
+	// Check if backup path is configured
+	if server.BackupPath == "" {
+		return nil, fmt.Errorf("server %s has no backup path configured", server.Name)
+	}
+
+	// A restore or another backup running at the same time would leave a corrupt archive
+	if err := BeginServerOperation(server.ID, "backup"); err != nil {
+		return nil, err
+	}
+	defer EndServerOperation(server.ID)
+
+	return createServerBackup(server, maxBackups)
+}
+
+// createServerBackup is CreateServerBackup for a caller already holding the server's backup operation
+func createServerBackup(server *models.Server, maxBackups int) (*models.Backup, error) {
+	// Rotate backups if needed
+	if _, err := RotateBackups(server.ID, maxBackups, false); err != nil {
+		return nil, fmt.Errorf("failed to rotate backups: %w", err)
+	}
+
+	// Generate backup filename
+	fileName := GenerateBackupFileName(server.Name)
+
+	rootFolder := ""
+	if server.WrapBackups {
+		rootFolder = server.Name
+	}
+
+	// The backup is still worth taking if the schedules can't be recorded with it
+	manifest, err := NewBackupManifest(server)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to get backup file size: %w", err)
+		log.Printf("⚠️  Backup of '%s' made without its schedules: %v", server.Name, err)
 	}
 
-	return fullBackupPath, fileInfo.Size(), nil
+	// Create backup
+	backupFilePath, fileSize, err := CreateTarGzBackup(server.FolderPath, ServerBackupDir(server), fileName, rootFolder, manifest, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	// Save backup record to database
+	backup, err := models.CreateBackup(server.ID, fileName, backupFilePath, fileSize)
+	if err != nil {
+		os.Remove(backupFilePath)
+		return nil, fmt.Errorf("failed to save backup record: %w", err)
+	}
+
+	return backup, nil
 }
 
-// RotateBackups deletes the oldest backup when the limit is reached
-func RotateBackups(serverID uint, maxBackups int) error {
-	// Count current backups
-	count, err := models.CountBackups(serverID)
+// untrackedBackupMinAge is how long an untracked archive must sit unmodified before it is
+// imported, so a backup that is still being written isn't picked up half-done
+const untrackedBackupMinAge = 2 * time.Minute
+
+// BackupReconciliation is the result of comparing a server's backup records with its backup directory
+type BackupReconciliation struct {
+	Missing   []models.Backup `json:"missing"`   // Records whose file is gone
+	Untracked []string        `json:"untracked"` // Archive files in the backup directory without a record
+	Pruned    int             `json:"pruned"`
+	Imported  []models.Backup `json:"imported"`
+	Errors    []string        `json:"errors"`
+}
+
+// backupFileNamePattern matches file names produced by GenerateBackupFileName for a server
+func backupFileNamePattern(serverName string) *regexp.Regexp {
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(serverName) + `_\d{8}_\d{4}\.tar\.gz$`)
+}
+
+// ReconcileBackups finds backup records whose files are missing and archives in the backup
+// directory that have no record. With prune, missing records are deleted; with importFiles,
+// untracked archives that match the backup naming pattern and read cleanly get a record.
+func ReconcileBackups(server *models.Server, prune, importFiles bool) (*BackupReconciliation, error) {
+	result := &BackupReconciliation{
+		Missing:   []models.Backup{},
+		Untracked: []string{},
+		Imported:  []models.Backup{},
+		Errors:    []string{},
+	}
+
+	backups, err := models.GetBackupsByServerID(server.ID)
 	if err != nil {
-		return fmt.Errorf("failed to count backups: %w", err)
+		return nil, fmt.Errorf("failed to retrieve backups: %w", err)
 	}
 
-	// If we've reached the limit, delete the oldest backup
-	if int(count) >= maxBackups {
-		oldestBackup, err := models.GetOldestBackup(serverID)
+	for i := range backups {
+		backup := &backups[i]
+		if _, err := os.Stat(backup.FilePath); err == nil || !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		result.Missing = append(result.Missing, *backup)
+		if !prune {
+			continue
+		}
+		if err := backup.Delete(); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: failed to delete backup record", backup.FileName))
+			continue
+		}
+		result.Pruned++
+	}
+
+	if server.BackupPath == "" {
+		return result, nil
+	}
+
+	// Backups sit directly in the backup path or in the server's subfolder of it, depending on
+	// whether per-server folders were enabled when they were made
+	for _, dir := range []string{server.BackupPath, filepath.Join(server.BackupPath, server.Name)} {
+		if err := reconcileBackupDir(server, dir, importFiles, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// reconcileBackupDir adds the untracked archives of one backup folder to result, importing them with importFiles
+func reconcileBackupDir(server *models.Server, dir string, importFiles bool, result *BackupReconciliation) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	pattern := backupFileNamePattern(server.Name)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !pattern.MatchString(entry.Name()) {
+			continue
+		}
+
+		filePath := filepath.Join(dir, entry.Name())
+		// The backup path may be shared, so a file tracked by any server is not untracked
+		tracked, err := models.IsBackupPathTracked(filePath)
 		if err != nil {
-			return fmt.Errorf("failed to get oldest backup: %w", err)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: failed to check backup records", entry.Name()))
+			continue
+		}
+		if tracked {
+			continue
 		}
 
-		// Delete the actual file
-		if err := os.Remove(oldestBackup.FilePath); err != nil {
-			// Log error but continue (file might already be deleted)
-			fmt.Printf("Warning: failed to delete backup file %s: %v\n", oldestBackup.FilePath, err)
+		name, err := filepath.Rel(server.BackupPath, filePath)
+		if err != nil {
+			name = entry.Name()
+		}
+		result.Untracked = append(result.Untracked, name)
+		if !importFiles {
+			continue
 		}
 
-		// Delete database record
-		if err := oldestBackup.Delete(); err != nil {
-			return fmt.Errorf("failed to delete backup record: %w", err)
+		info, err := entry.Info()
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry.Name(), err))
+			continue
+		}
+		if time.Since(info.ModTime()) < untrackedBackupMinAge {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: modified recently, it may still be being written", entry.Name()))
+			continue
 		}
+		if validation := ValidateBackupArchive(filePath); !validation.Valid {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: not a valid backup archive: %s", entry.Name(), validation.Error))
+			continue
+		}
+
+		backup, err := models.ImportBackup(server.ID, entry.Name(), filePath, info.Size(), info.ModTime())
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: failed to save backup record", entry.Name()))
+			continue
+		}
+		result.Imported = append(result.Imported, *backup)
 	}
 
 	return nil
@@ -164,8 +502,26 @@ func GetBackupSize(filePath string) (int64, error) {
 	return fileInfo.Size(), nil
 }
 
-// ValidateBackupPath checks if the backup path is valid and accessible
-func ValidateBackupPath(backupPath string) error {
+// ValidateBackupPath checks if the backup path is valid and accessible. The path must not
+// be inside the server's own folder, or each backup would archive the previous ones.
+// Backup paths are often network mounts, so the checks give up with ErrOperationTimeout
+// after the configured operation timeout.
+func ValidateBackupPath(ctx context.Context, backupPath, serverFolderPath string) error {
+	ctx, cancel := WithOperationTimeout(ctx)
+	defer cancel()
+
+	return RunWithContext(ctx, func() error {
+		return validateBackupPath(backupPath, serverFolderPath)
+	})
+}
+
+// validateBackupPath performs the checks of ValidateBackupPath without a timeout
+func validateBackupPath(backupPath, serverFolderPath string) error {
+	// Reject before creating anything inside the server folder
+	if isWithinPath(resolvePath(backupPath), resolvePath(serverFolderPath)) {
+		return fmt.Errorf("backup path cannot be inside the server folder")
+	}
+
 	// Check if path exists
 	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
 		// Try to create it
@@ -174,6 +530,11 @@ func ValidateBackupPath(backupPath string) error {
 		}
 	}
 
+	// Check again now that symlinks in the created path can be resolved
+	if isWithinPath(resolvePath(backupPath), resolvePath(serverFolderPath)) {
+		return fmt.Errorf("backup path cannot be inside the server folder")
+	}
+
 	// Check if path is writable
 	testFile := filepath.Join(backupPath, ".write_test")
 	if err := os.WriteFile(testFile, []byte("test"), 0644); err != nil {
@@ -184,6 +545,58 @@ func ValidateBackupPath(backupPath string) error {
 	return nil
 }
 
+// BackupPathWarnings returns warnings about a backup path that is valid but may collide
+// with the user's other servers
+func BackupPathWarnings(server *models.Server, backupPath string) []string {
+	warnings := make([]string, 0)
+
+	servers, err := models.GetServersByUserID(server.UserID)
+	if err != nil {
+		return warnings
+	}
+
+	resolved := resolvePath(backupPath)
+	for _, other := range servers {
+		if other.ID == server.ID {
+			continue
+		}
+
+		if other.BackupPath != "" && resolvePath(other.BackupPath) == resolved && !config.GetBackupPerServerDirs() {
+			warnings = append(warnings, fmt.Sprintf("Server %s uses the same backup path; backup files of both servers will be mixed in one folder", other.Name))
+		}
+		if isWithinPath(resolved, resolvePath(other.FolderPath)) {
+			if config.GetIncludeBackupDirs() {
+				warnings = append(warnings, fmt.Sprintf("Backup path is inside the folder of server %s; its backups will include these backup files", other.Name))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("Backup path is inside the folder of server %s; it is left out of that server's backups, folder sizes and archives", other.Name))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// resolvePath returns the absolute path with symlinks resolved when it exists
+func resolvePath(p string) string {
+	absPath, err := filepath.Abs(p)
+	if err != nil {
+		return filepath.Clean(p)
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		return resolved
+	}
+	return absPath
+}
+
+// isWithinPath reports whether p is root or a path inside it
+func isWithinPath(p, root string) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // FormatFileSize formats bytes to human-readable size
 func FormatFileSize(bytes int64) string {
 	const unit = 1024
@@ -210,19 +623,173 @@ func RestoreBackupFromArchive(backupFilePath, serverFolderPath string) error {
 		return fmt.Errorf("server folder not found: %w", err)
 	}
 
-	// Step 3: Delete all contents inside server folder (but keep the folder itself)
+	// Step 3: Look up whether the backup wraps everything in a top-level folder
+	rootPrefix, err := BackupRootFolder(backupFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	// Step 4: Delete all contents inside server folder (but keep the folder itself)
 	if err := clearDirectory(serverFolderPath); err != nil {
 		return fmt.Errorf("failed to clear server directory: %w", err)
 	}
 
-	// Step 4: Extract backup to server folder
-	if err := extractTarGzBackup(backupFilePath, serverFolderPath); err != nil {
+	// Step 5: Extract backup to server folder
+	if err := extractTarGzBackup(backupFilePath, serverFolderPath, rootPrefix); err != nil {
 		return fmt.Errorf("failed to extract backup: %w", err)
 	}
 
 	return nil
 }
 
+// ErrBackupEntryNotFound is returned when a requested file isn't present in a backup
+var ErrBackupEntryNotFound = errors.New("file not found in backup")
+
+// ReadBackupEntry locates a single file in a tar.gz backup and passes its contents to fn,
+// without extracting anything else. entryPath is relative to the server folder; backups
+// wrapped in a top-level folder are looked up inside it.
+func ReadBackupEntry(backupFilePath, entryPath string, fn func(header *tar.Header, content io.Reader) error) error {
+	entryPath = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(entryPath)), "/")
+	if entryPath == "" {
+		return ErrBackupEntryNotFound
+	}
+
+	rootPrefix, err := BackupRootFolder(backupFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	entryPath = path.Join(rootPrefix, entryPath)
+
+	file, err := os.Open(backupFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return ErrBackupEntryNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar header: %w", err)
+		}
+
+		name := strings.TrimPrefix(path.Clean(filepath.ToSlash(header.Name)), "./")
+		if name != entryPath {
+			continue
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			return fmt.Errorf("%s is not a regular file", strings.TrimPrefix(entryPath, rootPrefix+"/"))
+		}
+		return fn(header, tarReader)
+	}
+}
+
+// WalkBackupArchive reads a tar.gz backup from start to end and calls fn for every entry.
+// The gzip stream is drained past the tar trailer so its checksum is verified as well.
+func WalkBackupArchive(backupFilePath string, fn func(header *tar.Header, content io.Reader) error) error {
+	file, err := os.Open(backupFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar header: %w", err)
+		}
+
+		if err := fn(header, tarReader); err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.Copy(io.Discard, gzipReader); err != nil {
+		return fmt.Errorf("failed to read gzip stream: %w", err)
+	}
+	return nil
+}
+
+// BackupValidation is the result of reading a backup archive end to end
+type BackupValidation struct {
+	Valid     bool   `json:"valid"`
+	Entries   int    `json:"entries"`
+	TotalSize int64  `json:"total_size"` // Uncompressed size of all file contents
+	Error     string `json:"error,omitempty"`
+}
+
+// ValidateBackupArchive checks that a backup is a readable tar.gz by streaming every entry
+// to the end without writing anything. Read errors are reported in the result.
+func ValidateBackupArchive(backupFilePath string) BackupValidation {
+	var result BackupValidation
+
+	err := WalkBackupArchive(backupFilePath, func(header *tar.Header, content io.Reader) error {
+		n, err := io.Copy(io.Discard, content)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		result.Entries++
+		result.TotalSize += n
+		return nil
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Valid = true
+	return result
+}
+
+// BackupContentStats reads a backup's entry headers and returns the size and item count
+// its contents would occupy once restored, counted like GetDirStats. A top-level wrapper
+// folder is left out, since restores unwrap it.
+func BackupContentStats(backupFilePath string) (DirStats, error) {
+	rootPrefix, err := BackupRootFolder(backupFilePath)
+	if err != nil {
+		return DirStats{}, err
+	}
+
+	var stats DirStats
+	err = WalkBackupArchive(backupFilePath, func(header *tar.Header, content io.Reader) error {
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if name == "" || name == rootPrefix || isBackupManifestEntry(name, rootPrefix) {
+			return nil
+		}
+
+		stats.ItemCount++
+		if header.Typeflag == tar.TypeReg {
+			stats.TotalSize += header.Size
+		}
+		return nil
+	})
+	if err != nil {
+		return DirStats{}, err
+	}
+
+	return stats, nil
+}
+
 // clearDirectory removes all contents of a directory but keeps the directory itself
 func clearDirectory(dirPath string) error {
 	// Read directory contents
@@ -242,8 +809,63 @@ func clearDirectory(dirPath string) error {
 	return nil
 }
 
-// extractTarGzBackup extracts a tar.gz backup to the specified destination
-func extractTarGzBackup(backupFilePath, destPath string) error {
+// detectBackupRootFolder guesses the top-level folder shared by every entry in a backup
+// without a manifest, or "" when entries sit directly at the archive root. A single shared
+// folder is taken to mean the backup was wrapped; BackupRootFolder reads the recorded
+// layout instead whenever there is one.
+func detectBackupRootFolder(backupFilePath string) (string, error) {
+	file, err := os.Open(backupFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+
+	root := ""
+	hasNested := false
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read tar header: %w", err)
+		}
+
+		name := strings.TrimPrefix(path.Clean(filepath.ToSlash(header.Name)), "./")
+		parts := strings.SplitN(name, "/", 2)
+
+		// A top-level file means the backup isn't wrapped
+		if len(parts) == 1 && header.Typeflag != tar.TypeDir {
+			return "", nil
+		}
+
+		if root == "" {
+			root = parts[0]
+		} else if root != parts[0] {
+			return "", nil
+		}
+		if len(parts) == 2 {
+			hasNested = true
+		}
+	}
+
+	if !hasNested {
+		return "", nil
+	}
+	return root, nil
+}
+
+// extractTarGzBackup extracts a tar.gz backup to the specified destination,
+// stripping rootPrefix from entry names when the backup is wrapped in a top-level folder
+func extractTarGzBackup(backupFilePath, destPath, rootPrefix string) error {
 	// Open backup file
 	file, err := os.Open(backupFilePath)
 	if err != nil {
@@ -271,8 +893,23 @@ func extractTarGzBackup(backupFilePath, destPath string) error {
 			return fmt.Errorf("failed to read tar header: %w", err)
 		}
 
+		// Strip the top-level folder from wrapped backups
+		name := header.Name
+		if rootPrefix != "" {
+			name = strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "./")
+			if name == rootPrefix {
+				continue
+			}
+			name = strings.TrimPrefix(name, rootPrefix+"/")
+		}
+
+		// The manifest describes the backup and isn't part of the server files
+		if isBackupManifestEntry(strings.TrimPrefix(path.Clean("/"+name), "/"), "") {
+			continue
+		}
+
 		// Build target path
-		target := filepath.Join(destPath, header.Name)
+		target := filepath.Join(destPath, name)
 
 		// Security check: prevent path traversal
 		if !filepath.HasPrefix(filepath.Clean(target), filepath.Clean(destPath)) {
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"seiapanel/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB points models.DB at a fresh database in a temporary folder for one test
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "app.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Server{}, &models.Backup{}, &models.Schedule{}, &models.FileJournalEntry{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	previous := models.DB
+	models.DB = db
+	InvalidateBackupDirs()
+	t.Cleanup(func() {
+		models.DB = previous
+		InvalidateBackupDirs()
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+}
+
+// writeTestFile creates a file, and the folders leading to it, under root
+func writeTestFile(t *testing.T, root, name, content string) {
+	t.Helper()
+
+	fullPath := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestBackupRootFolderFromManifest checks that a server whose files all sit in one folder
+// keeps that folder, and that wrapped backups are still unwrapped
+func TestBackupRootFolderFromManifest(t *testing.T) {
+	setupTestDB(t)
+
+	source := t.TempDir()
+	writeTestFile(t, source, "world/level.dat", "level")
+	backupDir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		rootFolder string
+	}{
+		{"unwrapped", ""},
+		{"wrapped", "survival"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manifest := &BackupManifest{Version: backupManifestVersion, Server: "survival"}
+			backupPath, _, err := CreateTarGzBackup(source, backupDir, test.name+".tar.gz", test.rootFolder, manifest, nil)
+			if err != nil {
+				t.Fatalf("CreateTarGzBackup: %v", err)
+			}
+
+			root, err := BackupRootFolder(backupPath)
+			if err != nil {
+				t.Fatalf("BackupRootFolder: %v", err)
+			}
+			if root != test.rootFolder {
+				t.Errorf("BackupRootFolder = %q, want %q", root, test.rootFolder)
+			}
+
+			files, err := ListBackupFiles(backupPath)
+			if err != nil {
+				t.Fatalf("ListBackupFiles: %v", err)
+			}
+			if _, ok := files["world/level.dat"]; !ok {
+				t.Errorf("ListBackupFiles is missing world/level.dat: %v", files)
+			}
+
+			restored := t.TempDir()
+			if err := RestoreBackupFromArchive(backupPath, restored); err != nil {
+				t.Fatalf("RestoreBackupFromArchive: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(restored, "world", "level.dat")); err != nil {
+				t.Errorf("restored backup is missing world/level.dat: %v", err)
+			}
+
+			var content string
+			err = ReadBackupEntry(backupPath, "world/level.dat", func(_ *tar.Header, r io.Reader) error {
+				data, err := io.ReadAll(r)
+				content = string(data)
+				return err
+			})
+			if err != nil || content != "level" {
+				t.Errorf("ReadBackupEntry = %q, %v; want %q", content, err, "level")
+			}
+		})
+	}
+}
+
+// TestCreateTarGzBackupSymlinkLoop checks that a symlink back to the server folder is
+// archived as a link instead of being followed
+func TestCreateTarGzBackupSymlinkLoop(t *testing.T) {
+	setupTestDB(t)
+
+	source := t.TempDir()
+	writeTestFile(t, source, "world/level.dat", "level")
+	if err := os.Symlink(source, filepath.Join(source, "world", "loop")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	type result struct {
+		path string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		path, _, err := CreateTarGzBackup(source, t.TempDir(), "loop.tar.gz", "", nil, nil)
+		done <- result{path, err}
+	}()
+
+	var backupPath string
+	select {
+	case res := <-done:
+		if res.err != nil {
+			t.Fatalf("CreateTarGzBackup: %v", res.err)
+		}
+		backupPath = res.path
+	case <-time.After(10 * time.Second):
+		t.Fatal("backup through a symlink loop did not finish")
+	}
+
+	file, err := os.Open(backupPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			t.Fatal("backup is missing world/loop")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Name != filepath.Join("world", "loop") {
+			continue
+		}
+		if header.Typeflag != tar.TypeSymlink || header.Linkname != source {
+			t.Errorf("world/loop archived as type %c linking to %q, want a symlink to %q", header.Typeflag, header.Linkname, source)
+		}
+		return
+	}
+}
+
+// TestCreateTarGzBackupTooDeep checks that backing up a tree deeper than MaxWalkDepth stops
+// with ErrWalkTooDeep
+func TestCreateTarGzBackupTooDeep(t *testing.T) {
+	setupTestDB(t)
+
+	source := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(source, strings.Repeat("d/", MaxWalkDepth+1)), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := CreateTarGzBackup(source, t.TempDir(), "deep.tar.gz", "", nil, nil); !errors.Is(err, ErrWalkTooDeep) {
+		t.Errorf("CreateTarGzBackup error = %v, want ErrWalkTooDeep", err)
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DirStatsMaxEntries bounds how many entries a single directory walk visits
+const DirStatsMaxEntries = 200000
+
+// dirStatsTTL bounds how stale a cached result can get. A directory's modtime only
+// changes when its direct children change, so deep edits are picked up on expiry.
+const dirStatsTTL = 5 * time.Minute
+
+// DirStats holds the recursive size and item count of a directory
+type DirStats struct {
+	TotalSize int64 `json:"total_size"`
+	ItemCount int64 `json:"item_count"`
+	Truncated bool  `json:"truncated"` // Walk stopped at DirStatsMaxEntries
+}
+
+type dirStatsCacheEntry struct {
+	modTime  time.Time
+	cachedAt time.Time
+	stats    DirStats
+}
+
+var (
+	dirStatsCache = make(map[string]dirStatsCacheEntry)
+	dirStatsMux   sync.Mutex
+)
+
+// GetDirStats returns the total size of regular files and the number of entries under a
+// directory. Results are cached per path and modtime. The walk stops early with the
+// context's error when ctx is canceled, or ErrOperationTimeout when its deadline passes,
+// even while stuck on an unresponsive mount.
+func GetDirStats(ctx context.Context, dirPath string) (DirStats, error) {
+	var stats DirStats
+	err := RunWithContext(ctx, func() error {
+		var err error
+		stats, err = dirStats(ctx, dirPath)
+		return err
+	})
+	if err != nil {
+		return DirStats{}, err
+	}
+	return stats, nil
+}
+
+// dirStats computes or looks up the stats returned by GetDirStats
+func dirStats(ctx context.Context, dirPath string) (DirStats, error) {
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		return DirStats{}, err
+	}
+
+	dirStatsMux.Lock()
+	cached, exists := dirStatsCache[dirPath]
+	dirStatsMux.Unlock()
+	if exists && cached.modTime.Equal(info.ModTime()) && time.Since(cached.cachedAt) < dirStatsTTL {
+		return cached.stats, nil
+	}
+
+	var stats DirStats
+	err = filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || path == dirPath {
+			return nil
+		}
+		if d.IsDir() && IsBackupDir(path) {
+			return filepath.SkipDir
+		}
+
+		if stats.ItemCount >= DirStatsMaxEntries {
+			stats.Truncated = true
+			return filepath.SkipAll
+		}
+		stats.ItemCount++
+
+		if d.Type().IsRegular() {
+			if entryInfo, err := d.Info(); err == nil {
+				stats.TotalSize += entryInfo.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return DirStats{}, err
+	}
+
+	dirStatsMux.Lock()
+	defer dirStatsMux.Unlock()
+
+	// Drop expired entries so the cache doesn't grow without bound
+	now := time.Now()
+	for path, entry := range dirStatsCache {
+		if now.Sub(entry.cachedAt) >= dirStatsTTL {
+			delete(dirStatsCache, path)
+		}
+	}
+	dirStatsCache[dirPath] = dirStatsCacheEntry{
+		modTime:  info.ModTime(),
+		cachedAt: now,
+		stats:    stats,
+	}
+
+	return stats, nil
+}
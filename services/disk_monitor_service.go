@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"seiapanel/config"
+	"seiapanel/models"
+)
+
+// VolumeState is the free space of one volume holding the server root or a backup folder
+type VolumeState struct {
+	Paths       []string   `json:"paths"` // Monitored folders on this volume
+	Total       uint64     `json:"total"`
+	Free        uint64     `json:"free"` // Bytes available to the panel
+	UsedPercent float64    `json:"used_percent"`
+	Low         bool       `json:"low"` // Free space is below the alert threshold
+	LowSince    *time.Time `json:"low_since"`
+}
+
+var (
+	diskVolumes     = make(map[uint64]*VolumeState) // Keyed by device
+	diskCheckedAt   time.Time
+	diskMux         sync.Mutex
+	diskMonitorOnce sync.Once
+)
+
+// StartDiskMonitor starts checking free space on the volumes holding the server root and
+// backup folders, notifying before they run full
+func StartDiskMonitor() {
+	diskMonitorOnce.Do(func() {
+		go func() {
+			checkDiskSpace()
+			for {
+				_, interval := config.GetDiskAlert()
+				time.Sleep(interval)
+				checkDiskSpace()
+			}
+		}()
+		log.Println("✅ Disk space monitor started")
+	})
+}
+
+// GetDiskVolumes returns a copy of the latest free space check and when it ran
+func GetDiskVolumes() ([]VolumeState, time.Time) {
+	diskMux.Lock()
+	defer diskMux.Unlock()
+
+	volumes := make([]VolumeState, 0, len(diskVolumes))
+	for _, volume := range diskVolumes {
+		snapshot := *volume
+		snapshot.Paths = append([]string{}, volume.Paths...)
+		volumes = append(volumes, snapshot)
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].Paths[0] < volumes[j].Paths[0]
+	})
+	return volumes, diskCheckedAt
+}
+
+// monitoredDiskPaths returns the server root and every configured backup folder
+func monitoredDiskPaths() []string {
+	paths := make([]string, 0)
+	if config.AppConfig != nil && config.GetServerPath() != "" {
+		paths = append(paths, resolvePath(config.GetServerPath()))
+	}
+
+	servers, err := models.GetAllServers()
+	if err != nil {
+		return paths
+	}
+	for _, server := range servers {
+		if server.BackupPath != "" {
+			paths = append(paths, resolvePath(server.BackupPath))
+		}
+	}
+	return paths
+}
+
+// checkDiskSpace measures every monitored volume once. A volume alerts when its free space
+// drops below the threshold and recovers once it is 10% above it, so usage hovering around
+// the threshold doesn't spam notifications.
+func checkDiskSpace() {
+	threshold, _ := config.GetDiskAlert()
+
+	// Group folders by the volume they live on
+	measured := make(map[uint64]*VolumeState)
+	for _, path := range monitoredDiskPaths() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			continue
+		}
+		dev := uint64(stat.Dev)
+
+		if volume, exists := measured[dev]; exists {
+			if !containsString(volume.Paths, path) {
+				volume.Paths = append(volume.Paths, path)
+			}
+			continue
+		}
+
+		var fs syscall.Statfs_t
+		if err := syscall.Statfs(path, &fs); err != nil {
+			continue
+		}
+		volume := &VolumeState{
+			Paths: []string{path},
+			Total: fs.Blocks * uint64(fs.Bsize),
+			Free:  fs.Bavail * uint64(fs.Bsize),
+		}
+		if volume.Total > 0 {
+			volume.UsedPercent = float64(volume.Total-fs.Bfree*uint64(fs.Bsize)) / float64(volume.Total) * 100
+		}
+		measured[dev] = volume
+	}
+
+	diskMux.Lock()
+	defer diskMux.Unlock()
+
+	now := time.Now()
+	for dev, volume := range measured {
+		previous, exists := diskVolumes[dev]
+		if exists && previous.Low {
+			volume.Low, volume.LowSince = true, previous.LowSince
+		}
+
+		switch {
+		case threshold == 0:
+			volume.Low, volume.LowSince = false, nil
+		case !volume.Low && volume.Free < threshold:
+			volume.Low, volume.LowSince = true, &now
+			log.Printf("⚠️  Low disk space on %s: %d MiB free", volume.Paths[0], volume.Free>>20)
+			Notify(EventDiskLow, "",
+				fmt.Sprintf("Low disk space: %d MiB free on the volume holding %s (alert below %d MiB)", volume.Free>>20, volume.Paths[0], threshold>>20),
+				diskNotificationData(volume, threshold))
+		case volume.Low && volume.Free >= threshold+threshold/10:
+			volume.Low, volume.LowSince = false, nil
+			log.Printf("✅ Disk space on %s recovered: %d MiB free", volume.Paths[0], volume.Free>>20)
+			Notify(EventDiskRecovery, "",
+				fmt.Sprintf("Disk space recovered: %d MiB free on the volume holding %s", volume.Free>>20, volume.Paths[0]),
+				diskNotificationData(volume, threshold))
+		}
+	}
+
+	diskVolumes = measured
+	diskCheckedAt = now
+}
+
+// diskNotificationData builds the data payload for disk space notifications
+func diskNotificationData(volume *VolumeState, threshold uint64) map[string]interface{} {
+	return map[string]interface{}{
+		"paths":        volume.Paths,
+		"total":        volume.Total,
+		"free":         volume.Free,
+		"used_percent": volume.UsedPercent,
+		"alert_free":   threshold,
+	}
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"seiapanel/config"
+)
+
+// ErrDiskReserve is wrapped by the errors of operations refused because they would leave less
+// than the reserved free space on a volume
+var ErrDiskReserve = errors.New("not enough free disk space")
+
+// diskReserveCheckBytes is how much a reserve-guarded writer writes between free space checks
+const diskReserveCheckBytes = 64 * 1024 * 1024
+
+// CheckDiskReserve returns an error wrapping ErrDiskReserve when writing size more bytes under
+// path would leave less than the configured reserve free on its volume. Nothing is refused when
+// no reserve is configured or the free space can't be read.
+func CheckDiskReserve(path string, size int64) error {
+	reserve := config.GetDiskReserve()
+	if reserve == 0 {
+		return nil
+	}
+
+	free, err := volumeFreeSpace(path)
+	if err != nil {
+		return nil
+	}
+	if size < 0 {
+		size = 0
+	}
+	if free >= reserve && free-reserve >= uint64(size) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s would be written with %s free, but %s is reserved",
+		ErrDiskReserve, FormatFileSize(size), FormatFileSize(int64(free)), FormatFileSize(int64(reserve)))
+}
+
+// volumeFreeSpace returns the bytes available to the panel on the volume holding path, or
+// its closest existing parent when path doesn't exist yet
+func volumeFreeSpace(path string) (uint64, error) {
+	path = filepath.Clean(path)
+	for {
+		var fs syscall.Statfs_t
+		err := syscall.Statfs(path, &fs)
+		if err == nil {
+			return fs.Bavail * uint64(fs.Bsize), nil
+		}
+		parent := filepath.Dir(path)
+		if !os.IsNotExist(err) || parent == path {
+			return 0, err
+		}
+		path = parent
+	}
+}
+
+// diskReserveWriter fails writes once the volume it writes to is down to the reserve
+type diskReserveWriter struct {
+	w         io.Writer
+	path      string
+	unchecked int64
+}
+
+// NewDiskReserveWriter wraps w, which writes to a file under path, so writing fails with an
+// error wrapping ErrDiskReserve once the volume is down to the configured reserve. Free space
+// is checked before the first write and every few dozen megabytes after.
+func NewDiskReserveWriter(w io.Writer, path string) io.Writer {
+	if config.GetDiskReserve() == 0 {
+		return w
+	}
+	return &diskReserveWriter{w: w, path: path, unchecked: diskReserveCheckBytes}
+}
+
+func (rw *diskReserveWriter) Write(p []byte) (int, error) {
+	if rw.unchecked+int64(len(p)) >= diskReserveCheckBytes {
+		if err := CheckDiskReserve(rw.path, diskReserveCheckBytes); err != nil {
+			return 0, err
+		}
+		rw.unchecked = 0
+	}
+	n, err := rw.w.Write(p)
+	rw.unchecked += int64(n)
+	return n, err
+}
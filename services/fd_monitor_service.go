@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// File descriptor monitoring thresholds, as a percentage of the soft limit. Usage must drop
+// back below the recovery level before another alert fires.
+const (
+	fdAlertPercent    = 80
+	fdRecoveryPercent = 70
+	fdCheckInterval   = time.Minute
+)
+
+// FDState is the panel process's open file descriptor count against its limits
+type FDState struct {
+	Open        int        `json:"open"`
+	SoftLimit   uint64     `json:"soft_limit"`
+	HardLimit   uint64     `json:"hard_limit"`
+	UsedPercent float64    `json:"used_percent"`
+	Low         bool       `json:"low"` // Usage is above the alert threshold
+	LowSince    *time.Time `json:"low_since"`
+	CheckedAt   time.Time  `json:"checked_at"`
+}
+
+var (
+	fdState       FDState
+	fdMux         sync.Mutex
+	fdMonitorOnce sync.Once
+)
+
+// RaiseFileLimit raises the soft limit on open files to the hard limit, so busy panels don't
+// run out of descriptors for WebSockets, tails and downloads. Recent Go releases already do
+// this at startup; it is repeated here to log the limit in effect.
+func RaiseFileLimit() {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		log.Printf("⚠️  Failed to read the open file limit: %v", err)
+		return
+	}
+
+	if limit.Cur < limit.Max {
+		raised := limit
+		raised.Cur = limit.Max
+		if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &raised); err != nil {
+			log.Printf("⚠️  Failed to raise the open file limit from %d to %d: %v", limit.Cur, limit.Max, err)
+			return
+		}
+		limit = raised
+	}
+	log.Printf("✅ Open file limit is %d", limit.Cur)
+}
+
+// StartFDMonitor starts checking how many file descriptors the panel holds open, notifying
+// before it runs out
+func StartFDMonitor() {
+	fdMonitorOnce.Do(func() {
+		go func() {
+			for {
+				checkFileDescriptors()
+				time.Sleep(fdCheckInterval)
+			}
+		}()
+		log.Println("✅ File descriptor monitor started")
+	})
+}
+
+// GetFDState returns the latest file descriptor check
+func GetFDState() FDState {
+	fdMux.Lock()
+	defer fdMux.Unlock()
+	return fdState
+}
+
+// countOpenFDs counts the process's open file descriptors from /proc (Linux only)
+func countOpenFDs() (int, error) {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return 0, err
+	}
+	// The directory being read holds a descriptor of its own
+	return len(entries) - 1, nil
+}
+
+// checkFileDescriptors measures open descriptors once, alerting above fdAlertPercent of the
+// soft limit and recovering below fdRecoveryPercent
+func checkFileDescriptors() {
+	open, err := countOpenFDs()
+	if err != nil {
+		return
+	}
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil || limit.Cur == 0 {
+		return
+	}
+
+	fdMux.Lock()
+	defer fdMux.Unlock()
+
+	now := time.Now()
+	state := FDState{
+		Open:        open,
+		SoftLimit:   limit.Cur,
+		HardLimit:   limit.Max,
+		UsedPercent: float64(open) / float64(limit.Cur) * 100,
+		Low:         fdState.Low,
+		LowSince:    fdState.LowSince,
+		CheckedAt:   now,
+	}
+
+	switch {
+	case !state.Low && state.UsedPercent >= fdAlertPercent:
+		state.Low, state.LowSince = true, &now
+		log.Printf("⚠️  Running low on file descriptors: %d of %d open", open, limit.Cur)
+		Notify(EventFDLow, "",
+			fmt.Sprintf("Running low on file descriptors: %d of %d open (%.0f%%)", open, limit.Cur, state.UsedPercent),
+			fdNotificationData(state))
+	case state.Low && state.UsedPercent < fdRecoveryPercent:
+		state.Low, state.LowSince = false, nil
+		log.Printf("✅ File descriptor usage recovered: %d of %d open", open, limit.Cur)
+		Notify(EventFDRecovery, "",
+			fmt.Sprintf("File descriptor usage recovered: %d of %d open", open, limit.Cur),
+			fdNotificationData(state))
+	}
+
+	fdState = state
+}
+
+// fdNotificationData builds the data payload for file descriptor notifications
+func fdNotificationData(state FDState) map[string]interface{} {
+	return map[string]interface{}{
+		"open":         state.Open,
+		"soft_limit":   state.SoftLimit,
+		"hard_limit":   state.HardLimit,
+		"used_percent": state.UsedPercent,
+	}
+}
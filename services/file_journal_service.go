@@ -0,0 +1,501 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"seiapanel/config"
+	"seiapanel/models"
+)
+
+// fileJournalDir holds copies of files taken before the file manager deleted or overwrote them
+const fileJournalDir = "./file-journal"
+
+// maxFileJournalEntries bounds how many operations are kept per server
+const maxFileJournalEntries = 100
+
+// Journaled operations
+const (
+	JournalDelete    = "delete"
+	JournalMove      = "move"
+	JournalOverwrite = "overwrite"
+)
+
+var (
+	// ErrJournalEmpty is returned when a server has no journaled operation left
+	ErrJournalEmpty = errors.New("no file operation to undo")
+	// ErrJournalNotUndoable is returned for an operation recorded without the copies needed to reverse it
+	ErrJournalNotUndoable = errors.New("the last file operation can't be undone")
+	// ErrJournalConflict is returned when files changed since the operation in a way undo would clobber
+	ErrJournalConflict = errors.New("files changed since the operation")
+)
+
+// JournalItem is one file or folder affected by a journaled operation
+type JournalItem struct {
+	Path   string `json:"path"`             // Where it was before the operation
+	Target string `json:"target,omitempty"` // Where a move put it
+	Size   int64  `json:"size"`
+	IsDir  bool   `json:"is_dir"`
+	stash  string // Copy taken before a delete or overwrite, empty when none was kept
+	after  *journalFileState
+}
+
+// journalFileState is the size and modification time an overwrite left a file with, so undo
+// can tell whether it was changed again since
+type journalFileState struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// journalItemRecord is a JournalItem as stored in the database, copy location included
+type journalItemRecord struct {
+	Path   string            `json:"path"`
+	Target string            `json:"target,omitempty"`
+	Size   int64             `json:"size"`
+	IsDir  bool              `json:"is_dir"`
+	Stash  string            `json:"stash,omitempty"`
+	After  *journalFileState `json:"after,omitempty"`
+}
+
+// FileJournalEntry records one delete, move or overwrite in a server folder
+type FileJournalEntry struct {
+	ID       uint          `json:"id"`
+	ServerID uint          `json:"server_id"`
+	Op       string        `json:"op"`
+	Items    []JournalItem `json:"items"`
+	Time     time.Time     `json:"time"`
+	Undoable bool          `json:"undoable"`
+	stashKey string        // Prefix of the entry's copies in fileJournalDir
+}
+
+var (
+	fileJournalSeq uint64     // Tells apart copies started in the same instant
+	fileJournalMux sync.Mutex // Serializes journal changes and undo
+)
+
+// NewFileJournalEntry starts recording an operation on a server's files. It returns nil when
+// journaling is turned off, and every method accepts a nil entry.
+func NewFileJournalEntry(serverID uint, op string) *FileJournalEntry {
+	if enabled, _, _ := config.GetFileJournal(); !enabled {
+		return nil
+	}
+
+	stashKey := fmt.Sprintf("%d-%d", time.Now().UnixNano(), atomic.AddUint64(&fileJournalSeq, 1))
+	return &FileJournalEntry{ServerID: serverID, Op: op, Time: time.Now(), stashKey: stashKey}
+}
+
+// Stash records a file about to be deleted or overwritten, first copying it aside when it is
+// small enough. Folders and larger files are recorded without a copy and can't be restored.
+func (e *FileJournalEntry) Stash(path string) {
+	if e == nil {
+		return
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return
+	}
+	item := JournalItem{Path: path, Size: info.Size(), IsDir: info.IsDir()}
+
+	_, maxFile, _ := config.GetFileJournal()
+	if info.Mode().IsRegular() && info.Size() <= maxFile {
+		stash := filepath.Join(fileJournalDir, fmt.Sprintf("%s-%d", e.stashKey, len(e.Items)))
+		unlock := LockFileShared(path)
+		err := copyJournalFile(path, stash, info.Mode().Perm())
+		unlock()
+		if err != nil {
+			log.Printf("⚠️  Failed to keep a copy of %s for undo: %v", path, err)
+			os.Remove(stash)
+		} else {
+			item.stash = stash
+		}
+	}
+	e.Items = append(e.Items, item)
+}
+
+// AddMove records that path was moved or renamed to target
+func (e *FileJournalEntry) AddMove(path, target string) {
+	if e == nil {
+		return
+	}
+	item := JournalItem{Path: path, Target: target}
+	if info, err := os.Lstat(target); err == nil {
+		item.Size, item.IsDir = info.Size(), info.IsDir()
+	}
+	e.Items = append(e.Items, item)
+}
+
+// Forget drops a stashed path whose operation then failed, so undo doesn't try to restore it
+func (e *FileJournalEntry) Forget(path string) {
+	if e == nil {
+		return
+	}
+	for i, item := range e.Items {
+		if item.Path == path {
+			if item.stash != "" {
+				os.Remove(item.stash)
+			}
+			e.Items = append(e.Items[:i], e.Items[i+1:]...)
+			return
+		}
+	}
+}
+
+// Commit saves the finished operation to the journal, then drops the server's oldest entries
+// beyond the entry limit and the oldest copies beyond the stash limit
+func (e *FileJournalEntry) Commit() {
+	if e == nil || len(e.Items) == 0 {
+		return
+	}
+
+	// Remember what the overwrite left behind, so undo won't clobber later changes
+	if e.Op == JournalOverwrite {
+		for i, item := range e.Items {
+			if info, err := os.Lstat(item.Path); err == nil {
+				e.Items[i].after = &journalFileState{Size: info.Size(), ModTime: info.ModTime()}
+			}
+		}
+	}
+	e.Undoable = e.canUndo()
+
+	fileJournalMux.Lock()
+	defer fileJournalMux.Unlock()
+
+	if err := saveJournalEntry(e); err != nil {
+		log.Printf("⚠️  Failed to journal %s in server %d: %v", e.Op, e.ServerID, err)
+		for _, item := range e.Items {
+			if item.stash != "" {
+				os.Remove(item.stash)
+			}
+		}
+		return
+	}
+
+	records, err := models.GetFileJournalEntries(e.ServerID)
+	if err == nil && len(records) > maxFileJournalEntries {
+		for _, record := range records[maxFileJournalEntries:] {
+			if entry, err := journalEntryFromRecord(record); err == nil {
+				removeJournalEntry(entry)
+			}
+		}
+	}
+
+	trimJournalStash()
+}
+
+// canUndo reports whether everything the entry changed can be put back
+func (e *FileJournalEntry) canUndo() bool {
+	for _, item := range e.Items {
+		if e.Op != JournalMove && item.stash == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// GetFileJournal returns a server's journaled operations, newest first
+func GetFileJournal(serverID uint) []FileJournalEntry {
+	entries := make([]FileJournalEntry, 0)
+
+	records, err := models.GetFileJournalEntries(serverID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load the file journal of server %d: %v", serverID, err)
+		return entries
+	}
+	for _, record := range records {
+		if entry, err := journalEntryFromRecord(record); err == nil {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
+
+// UndoLastFileOperation reverses a server's most recent journaled operation and removes it
+// from the journal. An operation that can't be undone is removed too, so the one before it
+// can be undone next; one blocked by files changed since is kept.
+func UndoLastFileOperation(serverID uint) (*FileJournalEntry, error) {
+	fileJournalMux.Lock()
+	defer fileJournalMux.Unlock()
+
+	records, err := models.GetFileJournalEntries(serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the file journal: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, ErrJournalEmpty
+	}
+	entry, err := journalEntryFromRecord(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.Undoable {
+		removeJournalEntry(entry)
+		return entry, ErrJournalNotUndoable
+	}
+
+	// Check every item first so a conflict leaves the files untouched
+	for _, item := range entry.Items {
+		switch entry.Op {
+		case JournalMove:
+			if _, err := os.Lstat(item.Target); err != nil {
+				return entry, fmt.Errorf("%w: %s is gone", ErrJournalConflict, filepath.Base(item.Target))
+			}
+		case JournalOverwrite:
+			info, err := os.Lstat(item.Path)
+			if err == nil && item.after != nil && (info.Size() != item.after.Size || !info.ModTime().Equal(item.after.ModTime)) {
+				return entry, fmt.Errorf("%w: %s was modified again", ErrJournalConflict, filepath.Base(item.Path))
+			}
+		}
+		if entry.Op != JournalOverwrite {
+			if _, err := os.Lstat(item.Path); err == nil {
+				return entry, fmt.Errorf("%w: %s exists again", ErrJournalConflict, filepath.Base(item.Path))
+			}
+		}
+	}
+
+	for i := len(entry.Items) - 1; i >= 0; i-- {
+		item := entry.Items[i]
+		var err error
+		if entry.Op == JournalMove {
+			err = os.Rename(item.Target, item.Path)
+		} else {
+			err = restoreJournalFile(item)
+		}
+		if err != nil {
+			// Items already restored are done; keep the rest for another attempt
+			entry.Items = entry.Items[:i+1]
+			if saveErr := saveJournalEntry(entry); saveErr != nil {
+				log.Printf("⚠️  Failed to update file journal entry %d: %v", entry.ID, saveErr)
+			}
+			return entry, fmt.Errorf("failed to restore %s: %w", filepath.Base(item.Path), err)
+		}
+		if item.stash != "" {
+			os.Remove(item.stash)
+			entry.Items[i].stash = ""
+		}
+	}
+
+	removeJournalEntry(entry)
+	return entry, nil
+}
+
+// restoreJournalFile writes a stashed copy back to where it was taken from
+func restoreJournalFile(item JournalItem) error {
+	info, err := os.Stat(item.stash)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(item.Path), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(item.stash)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	unlock := LockFileExclusive(item.Path)
+	defer unlock()
+	return ReplaceFile(item.Path, in, info.Mode().Perm())
+}
+
+// saveJournalEntry creates or updates an entry's database record. The caller holds fileJournalMux.
+func saveJournalEntry(entry *FileJournalEntry) error {
+	items := make([]journalItemRecord, 0, len(entry.Items))
+	for _, item := range entry.Items {
+		items = append(items, journalItemRecord{
+			Path:   item.Path,
+			Target: item.Target,
+			Size:   item.Size,
+			IsDir:  item.IsDir,
+			Stash:  item.stash,
+			After:  item.after,
+		})
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	record := &models.FileJournalEntry{
+		ID:        entry.ID,
+		ServerID:  entry.ServerID,
+		Op:        entry.Op,
+		Items:     string(data),
+		Undoable:  entry.Undoable,
+		CreatedAt: entry.Time,
+	}
+	if entry.ID == 0 {
+		err = models.CreateFileJournalEntry(record)
+	} else {
+		err = models.UpdateFileJournalEntry(record)
+	}
+	if err != nil {
+		return err
+	}
+	entry.ID = record.ID
+	return nil
+}
+
+// journalEntryFromRecord reads an entry back from its database record
+func journalEntryFromRecord(record models.FileJournalEntry) (*FileJournalEntry, error) {
+	var items []journalItemRecord
+	if err := json.Unmarshal([]byte(record.Items), &items); err != nil {
+		return nil, fmt.Errorf("file journal entry %d is unreadable: %w", record.ID, err)
+	}
+
+	entry := &FileJournalEntry{
+		ID:       record.ID,
+		ServerID: record.ServerID,
+		Op:       record.Op,
+		Items:    make([]JournalItem, 0, len(items)),
+		Time:     record.CreatedAt,
+		Undoable: record.Undoable,
+	}
+	for _, item := range items {
+		entry.Items = append(entry.Items, JournalItem{
+			Path:   item.Path,
+			Target: item.Target,
+			Size:   item.Size,
+			IsDir:  item.IsDir,
+			stash:  item.Stash,
+			after:  item.After,
+		})
+	}
+	return entry, nil
+}
+
+// removeJournalEntry drops an entry from the journal along with its copies. The caller holds fileJournalMux.
+func removeJournalEntry(entry *FileJournalEntry) {
+	for _, item := range entry.Items {
+		if item.stash != "" {
+			os.Remove(item.stash)
+		}
+	}
+	if err := models.DeleteFileJournalEntry(entry.ID); err != nil {
+		log.Printf("⚠️  Failed to remove file journal entry %d: %v", entry.ID, err)
+	}
+}
+
+// trimJournalStash deletes the oldest copies, across all servers, until the ones left fit the
+// stash limit. Their entries stay in the journal as not undoable. The caller holds fileJournalMux.
+func trimJournalStash() {
+	records, err := models.GetAllFileJournalEntries()
+	if err != nil {
+		return
+	}
+
+	entries := make([]*FileJournalEntry, 0, len(records))
+	var total int64
+	for _, record := range records {
+		entry, err := journalEntryFromRecord(record)
+		if err != nil {
+			continue
+		}
+		for _, item := range entry.Items {
+			if item.stash != "" {
+				total += item.Size
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	_, _, maxStash := config.GetFileJournal()
+	for _, entry := range entries {
+		if total <= maxStash {
+			break
+		}
+		dropped := false
+		for i, item := range entry.Items {
+			if item.stash == "" {
+				continue
+			}
+			os.Remove(item.stash)
+			total -= item.Size
+			entry.Items[i].stash = ""
+			dropped = true
+		}
+		if dropped {
+			entry.Undoable = entry.canUndo()
+			if err := saveJournalEntry(entry); err != nil {
+				log.Printf("⚠️  Failed to update file journal entry %d: %v", entry.ID, err)
+			}
+		}
+	}
+}
+
+// copyJournalFile copies src to dst, creating the journal folder when needed
+func copyJournalFile(src, dst string, perm os.FileMode) error {
+	if err := os.MkdirAll(fileJournalDir, 0700); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// CleanupStaleFileJournal removes copies no journal entry refers to any more, such as those
+// of an operation interrupted by a crash. Copies of journaled operations are kept so they can
+// still be undone after a restart.
+func CleanupStaleFileJournal() {
+	files, err := os.ReadDir(fileJournalDir)
+	if err != nil {
+		return
+	}
+
+	records, err := models.GetAllFileJournalEntries()
+	if err != nil {
+		log.Printf("⚠️  Failed to load the file journal: %v", err)
+		return
+	}
+	referenced := make(map[string]bool)
+	for _, record := range records {
+		entry, err := journalEntryFromRecord(record)
+		if err != nil {
+			continue
+		}
+		for _, item := range entry.Items {
+			if item.stash != "" {
+				referenced[filepath.Base(item.stash)] = true
+			}
+		}
+	}
+
+	removed := 0
+	for _, file := range files {
+		if referenced[file.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(fileJournalDir, file.Name())); err != nil {
+			log.Printf("⚠️  Failed to remove stale file journal copy %s: %v", file.Name(), err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		log.Printf("🧹 Removed %d stale file journal stash(es)", removed)
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"seiapanel/config"
+)
+
+// setupTestJournal turns the file journal on and keeps its copies in a temporary folder
+func setupTestJournal(t *testing.T) {
+	t.Helper()
+	setupTestDB(t)
+
+	previousConfig := config.AppConfig
+	config.AppConfig = &config.Config{FileJournal: true}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		config.AppConfig = previousConfig
+	})
+}
+
+// journalOverwrite overwrites path with content the way the file editor does, journaling it
+func journalOverwrite(t *testing.T, path, content string) {
+	t.Helper()
+
+	journal := NewFileJournalEntry(1, JournalOverwrite)
+	journal.Stash(path)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	journal.Commit()
+}
+
+// TestFileJournalSurvivesRestart checks that an overwrite can still be undone after the
+// panel restarts and cleans up its journal folder
+func TestFileJournalSurvivesRestart(t *testing.T) {
+	setupTestJournal(t)
+
+	path := filepath.Join(t.TempDir(), "server.properties")
+	writeTestFile(t, filepath.Dir(path), "server.properties", "motd=before")
+	journalOverwrite(t, path, "motd=after")
+
+	CleanupStaleFileJournal()
+
+	entries := GetFileJournal(1)
+	if len(entries) != 1 || !entries[0].Undoable {
+		t.Fatalf("journal after restart = %+v, want one undoable entry", entries)
+	}
+
+	if _, err := UndoLastFileOperation(1); err != nil {
+		t.Fatalf("UndoLastFileOperation: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "motd=before" {
+		t.Errorf("undo restored %q, want %q", data, "motd=before")
+	}
+	if entries := GetFileJournal(1); len(entries) != 0 {
+		t.Errorf("journal after undo = %+v, want it empty", entries)
+	}
+}
+
+// TestFileJournalUndoRefusesModifiedFile checks that undoing an overwrite doesn't clobber
+// changes made to the file afterwards
+func TestFileJournalUndoRefusesModifiedFile(t *testing.T) {
+	setupTestJournal(t)
+
+	path := filepath.Join(t.TempDir(), "server.properties")
+	writeTestFile(t, filepath.Dir(path), "server.properties", "motd=before")
+	journalOverwrite(t, path, "motd=after")
+
+	later := time.Now().Add(time.Minute)
+	if err := os.WriteFile(path, []byte("motd=edited again"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := UndoLastFileOperation(1); !errors.Is(err, ErrJournalConflict) {
+		t.Fatalf("UndoLastFileOperation error = %v, want ErrJournalConflict", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "motd=edited again" {
+		t.Errorf("file after refused undo = %q, want it unchanged", data)
+	}
+	if entries := GetFileJournal(1); len(entries) != 1 {
+		t.Errorf("journal after refused undo has %d entries, want the entry kept", len(entries))
+	}
+}
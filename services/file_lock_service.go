@@ -0,0 +1,129 @@
+package services
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// maxFileLocks caps how many paths hold a lock at once. Past the cap, new paths wait until
+// one is released, so every path only ever has a single lock.
+const maxFileLocks = 4096
+
+// fileLock is a read/write lock for one path, counted so it can be dropped once unused
+type fileLock struct {
+	mu   sync.RWMutex
+	refs int
+}
+
+var (
+	fileLocks     = make(map[string]*fileLock)
+	fileLocksMux  sync.Mutex
+	fileLocksFree = sync.NewCond(&fileLocksMux)
+)
+
+// LockFileShared takes a shared lock on a file path, for reads such as viewing, downloading
+// or archiving. Paths are absolute, so the lock is scoped to the server folder it is in.
+// The returned function releases the lock.
+func LockFileShared(path string) func() {
+	key, lock := acquireFileLock(path)
+	lock.mu.RLock()
+	return func() {
+		lock.mu.RUnlock()
+		releaseFileLock(key, lock)
+	}
+}
+
+// LockFileExclusive takes an exclusive lock on a file path for writing, so shared holders
+// never see a half-written file. The returned function releases the lock.
+func LockFileExclusive(path string) func() {
+	key, lock := acquireFileLock(path)
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		releaseFileLock(key, lock)
+	}
+}
+
+// acquireFileLock returns the lock for a path, creating it if needed, and counts the holder.
+// When the cap is reached it waits for another path's lock to be released.
+func acquireFileLock(path string) (string, *fileLock) {
+	key := filepath.Clean(path)
+
+	fileLocksMux.Lock()
+	defer fileLocksMux.Unlock()
+
+	lock, exists := fileLocks[key]
+	for !exists && len(fileLocks) >= maxFileLocks {
+		fileLocksFree.Wait()
+		lock, exists = fileLocks[key]
+	}
+	if !exists {
+		lock = &fileLock{}
+		fileLocks[key] = lock
+	}
+	lock.refs++
+	return key, lock
+}
+
+// releaseFileLock drops a holder and removes the lock once nobody holds or waits for it
+func releaseFileLock(key string, lock *fileLock) {
+	fileLocksMux.Lock()
+	defer fileLocksMux.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(fileLocks, key)
+		fileLocksFree.Signal()
+	}
+}
+
+// OpenFileShared opens a file for reading under its shared lock, released as soon as the file
+// is open. Writers replace files through ReplaceFile, so the open file keeps its content
+// however long it is read, without holding saves back.
+func OpenFileShared(path string) (*os.File, error) {
+	unlock := LockFileShared(path)
+	defer unlock()
+	return os.Open(path)
+}
+
+// ReplaceFile writes the content of r to path through a partial file renamed into place, so
+// readers that already opened the file keep reading the old content whole. An existing file
+// keeps its mode, and a symlink keeps pointing to the file that is replaced; perm only applies
+// to new files. The caller holds the path's exclusive lock.
+func ReplaceFile(path string, r io.Reader, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmpPath := PartialPath(path)
+	out, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	// The mode is set explicitly so the process umask doesn't narrow it
+	if err := out.Chmod(perm); err != nil {
+		out.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
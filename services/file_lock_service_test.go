@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestFileLockExclusiveWhenFull checks that writers of one path still exclude each other
+// when every lock slot is taken by other paths
+func TestFileLockExclusiveWhenFull(t *testing.T) {
+	releases := make([]func(), 0, maxFileLocks)
+	for i := 0; i < maxFileLocks; i++ {
+		releases = append(releases, LockFileShared(fmt.Sprintf("/locks/held/%d", i)))
+	}
+
+	const writers = 8
+	var inside, overlaps int32
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := LockFileExclusive("/locks/contended")
+			if atomic.AddInt32(&inside, 1) > 1 {
+				atomic.AddInt32(&overlaps, 1)
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&inside, -1)
+			unlock()
+		}()
+	}
+
+	// Writers wait for a slot rather than sharing a fallback lock
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&inside); n != 0 {
+		t.Fatalf("%d writer(s) got a lock while all slots were taken", n)
+	}
+
+	releases[0]()
+	wg.Wait()
+	for _, release := range releases[1:] {
+		release()
+	}
+
+	if overlaps != 0 {
+		t.Fatalf("writers of the same path overlapped %d time(s)", overlaps)
+	}
+
+	fileLocksMux.Lock()
+	left := len(fileLocks)
+	fileLocksMux.Unlock()
+	if left != 0 {
+		t.Fatalf("%d lock(s) left after every holder released", left)
+	}
+}
+
+// TestReplaceFileKeepsOpenReaders checks that a reader holding a file open reads the old
+// content whole while the file is replaced concurrently
+func TestReplaceFileKeepsOpenReaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.properties")
+	oldContent := strings.Repeat("a", 1<<20)
+	newContent := strings.Repeat("b", 1<<19)
+	if err := os.WriteFile(path, []byte(oldContent), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := OpenFileShared(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		unlock := LockFileExclusive(path)
+		defer unlock()
+		if err := ReplaceFile(path, strings.NewReader(newContent), 0644); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	read, err := io.ReadAll(file)
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(read) != oldContent {
+		t.Fatalf("reader saw a torn file: got %d bytes", len(read))
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(saved) != newContent {
+		t.Fatalf("replaced file has %d bytes, want %d", len(saved), len(newContent))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Fatalf("replaced file mode is %o, want 640", info.Mode().Perm())
+	}
+}
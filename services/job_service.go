@@ -0,0 +1,283 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job status values
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// jobRetention is how long finished jobs are kept around for status polling
+const jobRetention = 1 * time.Hour
+
+// Job tracks a long-running background operation (archive extraction, archive creation, ...)
+type Job struct {
+	ID             string                 `json:"id"`
+	ServerID       uint                   `json:"server_id"`
+	Type           string                 `json:"type"`
+	Status         string                 `json:"status"`
+	Targets        []string               `json:"targets"`
+	BytesProcessed int64                  `json:"bytes_processed"`
+	BytesTotal     int64                  `json:"bytes_total"`
+	FilesProcessed int                    `json:"files_processed"`
+	CurrentFile    string                 `json:"current_file"`
+	Error          string                 `json:"error,omitempty"`
+	Result         map[string]interface{} `json:"result,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+	FinishedAt     *time.Time             `json:"finished_at"`
+	mu             sync.Mutex
+}
+
+var (
+	jobs   = make(map[string]*Job)
+	jobMux sync.Mutex
+
+	// serverOperations holds the operation, such as a backup or restore, that currently owns
+	// each server's files. It is guarded by jobMux.
+	serverOperations = make(map[uint]string)
+)
+
+// ErrOperationInProgress is wrapped by the errors of operations refused because another
+// operation on the same server hasn't finished
+var ErrOperationInProgress = errors.New("operation in progress")
+
+// BeginServerOperation claims a server's files for an operation such as a backup or restore,
+// failing with an error wrapping ErrOperationInProgress while another one holds them. Every
+// successful call must be paired with EndServerOperation.
+func BeginServerOperation(serverID uint, operation string) error {
+	jobMux.Lock()
+	defer jobMux.Unlock()
+
+	if current, busy := serverOperations[serverID]; busy {
+		return fmt.Errorf("%w: a %s of this server is still running", ErrOperationInProgress, current)
+	}
+	serverOperations[serverID] = operation
+	return nil
+}
+
+// EndServerOperation releases a server claimed by BeginServerOperation
+func EndServerOperation(serverID uint) {
+	jobMux.Lock()
+	delete(serverOperations, serverID)
+	jobMux.Unlock()
+}
+
+// GetServerOperation returns the operation holding a server's files, or "" if none
+func GetServerOperation(serverID uint) string {
+	jobMux.Lock()
+	defer jobMux.Unlock()
+
+	return serverOperations[serverID]
+}
+
+// NewJob registers a new pending job for a server
+func NewJob(serverID uint, jobType string, targets []string) *Job {
+	job := &Job{
+		ID:        generateJobID(),
+		ServerID:  serverID,
+		Type:      jobType,
+		Status:    JobStatusPending,
+		Targets:   targets,
+		CreatedAt: time.Now(),
+	}
+
+	jobMux.Lock()
+	pruneFinishedJobs()
+	jobs[job.ID] = job
+	jobMux.Unlock()
+
+	return job
+}
+
+// GetJob retrieves a job by its ID
+func GetJob(id string) (*Job, bool) {
+	jobMux.Lock()
+	defer jobMux.Unlock()
+
+	job, exists := jobs[id]
+	return job, exists
+}
+
+// Start runs the job's work function in the background and records the outcome
+func (j *Job) Start(work func(job *Job) error) {
+	j.mu.Lock()
+	j.Status = JobStatusRunning
+	j.mu.Unlock()
+
+	go func() {
+		err := work(j)
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		now := time.Now()
+		j.FinishedAt = &now
+		j.CurrentFile = ""
+		if err != nil {
+			j.Status = JobStatusFailed
+			j.Error = err.Error()
+			log.Printf("❌ Job %s (%s) failed: %v", j.ID, j.Type, err)
+			return
+		}
+		j.Status = JobStatusCompleted
+		log.Printf("✅ Job %s (%s) completed", j.ID, j.Type)
+	}()
+}
+
+// Snapshot returns a copy of the job's current state that is safe to serialize
+func (j *Job) Snapshot() Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// The result map keeps changing while the job runs, so readers get their own copy
+	var result map[string]interface{}
+	if j.Result != nil {
+		result = make(map[string]interface{}, len(j.Result))
+		for key, value := range j.Result {
+			result[key] = value
+		}
+	}
+
+	return Job{
+		ID:             j.ID,
+		ServerID:       j.ServerID,
+		Type:           j.Type,
+		Status:         j.Status,
+		Targets:        j.Targets,
+		BytesProcessed: j.BytesProcessed,
+		BytesTotal:     j.BytesTotal,
+		FilesProcessed: j.FilesProcessed,
+		CurrentFile:    j.CurrentFile,
+		Error:          j.Error,
+		Result:         result,
+		CreatedAt:      j.CreatedAt,
+		FinishedAt:     j.FinishedAt,
+	}
+}
+
+// AddTotal increases the expected number of bytes the job will process
+func (j *Job) AddTotal(n int64) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.BytesTotal += n
+	j.mu.Unlock()
+}
+
+// AddBytes records bytes processed by the job
+func (j *Job) AddBytes(n int64) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.BytesProcessed += n
+	j.mu.Unlock()
+}
+
+// SetCurrentFile records the file currently being processed
+func (j *Job) SetCurrentFile(name string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.CurrentFile = name
+	j.mu.Unlock()
+}
+
+// FileDone increments the processed file counter
+func (j *Job) FileDone() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.FilesProcessed++
+	j.mu.Unlock()
+}
+
+// SetResult stores a result value reported alongside the job status
+func (j *Job) SetResult(key string, value interface{}) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	if j.Result == nil {
+		j.Result = make(map[string]interface{})
+	}
+	j.Result[key] = value
+	j.mu.Unlock()
+}
+
+// TrackReader wraps a reader so every byte read is counted as processed
+func (j *Job) TrackReader(r io.Reader) io.Reader {
+	if j == nil {
+		return r
+	}
+	return &jobProgressReader{reader: r, job: j}
+}
+
+// TrackWriter wraps a writer so every byte written is counted as processed
+func (j *Job) TrackWriter(w io.Writer) io.Writer {
+	if j == nil {
+		return w
+	}
+	return &jobProgressWriter{writer: w, job: j}
+}
+
+// jobProgressReader counts bytes read into a job
+type jobProgressReader struct {
+	reader io.Reader
+	job    *Job
+}
+
+func (pr *jobProgressReader) Read(p []byte) (int, error) {
+	n, err := pr.reader.Read(p)
+	pr.job.AddBytes(int64(n))
+	return n, err
+}
+
+// jobProgressWriter counts bytes written into a job
+type jobProgressWriter struct {
+	writer io.Writer
+	job    *Job
+}
+
+func (pw *jobProgressWriter) Write(p []byte) (int, error) {
+	n, err := pw.writer.Write(p)
+	pw.job.AddBytes(int64(n))
+	return n, err
+}
+
+// pruneFinishedJobs removes finished jobs older than the retention window (caller holds jobMux)
+func pruneFinishedJobs() {
+	cutoff := time.Now().Add(-jobRetention)
+	for id, job := range jobs {
+		job.mu.Lock()
+		expired := job.FinishedAt != nil && job.FinishedAt.Before(cutoff)
+		job.mu.Unlock()
+		if expired {
+			delete(jobs, id)
+		}
+	}
+}
+
+// generateJobID generates a random hex job ID
+func generateJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
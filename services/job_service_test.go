@@ -0,0 +1,36 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestJobSnapshotCopiesResult checks that a snapshot can be read while the job keeps
+// reporting results
+func TestJobSnapshotCopiesResult(t *testing.T) {
+	job := NewJob(0, "test", nil)
+	job.SetResult("first", 1)
+
+	snapshot := job.Snapshot()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			job.SetResult(fmt.Sprintf("key%d", i), i)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if _, err := json.Marshal(job.Snapshot()); err != nil {
+			t.Fatalf("failed to encode snapshot: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if len(snapshot.Result) != 1 {
+		t.Errorf("snapshot result changed after it was taken: %v", snapshot.Result)
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"seiapanel/models"
+)
+
+// maxLogExportBytes caps how much log text one export with rotated logs may write, so a
+// server with years of history can't fill the temp disk
+const maxLogExportBytes = 512 << 20
+
+// rotatedLogPattern matches the dated logs a server rotates out of latest.log, e.g. 2024-05-01-2.log.gz
+var rotatedLogPattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(\d+)\.log(\.gz)?$`)
+
+// ServerLogPath returns the path of the log file the server is currently writing
+func ServerLogPath(server *models.Server) string {
+	return filepath.Join(server.FolderPath, "logs", "latest.log")
+}
+
+// ListRotatedLogs returns the rotated log files of a server, oldest first
+func ListRotatedLogs(server *models.Server) ([]string, error) {
+	logDir := filepath.Join(server.FolderPath, "logs")
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	type rotatedLog struct {
+		name  string
+		date  string
+		index int
+	}
+	var logs []rotatedLog
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := rotatedLogPattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(match[2])
+		logs = append(logs, rotatedLog{name: entry.Name(), date: match[1], index: index})
+	}
+
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].date != logs[j].date {
+			return logs[i].date < logs[j].date
+		}
+		return logs[i].index < logs[j].index
+	})
+
+	paths := make([]string, len(logs))
+	for i, l := range logs {
+		paths[i] = filepath.Join(logDir, l.name)
+	}
+	return paths, nil
+}
+
+// ExportServerLogs writes the rotated logs followed by latest.log into a temporary file,
+// each preceded by a header naming its source. The caller removes the file when done.
+func ExportServerLogs(server *models.Server) (string, error) {
+	sources, err := ListRotatedLogs(server)
+	if err != nil {
+		return "", fmt.Errorf("failed to list rotated logs: %w", err)
+	}
+	if _, err := os.Stat(ServerLogPath(server)); err == nil {
+		sources = append(sources, ServerLogPath(server))
+	}
+
+	out, err := os.CreateTemp("", "seiapanel-logs-*.log")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer out.Close()
+
+	remaining := int64(maxLogExportBytes)
+	for _, source := range sources {
+		if remaining <= 0 {
+			fmt.Fprintf(out, "\n===== [export truncated at %d MiB] =====\n", maxLogExportBytes>>20)
+			break
+		}
+		fmt.Fprintf(out, "===== %s =====\n", filepath.Base(source))
+		written, err := copyLogFile(out, source, remaining)
+		if err != nil {
+			// A corrupt rotated log shouldn't sink the whole export
+			fmt.Fprintf(out, "[failed to read %s: %v]\n", filepath.Base(source), err)
+		}
+		remaining -= written
+	}
+
+	if err := out.Close(); err != nil {
+		os.Remove(out.Name())
+		return "", fmt.Errorf("failed to write log export: %w", err)
+	}
+	return out.Name(), nil
+}
+
+// copyLogFile copies up to limit bytes of a log into w, decompressing gzipped logs
+func copyLogFile(w io.Writer, path string, limit int64) (int64, error) {
+	unlock := LockFileShared(path)
+	defer unlock()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return 0, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	return io.Copy(w, io.LimitReader(reader, limit))
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"seiapanel/models"
+)
+
+// LogEntry is a log line split by the server's log pattern
+type LogEntry struct {
+	Timestamp *time.Time `json:"timestamp"` // Nil until the first line with a readable time
+	Level     string     `json:"level,omitempty"`
+	Message   string     `json:"message"`
+	Raw       string     `json:"raw"`
+}
+
+// LogParser splits log lines into entries using a server's configured pattern
+type LogParser struct {
+	pattern  *regexp.Regexp
+	layout   string
+	hasDate  bool // The layout carries a month and day
+	timeIdx  int
+	levelIdx int
+	msgIdx   int
+}
+
+// NewLogParser returns a parser for the server's log format, or nil when none is configured
+func NewLogParser(server *models.Server) (*LogParser, error) {
+	if server.LogPattern == "" {
+		return nil, nil
+	}
+
+	pattern, err := regexp.Compile(server.LogPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// A date survives a round trip through the layout only if the layout includes it
+	probe := time.Date(2000, time.March, 15, 0, 0, 0, 0, time.UTC)
+	parsed, err := time.Parse(server.LogTimeLayout, probe.Format(server.LogTimeLayout))
+	hasDate := err == nil && parsed.Month() == probe.Month() && parsed.Day() == probe.Day()
+
+	return &LogParser{
+		pattern:  pattern,
+		layout:   server.LogTimeLayout,
+		hasDate:  hasDate,
+		timeIdx:  pattern.SubexpIndex("time"),
+		levelIdx: pattern.SubexpIndex("level"),
+		msgIdx:   pattern.SubexpIndex("msg"),
+	}, nil
+}
+
+// Parse splits lines into entries. Lines that don't match the pattern, like stack traces,
+// keep the timestamp and level of the line before them so time filters don't drop them.
+// Timestamps without a date are placed on the most recent day that keeps them before now.
+func (p *LogParser) Parse(lines []string, now time.Time) []LogEntry {
+	entries := make([]LogEntry, 0, len(lines))
+
+	var lastTime *time.Time
+	var lastLevel string
+	for _, line := range lines {
+		entry := LogEntry{Message: line, Raw: line, Timestamp: lastTime, Level: lastLevel}
+
+		if match := p.pattern.FindStringSubmatch(line); match != nil {
+			if t, ok := p.parseTime(match[p.timeIdx], now); ok {
+				entry.Timestamp = &t
+				lastTime = &t
+			}
+			if p.levelIdx >= 0 {
+				entry.Level = strings.ToUpper(strings.TrimSpace(match[p.levelIdx]))
+				lastLevel = entry.Level
+			}
+			if p.msgIdx >= 0 {
+				entry.Message = match[p.msgIdx]
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// parseTime reads a timestamp in the configured layout, filling in today's date or the
+// current year when the layout lacks them
+func (p *LogParser) parseTime(value string, now time.Time) (time.Time, bool) {
+	t, err := time.ParseInLocation(p.layout, strings.TrimSpace(value), now.Location())
+	if err != nil {
+		return time.Time{}, false
+	}
+	if t.Year() != 0 {
+		return t, true
+	}
+
+	if !p.hasDate {
+		t = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), now.Location())
+		// A time later than now belongs to yesterday's part of the log
+		if t.After(now.Add(time.Minute)) {
+			t = t.AddDate(0, 0, -1)
+		}
+		return t, true
+	}
+
+	t = t.AddDate(now.Year(), 0, 0)
+	// A date later than now belongs to last year's part of the log
+	if t.After(now.Add(time.Minute)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t, true
+}
+
+// FilterLogEntries keeps entries whose timestamp falls within [since, until]. Zero bounds
+// are open; entries without a timestamp are only kept when there is no since bound.
+func FilterLogEntries(entries []LogEntry, since, until time.Time) []LogEntry {
+	if since.IsZero() && until.IsZero() {
+		return entries
+	}
+
+	filtered := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Timestamp == nil {
+			if since.IsZero() {
+				filtered = append(filtered, entry)
+			}
+			continue
+		}
+		if !since.IsZero() && entry.Timestamp.Before(since) {
+			continue
+		}
+		if !until.IsZero() && entry.Timestamp.After(until) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"seiapanel/models"
+)
+
+// ErrMacroRunning is returned when a macro is started while a previous run is still going
+var ErrMacroRunning = errors.New("this macro is already running")
+
+var (
+	runningMacros   = make(map[uint]bool)
+	runningMacroMux sync.Mutex
+)
+
+// RunCommandMacro sends a macro's commands to a running server in the background, waiting
+// each step's delay between commands. The run stops early if the server goes down.
+// onDone is called with the number of commands sent and the error that stopped the run, if any.
+func RunCommandMacro(server *models.Server, macro *models.CommandMacro, onDone func(sent int, err error)) error {
+	if !IsServerRunning(server) {
+		return errors.New("server is not running")
+	}
+
+	runningMacroMux.Lock()
+	if runningMacros[macro.ID] {
+		runningMacroMux.Unlock()
+		return ErrMacroRunning
+	}
+	runningMacros[macro.ID] = true
+	runningMacroMux.Unlock()
+
+	go func() {
+		defer func() {
+			runningMacroMux.Lock()
+			delete(runningMacros, macro.ID)
+			runningMacroMux.Unlock()
+		}()
+
+		sent, err := runMacroSteps(server, macro.Steps)
+		if err != nil {
+			log.Printf("❌ Macro '%s' on %s stopped after %d of %d command(s): %v", macro.Name, server.Name, sent, len(macro.Steps), err)
+		} else {
+			log.Printf("✅ Macro '%s' on %s sent %d command(s)", macro.Name, server.Name, sent)
+		}
+		if onDone != nil {
+			onDone(sent, err)
+		}
+	}()
+
+	return nil
+}
+
+// IsMacroRunning reports whether a macro is currently being executed
+func IsMacroRunning(macroID uint) bool {
+	runningMacroMux.Lock()
+	defer runningMacroMux.Unlock()
+	return runningMacros[macroID]
+}
+
+// runMacroSteps sends each step in order and returns how many commands were sent
+func runMacroSteps(server *models.Server, steps []models.MacroStep) (int, error) {
+	for i, step := range steps {
+		if !IsServerRunning(server) {
+			return i, errors.New("server stopped")
+		}
+		if err := SendCommand(server, step.Command); err != nil {
+			return i, fmt.Errorf("command %d: %w", i+1, err)
+		}
+
+		// No need to wait after the last command
+		if step.DelaySeconds > 0 && i < len(steps)-1 {
+			time.Sleep(time.Duration(step.DelaySeconds) * time.Second)
+		}
+	}
+	return len(steps), nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"seiapanel/config"
+)
+
+// Notification events
+const (
+	EventResourceAlert    = "resource.alert"
+	EventResourceRecovery = "resource.recovered"
+	EventDiskLow          = "disk.low"
+	EventDiskRecovery     = "disk.recovered"
+	EventFDLow            = "fd.low"
+	EventFDRecovery       = "fd.recovered"
+)
+
+// Notification is the payload posted to the notification webhook.
+// "content" makes the payload directly usable with Discord-style webhooks.
+type Notification struct {
+	Event     string                 `json:"event"`
+	Server    string                 `json:"server,omitempty"`
+	Message   string                 `json:"message"`
+	Content   string                 `json:"content"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+var notifyClient = &http.Client{Timeout: 10 * time.Second}
+
+// Notify sends a notification to the configured webhook in the background.
+// It is a no-op when no webhook is configured.
+func Notify(event, serverName, message string, data map[string]interface{}) {
+	webhookURL := config.GetNotifyWebhookURL()
+	if webhookURL == "" {
+		return
+	}
+
+	content := message
+	if serverName != "" {
+		content = fmt.Sprintf("[%s] %s", serverName, message)
+	}
+
+	notification := Notification{
+		Event:     event,
+		Server:    serverName,
+		Message:   message,
+		Content:   content,
+		Data:      data,
+		Timestamp: time.Now(),
+	}
+
+	go func() {
+		if err := sendNotification(webhookURL, notification); err != nil {
+			log.Printf("⚠️  Failed to send %s notification: %v", event, err)
+		}
+	}()
+}
+
+// SendTestNotification synchronously sends a test notification so configuration errors can be reported
+func SendTestNotification(webhookURL string) error {
+	_, err := DeliverTestNotification(webhookURL)
+	return err
+}
+
+// DeliverTestNotification synchronously sends a sample notification and returns the HTTP
+// status the webhook answered with (0 when no response was received)
+func DeliverTestNotification(webhookURL string) (int, error) {
+	return deliverNotification(webhookURL, Notification{
+		Event:     "test",
+		Message:   "Test notification from Seia Panel",
+		Content:   "Test notification from Seia Panel",
+		Data:      map[string]interface{}{"sample": true},
+		Timestamp: time.Now(),
+	})
+}
+
+// sendNotification posts a notification to a webhook URL
+func sendNotification(webhookURL string, notification Notification) error {
+	_, err := deliverNotification(webhookURL, notification)
+	return err
+}
+
+// deliverNotification posts a notification to a webhook URL and returns the response status
+func deliverNotification(webhookURL string, notification Notification) (int, error) {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	resp, err := notifyClient.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"seiapanel/config"
+)
+
+// ErrOperationTimeout is returned when a file system or network operation doesn't finish
+// within the configured operation timeout
+var ErrOperationTimeout = errors.New("operation timed out, the storage may be unresponsive")
+
+// WithOperationTimeout derives a context that ends after the configured operation timeout
+func WithOperationTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, config.GetOperationTimeout())
+}
+
+// RunWithContext runs fn and waits for it until ctx ends. A system call stuck on an
+// unresponsive network mount can't be interrupted, so fn is then left to finish in the
+// background and ErrOperationTimeout (or the cancellation error) is returned right away.
+// fn must not touch anything the caller reads after RunWithContext returns early.
+func RunWithContext(ctx context.Context, fn func() error) error {
+	if ctx.Err() != nil {
+		return operationContextError(ctx)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && ctx.Err() != nil {
+			return operationContextError(ctx)
+		}
+		return err
+	case <-ctx.Done():
+		return operationContextError(ctx)
+	}
+}
+
+// operationContextError reports an ended context as ErrOperationTimeout when its deadline passed
+func operationContextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrOperationTimeout
+	}
+	return ctx.Err()
+}
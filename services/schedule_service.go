@@ -1,10 +1,15 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
+	"seiapanel/config"
 	"seiapanel/models"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
@@ -13,35 +18,172 @@ import (
 type ScheduleService struct {
 	cron      *cron.Cron
 	schedules map[uint]cron.EntryID // maps schedule ID to cron entry ID
+	policies  map[uint]cron.EntryID // maps backup policy ID to cron entry ID
+	running   map[uint]int          // maps schedule ID to its number of in-progress runs
+	catchUp   bool                  // Run schedules that missed a fire time while the panel was down
 	mu        sync.RWMutex
+	runMu     sync.Mutex
 }
 
+// errScheduleSkipped marks a run whose action didn't apply, e.g. the server was offline
+var errScheduleSkipped = errors.New("skipped")
+
 var (
 	scheduleService *ScheduleService
 	serviceOnce     sync.Once
+	schedulerErr    error        // Why the last start failed or was incomplete, nil when healthy
+	schedulerMux    sync.RWMutex // Guards scheduleService and schedulerErr, which a restart replaces
+	restartMux      sync.Mutex   // Serializes scheduler restarts
+)
+
+// cronParser accepts standard 5-field expressions, 6-field expressions with a leading
+// seconds field, and descriptors such as "@daily" or "@every 6h"
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
 )
 
+// ParseCronSpec parses a schedule expression in any form the scheduler accepts
+func ParseCronSpec(spec string) (cron.Schedule, error) {
+	return cronParser.Parse(spec)
+}
+
+// PreviewCronRuns returns the next count times a schedule expression fires after from, parsed
+// exactly as the scheduler would. Nothing is registered.
+func PreviewCronRuns(spec string, from time.Time, count int) ([]time.Time, error) {
+	schedule, err := ParseCronSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]time.Time, 0, count)
+	for next := schedule.Next(from); len(runs) < count && !next.IsZero(); next = schedule.Next(next) {
+		runs = append(runs, next)
+	}
+	return runs, nil
+}
+
 // InitScheduler initializes the schedule service and starts the cron scheduler
 func InitScheduler() {
 	serviceOnce.Do(func() {
-		scheduleService = &ScheduleService{
-			cron:      cron.New(),
-			schedules: make(map[uint]cron.EntryID),
+		if err := startScheduler(true); err != nil {
+			log.Printf("❌ Schedule service did not start cleanly: %v", err)
+		} else {
+			log.Println("✅ Schedule service initialized and started")
 		}
 
-		// Start the cron scheduler
-		scheduleService.cron.Start()
-		log.Println("✅ Schedule service initialized and started")
+		// Trim schedule run history and sweep leftovers of interrupted operations now;
+		// the scheduler repeats both periodically
+		go PruneScheduleRunHistory()
+		go CleanupTempArtifacts()
+	})
+}
 
-		// Load all enabled schedules from database
-		if err := scheduleService.LoadAllSchedules(); err != nil {
-			log.Printf("⚠️  Warning: Failed to load schedules: %v", err)
+// startScheduler builds and starts a schedule service, then loads every enabled schedule and
+// backup policy into it. A service that fails to start is left nil; one that starts but can't
+// load everything keeps running. Either way the error is kept for SchedulerStatus. Missed
+// runs are only caught up with catchUp, on the first start after the panel comes up.
+func startScheduler(catchUp bool) (err error) {
+	service := &ScheduleService{
+		cron:      cron.New(cron.WithParser(cronParser)),
+		schedules: make(map[uint]cron.EntryID),
+		policies:  make(map[uint]cron.EntryID),
+		running:   make(map[uint]int),
+		catchUp:   catchUp,
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			service.cron.Stop()
+			service = nil
+			err = fmt.Errorf("scheduler failed to start: %v", r)
 		}
-	})
+
+		schedulerMux.Lock()
+		scheduleService = service
+		schedulerErr = err
+		schedulerMux.Unlock()
+	}()
+
+	// Start the cron scheduler
+	service.cron.Start()
+
+	var errs []error
+
+	// Load all enabled schedules from database
+	if err := service.LoadAllSchedules(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to load schedules: %w", err))
+	}
+
+	// Load all enabled backup policies from database
+	if err := service.LoadAllBackupPolicies(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to load backup policies: %w", err))
+	}
+
+	// Trim schedule run history hourly
+	if _, err := service.cron.AddFunc(scheduleRunPruneSpec, PruneScheduleRunHistory); err != nil {
+		errs = append(errs, fmt.Errorf("failed to schedule run history cleanup: %w", err))
+	}
+
+	// Sweep leftovers of interrupted operations periodically
+	if _, err := service.cron.AddFunc(tempCleanupSpec, CleanupTempArtifacts); err != nil {
+		errs = append(errs, fmt.Errorf("failed to schedule temp file cleanup: %w", err))
+	}
+
+	return errors.Join(errs...)
 }
 
-// GetScheduleService returns the singleton schedule service instance
+// RestartScheduler stops the schedule service and starts a new one from the database. Runs
+// already in progress finish under the old service. Missed runs aren't caught up again, since
+// the new service can't see the old one's runs to skip overlaps and a failed run would be
+// retried on every restart.
+func RestartScheduler() error {
+	restartMux.Lock()
+	defer restartMux.Unlock()
+
+	if old := GetScheduleService(); old != nil {
+		old.cron.Stop()
+	}
+
+	if err := startScheduler(false); err != nil {
+		log.Printf("❌ Schedule service restarted with errors: %v", err)
+		return err
+	}
+	log.Println("✅ Schedule service restarted")
+	return nil
+}
+
+// SchedulerStatus reports whether the schedule service is running and why its last start
+// failed or was incomplete, if it did
+func SchedulerStatus() (bool, error) {
+	schedulerMux.RLock()
+	defer schedulerMux.RUnlock()
+	return scheduleService != nil, schedulerErr
+}
+
+// scheduleRunPruneSpec is when the internal run history cleanup runs
+const scheduleRunPruneSpec = "@hourly"
+
+// tempCleanupSpec is when the internal temp file cleanup runs
+const tempCleanupSpec = "@every 6h"
+
+// PruneScheduleRunHistory applies the configured run history retention
+func PruneScheduleRunHistory() {
+	keep, maxAgeDays := config.GetScheduleRunRetention()
+
+	deleted, err := models.PruneScheduleRuns(keep, time.Duration(maxAgeDays)*24*time.Hour)
+	if err != nil {
+		log.Printf("❌ Failed to prune schedule run history: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("🧹 Pruned %d schedule run(s) from history", deleted)
+	}
+}
+
+// GetScheduleService returns the singleton schedule service instance, nil when it isn't running
 func GetScheduleService() *ScheduleService {
+	schedulerMux.RLock()
+	defer schedulerMux.RUnlock()
 	return scheduleService
 }
 
@@ -55,17 +197,40 @@ func (s *ScheduleService) LoadAllSchedules() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	now := time.Now()
 	for _, schedule := range schedules {
 		if err := s.addScheduleInternal(schedule); err != nil {
 			log.Printf("⚠️  Failed to add schedule %d (%s): %v", schedule.ID, schedule.Name, err)
-		} else {
-			log.Printf("✅ Loaded schedule: %s (ID: %d)", schedule.Name, schedule.ID)
+			continue
+		}
+		log.Printf("✅ Loaded schedule: %s (ID: %d)", schedule.Name, schedule.ID)
+
+		// Run missed schedules once, no matter how many fire times were missed
+		if s.catchUp && schedule.CatchUp && missedRun(schedule, now) {
+			log.Printf("⏰ Schedule %s (ID: %d) missed a run during downtime, catching up", schedule.Name, schedule.ID)
+			go s.executeSchedule(schedule, models.ScheduleTriggerCatchUp)
 		}
 	}
 
 	return nil
 }
 
+// missedRun reports whether a schedule had a fire time between its last run and now.
+// Schedules that never ran are measured from when they were created.
+func missedRun(schedule models.Schedule, now time.Time) bool {
+	cronSchedule, err := ParseCronSpec(schedule.GetCronExpression())
+	if err != nil {
+		return false
+	}
+
+	since := schedule.CreatedAt
+	if schedule.LastRunAt != nil {
+		since = *schedule.LastRunAt
+	}
+
+	return cronSchedule.Next(since).Before(now)
+}
+
 // AddSchedule adds a schedule to the cron scheduler
 func (s *ScheduleService) AddSchedule(schedule models.Schedule) error {
 	if !schedule.Enabled {
@@ -85,18 +250,18 @@ func (s *ScheduleService) addScheduleInternal(schedule models.Schedule) error {
 		return fmt.Errorf("schedule %d already exists in cron", schedule.ID)
 	}
 
-	// Get cron expression
+	// Get cron expression; descriptors and expressions with seconds parse the same way
 	cronExpr := schedule.GetCronExpression()
-
-	// Add to cron scheduler
-	entryID, err := s.cron.AddFunc(cronExpr, func() {
-		s.executeSchedule(schedule)
-	})
-
+	cronSchedule, err := ParseCronSpec(cronExpr)
 	if err != nil {
-		return fmt.Errorf("failed to add cron job: %w", err)
+		return fmt.Errorf("invalid cron expression %q: %w", cronExpr, err)
 	}
 
+	// Add to cron scheduler
+	entryID := s.cron.Schedule(cronSchedule, cron.FuncJob(func() {
+		s.executeSchedule(schedule, models.ScheduleTriggerCron)
+	}))
+
 	// Store entry ID
 	s.schedules[schedule.ID] = entryID
 
@@ -139,137 +304,486 @@ func (s *ScheduleService) UpdateSchedule(schedule models.Schedule) error {
 	return nil
 }
 
+// ScheduleEntry describes how a schedule in the database is registered in the cron engine
+type ScheduleEntry struct {
+	ScheduleID uint       `json:"schedule_id"`
+	Name       string     `json:"name"`
+	Enabled    bool       `json:"enabled"`    // Enabled in the database
+	Registered bool       `json:"registered"` // Registered with cron
+	NextRun    *time.Time `json:"next_run"`
+	Drift      bool       `json:"drift"` // Enabled and registered disagree
+}
+
+// GetScheduleEntries reports the live cron registration of the given schedules
+func (s *ScheduleService) GetScheduleEntries(schedules []models.Schedule) []ScheduleEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]ScheduleEntry, 0, len(schedules))
+	for _, schedule := range schedules {
+		entry := ScheduleEntry{
+			ScheduleID: schedule.ID,
+			Name:       schedule.Name,
+			Enabled:    schedule.Enabled,
+		}
+
+		if entryID, exists := s.schedules[schedule.ID]; exists {
+			entry.Registered = true
+			if next := s.cron.Entry(entryID).Next; !next.IsZero() {
+				entry.NextRun = &next
+			}
+		}
+		entry.Drift = entry.Enabled != entry.Registered
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// UpcomingRun is a future execution of a schedule registered in the cron engine
+type UpcomingRun struct {
+	ScheduleID uint      `json:"schedule_id"`
+	ServerID   uint      `json:"server_id"`
+	Name       string    `json:"name"`
+	Action     string    `json:"action"`
+	Time       time.Time `json:"time"`
+}
+
+// GetUpcomingRuns returns the next limit executions of the given schedules in chronological
+// order. Schedules that fire often may appear several times; unregistered ones never do.
+func (s *ScheduleService) GetUpcomingRuns(schedules []models.Schedule, limit int) []UpcomingRun {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	runs := make([]UpcomingRun, 0)
+	for _, schedule := range schedules {
+		entryID, exists := s.schedules[schedule.ID]
+		if !exists {
+			continue
+		}
+		entry := s.cron.Entry(entryID)
+		if entry.Schedule == nil {
+			continue
+		}
+
+		// No schedule contributes more than limit runs, so the merged list is complete
+		next := entry.Next
+		if next.IsZero() {
+			next = entry.Schedule.Next(time.Now())
+		}
+		for i := 0; i < limit && !next.IsZero(); i++ {
+			runs = append(runs, UpcomingRun{
+				ScheduleID: schedule.ID,
+				ServerID:   schedule.ServerID,
+				Name:       schedule.Name,
+				Action:     schedule.Action,
+				Time:       next,
+			})
+			next = entry.Schedule.Next(next)
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Time.Before(runs[j].Time)
+	})
+	return runs[:min(len(runs), limit)]
+}
+
+// ReconcileSchedules re-syncs the cron engine with the given schedules: enabled schedules are
+// re-registered with their current settings and disabled ones are removed. It returns the IDs
+// that were out of sync and any schedules that still failed to register.
+func (s *ScheduleService) ReconcileSchedules(schedules []models.Schedule) ([]uint, map[uint]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fixed := make([]uint, 0)
+	failed := make(map[uint]string)
+
+	for _, schedule := range schedules {
+		entryID, registered := s.schedules[schedule.ID]
+		if registered != schedule.Enabled {
+			fixed = append(fixed, schedule.ID)
+		}
+
+		// Always drop the old entry so registered jobs pick up the latest settings
+		if registered {
+			s.cron.Remove(entryID)
+			delete(s.schedules, schedule.ID)
+		}
+
+		if !schedule.Enabled {
+			continue
+		}
+
+		if err := s.addScheduleInternal(schedule); err != nil {
+			log.Printf("❌ Failed to reconcile schedule %d (%s): %v", schedule.ID, schedule.Name, err)
+			failed[schedule.ID] = err.Error()
+		}
+	}
+
+	return fixed, failed
+}
+
 // ExecuteScheduleManually executes a schedule immediately (manual trigger)
 func (s *ScheduleService) ExecuteScheduleManually(schedule models.Schedule) {
 	log.Printf("🎯 Manual execution triggered for schedule: %s (ID: %d)", schedule.Name, schedule.ID)
-	s.executeSchedule(schedule)
+	s.executeSchedule(schedule, models.ScheduleTriggerManual)
+}
+
+// beginRun registers an in-progress run. It refuses when the schedule skips overlapping
+// runs and a previous run hasn't finished yet.
+func (s *ScheduleService) beginRun(schedule models.Schedule) bool {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	if schedule.SkipOverlap && s.running[schedule.ID] > 0 {
+		return false
+	}
+	s.running[schedule.ID]++
+	return true
+}
+
+// endRun unregisters an in-progress run
+func (s *ScheduleService) endRun(scheduleID uint) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	s.running[scheduleID]--
+	if s.running[scheduleID] <= 0 {
+		delete(s.running, scheduleID)
+	}
+}
+
+// schedulesPaused reports whether a user has paused all scheduled automation
+func schedulesPaused(userID uint) bool {
+	user, err := models.GetUserByID(userID)
+	return err == nil && user.SchedulesPaused
+}
+
+// recordScheduleRun stores a run in the schedule's history
+func recordScheduleRun(schedule models.Schedule, trigger, status, message string, startedAt time.Time) {
+	if _, err := models.CreateScheduleRun(schedule.ID, schedule.ServerID, trigger, status, message, startedAt, time.Now()); err != nil {
+		log.Printf("⚠️  Schedule %d: Failed to record run history: %v", schedule.ID, err)
+	}
 }
 
 // executeSchedule executes the action for a schedule
-func (s *ScheduleService) executeSchedule(schedule models.Schedule) {
+func (s *ScheduleService) executeSchedule(schedule models.Schedule, trigger string) {
+	startedAt := time.Now()
+
+	if !s.beginRun(schedule) {
+		log.Printf("⚠️  Schedule %d: Previous run still in progress, skipping overlapping run", schedule.ID)
+		recordScheduleRun(schedule, trigger, models.ScheduleRunSkippedOverlap, "previous run still in progress", startedAt)
+		return
+	}
+	defer s.endRun(schedule.ID)
+
 	log.Printf("⏰ Executing schedule: %s (ID: %d, Action: %s)", schedule.Name, schedule.ID, schedule.Action)
 
 	// Get the server
 	server, err := models.GetServerByID(schedule.ServerID)
 	if err != nil {
 		log.Printf("❌ Schedule %d: Failed to get server: %v", schedule.ID, err)
+		recordScheduleRun(schedule, trigger, models.ScheduleRunFailed, "server not found", startedAt)
 		return
 	}
 
+	// Paused automation skips timed runs; an operator's manual run still goes through
+	if trigger != models.ScheduleTriggerManual && schedulesPaused(server.UserID) {
+		log.Printf("⏸️  Schedule %d: Schedules are paused, skipping", schedule.ID)
+		recordScheduleRun(schedule, trigger, models.ScheduleRunSkipped, "schedules paused", startedAt)
+		return
+	}
+
+	// Spread timed backups that share a fire time so they don't all hit the disk at once
+	if schedule.Action == "backup" && trigger != models.ScheduleTriggerManual {
+		if window := config.GetScheduleBackupJitter(); window > 0 {
+			delay := time.Duration(rand.Int63n(int64(window)))
+			log.Printf("⏰ Schedule %d: Delaying backup by %s to spread load", schedule.ID, delay.Round(time.Second))
+			time.Sleep(delay)
+			startedAt = time.Now()
+		}
+	}
+
 	// Execute action based on type
 	switch schedule.Action {
 	case "send_command":
-		s.executeSendCommand(server, schedule)
+		err = s.executeSendCommand(server, schedule)
 	case "start_server":
-		s.executeStartServer(server, schedule)
+		err = s.executeStartServer(server, schedule)
 	case "restart_server":
-		s.executeRestartServer(server, schedule)
+		err = s.executeRestartServer(server, schedule)
+	case "reload_server":
+		err = s.executeReloadServer(server, schedule)
 	case "stop_server":
-		s.executeStopServer(server, schedule)
+		err = s.executeStopServer(server, schedule)
 	case "backup":
-		s.executeBackup(server, schedule)
+		err = s.executeBackupWithRetry(server, schedule, trigger)
 	default:
 		log.Printf("❌ Schedule %d: Unknown action: %s", schedule.ID, schedule.Action)
+		recordScheduleRun(schedule, trigger, models.ScheduleRunFailed, "unknown action: "+schedule.Action, startedAt)
+		return
+	}
+
+	switch {
+	case errors.Is(err, errScheduleSkipped):
+		recordScheduleRun(schedule, trigger, models.ScheduleRunSkipped, err.Error(), startedAt)
+	case err != nil:
+		recordScheduleRun(schedule, trigger, models.ScheduleRunFailed, err.Error(), startedAt)
+		return
+	default:
+		recordScheduleRun(schedule, trigger, models.ScheduleRunSuccess, "", startedAt)
+	}
+
+	// Record the run so missed runs can be detected after downtime
+	if err := schedule.RecordRun(time.Now()); err != nil {
+		log.Printf("⚠️  Schedule %d: Failed to record last run: %v", schedule.ID, err)
 	}
 }
 
 // executeSendCommand sends a command to the server
-func (s *ScheduleService) executeSendCommand(server *models.Server, schedule models.Schedule) {
+func (s *ScheduleService) executeSendCommand(server *models.Server, schedule models.Schedule) error {
 	// Check if server is running
 	if !IsServerRunning(server) {
 		log.Printf("⚠️  Schedule %d: Server %s is offline, skipping command", schedule.ID, server.Name)
-		return
+		return fmt.Errorf("%w: server offline", errScheduleSkipped)
 	}
 
 	// Send command
 	if err := SendCommand(server, schedule.Command); err != nil {
 		log.Printf("❌ Schedule %d: Failed to send command to %s: %v", schedule.ID, server.Name, err)
-		return
+		return err
 	}
 
 	log.Printf("✅ Schedule %d: Command sent to %s: %s", schedule.ID, server.Name, schedule.Command)
+
+	return nil
 }
 
 // executeStartServer starts the server
-func (s *ScheduleService) executeStartServer(server *models.Server, schedule models.Schedule) {
+func (s *ScheduleService) executeStartServer(server *models.Server, schedule models.Schedule) error {
 	// Check if server is already running
 	if IsServerRunning(server) {
 		log.Printf("⚠️  Schedule %d: Server %s is already online, skipping start", schedule.ID, server.Name)
-		return
+		return fmt.Errorf("%w: server already online", errScheduleSkipped)
 	}
 
 	// Start server
 	if err := StartServer(server); err != nil {
 		log.Printf("❌ Schedule %d: Failed to start server %s: %v", schedule.ID, server.Name, err)
-		return
+		return err
 	}
 
 	log.Printf("✅ Schedule %d: Started server %s", schedule.ID, server.Name)
+
+	return nil
 }
 
 // executeRestartServer restarts the server
-func (s *ScheduleService) executeRestartServer(server *models.Server, schedule models.Schedule) {
+func (s *ScheduleService) executeRestartServer(server *models.Server, schedule models.Schedule) error {
 	// Check if server is running
 	if !IsServerRunning(server) {
 		log.Printf("⚠️  Schedule %d: Server %s is offline, skipping restart", schedule.ID, server.Name)
-		return
+		return fmt.Errorf("%w: server offline", errScheduleSkipped)
 	}
 
 	// Restart server
 	if err := RestartServer(server); err != nil {
 		log.Printf("❌ Schedule %d: Failed to restart server %s: %v", schedule.ID, server.Name, err)
-		return
+		return err
 	}
 
 	log.Printf("✅ Schedule %d: Restarted server %s", schedule.ID, server.Name)
+
+	return nil
+}
+
+// executeReloadServer sends the server's reload command
+func (s *ScheduleService) executeReloadServer(server *models.Server, schedule models.Schedule) error {
+	// Check if server is running
+	if !IsServerRunning(server) {
+		log.Printf("⚠️  Schedule %d: Server %s is offline, skipping reload", schedule.ID, server.Name)
+		return fmt.Errorf("%w: server offline", errScheduleSkipped)
+	}
+
+	if err := ReloadServer(server); err != nil {
+		log.Printf("❌ Schedule %d: Failed to reload server %s: %v", schedule.ID, server.Name, err)
+		return err
+	}
+
+	log.Printf("✅ Schedule %d: Reloaded server %s", schedule.ID, server.Name)
+
+	return nil
 }
 
 // executeStopServer stops the server
-func (s *ScheduleService) executeStopServer(server *models.Server, schedule models.Schedule) {
+func (s *ScheduleService) executeStopServer(server *models.Server, schedule models.Schedule) error {
 	// Check if server is running
 	if !IsServerRunning(server) {
 		log.Printf("⚠️  Schedule %d: Server %s is already offline, skipping stop", schedule.ID, server.Name)
-		return
+		return fmt.Errorf("%w: server already offline", errScheduleSkipped)
 	}
 
 	// Stop server
 	if err := StopServer(server); err != nil {
 		log.Printf("❌ Schedule %d: Failed to stop server %s: %v", schedule.ID, server.Name, err)
-		return
+		return err
 	}
 
 	log.Printf("✅ Schedule %d: Stopped server %s", schedule.ID, server.Name)
+
+	return nil
+}
+
+// executeBackupWithRetry runs executeBackup, retrying failures with a doubling delay as
+// configured. Each failed attempt before the last is recorded as a retrying run; the final
+// outcome is recorded by the caller.
+func (s *ScheduleService) executeBackupWithRetry(server *models.Server, schedule models.Schedule, trigger string) error {
+	retries, delay := config.GetScheduleRetry()
+
+	for attempt := 0; ; attempt++ {
+		attemptStartedAt := time.Now()
+		err := s.executeBackup(server, schedule)
+		if err == nil || errors.Is(err, errScheduleSkipped) {
+			return err
+		}
+
+		if attempt >= retries {
+			log.Printf("❌ Schedule %d: Backup failed for %s after %d attempt(s): %v", schedule.ID, server.Name, attempt+1, err)
+			return err
+		}
+
+		log.Printf("⚠️  Schedule %d: Backup attempt %d of %d failed for %s: %v, retrying in %s", schedule.ID, attempt+1, retries+1, server.Name, err, delay)
+		recordScheduleRun(schedule, trigger, models.ScheduleRunRetrying, fmt.Sprintf("attempt %d of %d: %v", attempt+1, retries+1, err), attemptStartedAt)
+
+		time.Sleep(delay)
+		delay *= 2
+	}
 }
 
 // executeBackup creates a backup of the server
-func (s *ScheduleService) executeBackup(server *models.Server, schedule models.Schedule) {
+func (s *ScheduleService) executeBackup(server *models.Server, schedule models.Schedule) error {
 	// Check if backup path is configured
 	if server.BackupPath == "" {
 		log.Printf("⚠️  Schedule %d: Server %s has no backup path configured, skipping backup", schedule.ID, server.Name)
-		return
+		return fmt.Errorf("%w: no backup path configured", errScheduleSkipped)
 	}
 
-	// Rotate backups if needed
-	if err := RotateBackups(server.ID, server.MaxBackups); err != nil {
-		log.Printf("❌ Schedule %d: Failed to rotate backups for %s: %v", schedule.ID, server.Name, err)
-		return
+	backup, err := CreateServerBackup(server, server.MaxBackups)
+	if err != nil {
+		return err
 	}
 
-	// Generate backup filename
-	fileName := GenerateBackupFileName(server.Name)
+	log.Printf("✅ Schedule %d: Backup created for %s: %s", schedule.ID, server.Name, backup.FileName)
+
+	return nil
+}
+
+// LoadAllBackupPolicies loads all enabled backup policies from the database
+func (s *ScheduleService) LoadAllBackupPolicies() error {
+	policies, err := models.GetAllEnabledBackupPolicies()
+	if err != nil {
+		return fmt.Errorf("failed to get enabled backup policies: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, policy := range policies {
+		if err := s.addBackupPolicyInternal(policy); err != nil {
+			log.Printf("⚠️  Failed to add backup policy %d (%s): %v", policy.ID, policy.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// AddBackupPolicy adds a backup policy to the cron scheduler
+func (s *ScheduleService) AddBackupPolicy(policy models.BackupPolicy) error {
+	if !policy.Enabled {
+		return nil // Don't add disabled policies
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addBackupPolicyInternal(policy)
+}
+
+// addBackupPolicyInternal adds a backup policy without locking (internal use only)
+func (s *ScheduleService) addBackupPolicyInternal(policy models.BackupPolicy) error {
+	if _, exists := s.policies[policy.ID]; exists {
+		return fmt.Errorf("backup policy %d already exists in cron", policy.ID)
+	}
 
-	// Create backup
-	backupFilePath, fileSize, err := CreateTarGzBackup(server.FolderPath, server.BackupPath, fileName)
+	cronExpr := policy.GetCronExpression()
+	cronSchedule, err := ParseCronSpec(cronExpr)
 	if err != nil {
-		log.Printf("❌ Schedule %d: Failed to create backup for %s: %v", schedule.ID, server.Name, err)
+		return fmt.Errorf("invalid cron expression %q: %w", cronExpr, err)
+	}
+	entryID := s.cron.Schedule(cronSchedule, cron.FuncJob(func() {
+		s.executeBackupPolicy(policy)
+	}))
+
+	s.policies[policy.ID] = entryID
+
+	log.Printf("✅ Added backup policy to cron: %s (ID: %d, Tag: %s, Cron: %s)", policy.Name, policy.ID, policy.Tag, cronExpr)
+	return nil
+}
+
+// RemoveBackupPolicy removes a backup policy from the cron scheduler
+func (s *ScheduleService) RemoveBackupPolicy(policyID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entryID, exists := s.policies[policyID]
+	if !exists {
 		return
 	}
 
-	// Save backup record to database
-	if _, err := models.CreateBackup(server.ID, fileName, backupFilePath, fileSize); err != nil {
-		log.Printf("❌ Schedule %d: Failed to save backup record for %s: %v", schedule.ID, server.Name, err)
+	s.cron.Remove(entryID)
+	delete(s.policies, policyID)
+
+	log.Printf("✅ Removed backup policy from cron: ID %d", policyID)
+}
+
+// UpdateBackupPolicy re-registers a backup policy in the cron scheduler
+func (s *ScheduleService) UpdateBackupPolicy(policy models.BackupPolicy) error {
+	s.RemoveBackupPolicy(policy.ID)
+	return s.AddBackupPolicy(policy)
+}
+
+// executeBackupPolicy backs up every server currently carrying the policy's tag.
+// Servers are resolved at fire time, so tag changes take effect on the next run.
+func (s *ScheduleService) executeBackupPolicy(policy models.BackupPolicy) {
+	if schedulesPaused(policy.UserID) {
+		log.Printf("⏸️  Backup policy %d: Schedules are paused, skipping", policy.ID)
 		return
 	}
 
-	log.Printf("✅ Schedule %d: Backup created for %s: %s", schedule.ID, server.Name, fileName)
-}
\ No newline at end of file
+	servers, err := models.GetServersByTag(policy.UserID, policy.Tag)
+	if err != nil {
+		log.Printf("❌ Backup policy %d: Failed to resolve servers for tag %s: %v", policy.ID, policy.Tag, err)
+		return
+	}
+
+	log.Printf("⏰ Executing backup policy: %s (ID: %d, Tag: %s, Servers: %d)", policy.Name, policy.ID, policy.Tag, len(servers))
+
+	for i := range servers {
+		server := &servers[i]
+
+		if server.BackupPath == "" {
+			log.Printf("⚠️  Backup policy %d: Server %s has no backup path configured, skipping", policy.ID, server.Name)
+			continue
+		}
+
+		backup, err := CreateServerBackup(server, policy.KeepBackups)
+		if err != nil {
+			log.Printf("❌ Backup policy %d: Backup failed for %s: %v", policy.ID, server.Name, err)
+			continue
+		}
+
+		log.Printf("✅ Backup policy %d: Backup created for %s: %s", policy.ID, server.Name, backup.FileName)
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"seiapanel/config"
+	"seiapanel/models"
+)
+
+// StartAutoStartServers starts, in the background, every server flagged to start when the
+// panel starts. Servers are started one at a time with the configured delay between them so
+// their worlds don't all load at once. Servers whose owner has paused automation are left
+// stopped.
+func StartAutoStartServers() {
+	servers, err := models.GetAutoStartServers()
+	if err != nil {
+		log.Printf("❌ Failed to load auto-start servers: %v", err)
+		return
+	}
+	if len(servers) == 0 {
+		return
+	}
+
+	log.Printf("🚀 Auto-starting %d server(s)", len(servers))
+	go autoStartServers(servers, config.GetAutoStartDelay())
+}
+
+// autoStartServers starts each server in turn, waiting delay after every one that started
+func autoStartServers(servers []models.Server, delay time.Duration) {
+	started := 0
+	for i := range servers {
+		server := &servers[i]
+
+		if schedulesPaused(server.UserID) {
+			log.Printf("⏸️  Auto-start skipped for '%s': automation is paused", server.Name)
+			continue
+		}
+		if IsServerRunning(server) {
+			continue
+		}
+
+		if started > 0 && delay > 0 {
+			time.Sleep(delay)
+		}
+		if err := StartServer(server); err != nil {
+			log.Printf("❌ Auto-start failed for '%s': %v", server.Name, err)
+			continue
+		}
+		log.Printf("✅ Auto-started '%s'", server.Name)
+		started++
+	}
+}
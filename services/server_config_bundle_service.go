@@ -0,0 +1,135 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"seiapanel/config"
+	"seiapanel/models"
+)
+
+// serverConfigBundleVersion is bumped when the bundle layout changes incompatibly
+const serverConfigBundleVersion = 1
+
+// MaxServerConfigBundleSize bounds how large an imported bundle may be
+const MaxServerConfigBundleSize = 4 * 1024 * 1024
+
+// ServerConfigBundle is a server's panel configuration in a form another panel installation
+// can import. It carries no files, and paths are relative to the server folder.
+type ServerConfigBundle struct {
+	Version      int                `json:"version"`
+	PanelVersion string             `json:"panel_version"`
+	ExportedAt   time.Time          `json:"exported_at"`
+	Server       BundleServer       `json:"server"`
+	Schedules    []ManifestSchedule `json:"schedules"`
+}
+
+// BundleServer holds the settings of a server record, without its location, owner or secrets
+type BundleServer struct {
+	Name            string        `json:"name"`
+	StartupCommand  string        `json:"startup_command"`
+	WorkingDir      string        `json:"working_dir"`
+	ExtraArgs       string        `json:"extra_args"`
+	ReloadCommand   string        `json:"reload_command"`
+	FileRoot        string        `json:"file_root"`
+	FileMode        string        `json:"file_mode"`
+	DirMode         string        `json:"dir_mode"`
+	Tags            []string      `json:"tags"`
+	LogPattern      string        `json:"log_pattern"`
+	LogTimeLayout   string        `json:"log_time_layout"`
+	ConsoleAllowed  []string      `json:"console_allowed"`
+	ConsoleBlocked  []string      `json:"console_blocked"`
+	ProtectedPaths  []string      `json:"protected_paths"`
+	AlertCPUPercent float64       `json:"alert_cpu_percent"`
+	AlertMemPercent float64       `json:"alert_mem_percent"`
+	AlertDuration   int           `json:"alert_duration"`
+	AutoStartOnBoot bool          `json:"auto_start_on_boot"`
+	Backups         BundleBackups `json:"backups"`
+}
+
+// BundleBackups holds a server's backup settings. Path is relative to the server folder so
+// it follows the server to its new location; empty means the default backup folder.
+type BundleBackups struct {
+	Path             string `json:"path"`
+	MaxBackups       int    `json:"max_backups"`
+	WrapInFolder     bool   `json:"wrap_in_folder"`
+	AutoBackupOnStop bool   `json:"auto_backup_on_stop"`
+}
+
+// NewServerConfigBundle describes a server's current configuration for export
+func NewServerConfigBundle(server *models.Server) (*ServerConfigBundle, error) {
+	manifest, err := NewBackupManifest(server)
+	if err != nil {
+		return nil, err
+	}
+
+	backupPath := server.BackupPath
+	if backupPath != "" {
+		if rel, err := filepath.Rel(server.FolderPath, backupPath); err == nil {
+			backupPath = filepath.ToSlash(rel)
+		}
+	}
+
+	return &ServerConfigBundle{
+		Version:      serverConfigBundleVersion,
+		PanelVersion: config.Version,
+		ExportedAt:   time.Now(),
+		Server: BundleServer{
+			Name:            server.Name,
+			StartupCommand:  server.StartupCommand,
+			WorkingDir:      server.WorkingDir,
+			ExtraArgs:       server.ExtraArgs,
+			ReloadCommand:   server.ReloadCommand,
+			FileRoot:        server.FileRoot,
+			FileMode:        server.FileMode,
+			DirMode:         server.DirMode,
+			Tags:            server.GetTags(),
+			LogPattern:      server.LogPattern,
+			LogTimeLayout:   server.LogTimeLayout,
+			ConsoleAllowed:  server.GetConsoleAllowed(),
+			ConsoleBlocked:  server.GetConsoleBlocked(),
+			ProtectedPaths:  server.GetProtectedPaths(),
+			AlertCPUPercent: server.AlertCPUPercent,
+			AlertMemPercent: server.AlertMemPercent,
+			AlertDuration:   server.AlertDuration,
+			AutoStartOnBoot: server.AutoStartOnBoot,
+			Backups: BundleBackups{
+				Path:             backupPath,
+				MaxBackups:       server.MaxBackups,
+				WrapInFolder:     server.WrapBackups,
+				AutoBackupOnStop: server.AutoBackupOnStop,
+			},
+		},
+		Schedules: manifest.Schedules,
+	}, nil
+}
+
+// ParseServerConfigBundle decodes an exported bundle, rejecting ones this panel can't read
+func ParseServerConfigBundle(data []byte) (*ServerConfigBundle, error) {
+	var bundle ServerConfigBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid configuration bundle: %w", err)
+	}
+	if bundle.Version == 0 {
+		return nil, errors.New("not a server configuration bundle")
+	}
+	if bundle.Version > serverConfigBundleVersion {
+		return nil, fmt.Errorf("configuration bundle version %d is newer than this panel supports", bundle.Version)
+	}
+	if bundle.Server.StartupCommand == "" {
+		return nil, errors.New("configuration bundle has no startup command")
+	}
+	return &bundle, nil
+}
+
+// BundleBackupPath resolves a bundle's backup path against the server's new folder
+func BundleBackupPath(bundle *ServerConfigBundle, folderPath string) string {
+	backupPath := bundle.Server.Backups.Path
+	if backupPath == "" || filepath.IsAbs(backupPath) {
+		return backupPath
+	}
+	return filepath.Join(folderPath, filepath.FromSlash(backupPath))
+}
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"seiapanel/config"
 	"seiapanel/models"
 
 	"github.com/gorilla/websocket"
@@ -29,23 +30,97 @@ type ServerProcess struct {
 	LogMux  sync.Mutex
 	Clients []*websocket.Conn
 	ClientMux sync.Mutex
+
+	logBytes        int       // Total size of Logs, guarded by LogMux
+	broadcastTokens float64   // Broadcast rate limit bucket, guarded by ClientMux
+	lastRefill      time.Time // Last time broadcastTokens was refilled
+	droppedLines    int       // Lines not broadcast since the rate limit kicked in
 }
 
+// maxLogLines caps the number of console lines kept in memory per server
+const maxLogLines = 1000
+
+// truncatedLineMarker is appended to console lines cut at the line length limit
+const truncatedLineMarker = " … [line truncated]"
+
 // ServerStats holds server statistics
 type ServerStats struct {
-	MemoryMB float64 `json:"memory_mb"`
-	MemoryGB float64 `json:"memory_gb"`
-	PID      int     `json:"pid"`
-	IsRunning bool   `json:"is_running"`
+	MemoryMB   float64     `json:"memory_mb"`
+	MemoryGB   float64     `json:"memory_gb"`
+	PID        int         `json:"pid"`
+	IsRunning  bool        `json:"is_running"`
+	CPUPercent float64     `json:"cpu_percent"`
+	State      string      `json:"state"` // online, offline, starting, stopping
+	Alert      *AlertState `json:"alert,omitempty"`
 }
 
+// Transitional server states, held while a start or stop is in progress
+const (
+	ServerStateStarting = "starting"
+	ServerStateStopping = "stopping"
+)
+
 var (
 	runningServers = make(map[uint]*ServerProcess)
 	serverMux      sync.Mutex
+
+	serverTransitions = make(map[uint]string)
+	transitionMux     sync.Mutex
 )
 
+// beginTransition marks a server as starting or stopping, failing if another
+// start or stop of the same server is already in progress
+func beginTransition(serverID uint, state string) error {
+	transitionMux.Lock()
+	defer transitionMux.Unlock()
+
+	if current, exists := serverTransitions[serverID]; exists {
+		return fmt.Errorf("server is %s", current)
+	}
+	serverTransitions[serverID] = state
+	return nil
+}
+
+// setTransition changes the state of an in-progress transition
+func setTransition(serverID uint, state string) {
+	transitionMux.Lock()
+	serverTransitions[serverID] = state
+	transitionMux.Unlock()
+}
+
+// endTransition clears a server's transitional state
+func endTransition(serverID uint) {
+	transitionMux.Lock()
+	delete(serverTransitions, serverID)
+	transitionMux.Unlock()
+}
+
+// GetServerTransition returns the server's in-progress transition (starting, stopping), or "" if none
+func GetServerTransition(server *models.Server) string {
+	transitionMux.Lock()
+	defer transitionMux.Unlock()
+
+	return serverTransitions[server.ID]
+}
+
 // StartServer starts a Minecraft server
 func StartServer(server *models.Server) error {
+	if err := beginTransition(server.ID, ServerStateStarting); err != nil {
+		return err
+	}
+	defer endTransition(server.ID)
+
+	// A backup or restore still working on the folder, such as the backup taken on stop,
+	// must finish before the server writes to it again
+	if operation := GetServerOperation(server.ID); operation != "" {
+		return fmt.Errorf("%w: a %s of this server is still running", ErrOperationInProgress, operation)
+	}
+
+	return startServer(server)
+}
+
+// startServer launches the server process; the caller holds the transition
+func startServer(server *models.Server) error {
 	serverMux.Lock()
 	defer serverMux.Unlock()
 
@@ -54,15 +129,15 @@ func StartServer(server *models.Server) error {
 		return errors.New("server is already running")
 	}
 
-	// Parse startup command
-	parts := strings.Fields(server.StartupCommand)
+	// Parse startup command and extra launch arguments
+	parts := server.LaunchArgs()
 	if len(parts) == 0 {
 		return errors.New("invalid startup command")
 	}
 
 	// Create command
 	cmd := exec.Command(parts[0], parts[1:]...)
-	cmd.Dir = server.FolderPath
+	cmd.Dir = server.LaunchDir()
 
 	// Get stdin, stdout, stderr pipes
 	stdin, err := cmd.StdinPipe()
@@ -114,10 +189,63 @@ func StartServer(server *models.Server) error {
 
 // StopServer stops a running Minecraft server
 func StopServer(server *models.Server) error {
-	serverMux.Lock()
-	defer serverMux.Unlock()
+	if err := beginTransition(server.ID, ServerStateStopping); err != nil {
+		return err
+	}
+	defer endTransition(server.ID)
+
+	if err := stopServer(server); err != nil {
+		return err
+	}
+
+	if server.AutoBackupOnStop {
+		// Claimed before the transition ends, so no start or restore can slip in
+		// while the folder is being archived
+		if err := BeginServerOperation(server.ID, "backup"); err != nil {
+			log.Printf("⚠️  Skipping backup on stop for '%s': %v", server.Name, err)
+			return nil
+		}
+		go func() {
+			defer EndServerOperation(server.ID)
+			backupAfterStop(server)
+		}()
+	}
+	return nil
+}
+
+// autoBackupMinInterval is how recent a backup must be for the stop backup to be skipped
+const autoBackupMinInterval = 10 * time.Minute
+
+// backupAfterStop takes a safety backup of a server that was just stopped, unless one
+// was taken very recently or no backup path is configured. The caller holds the server's
+// backup operation.
+func backupAfterStop(server *models.Server) {
+	if server.BackupPath == "" {
+		log.Printf("⚠️  Skipping backup on stop for '%s': no backup path configured", server.Name)
+		return
+	}
+
+	if latest, err := models.GetLatestBackup(server.ID); err == nil && time.Since(latest.CreatedAt) < autoBackupMinInterval {
+		log.Printf("⚠️  Skipping backup on stop for '%s': last backup was taken %s ago", server.Name, time.Since(latest.CreatedAt).Round(time.Second))
+		return
+	}
+
+	backup, err := createServerBackup(server, server.MaxBackups)
+	if err != nil {
+		log.Printf("❌ Backup on stop failed for '%s': %v", server.Name, err)
+		return
+	}
+	log.Printf("✅ Backup on stop created for '%s': %s (%s)", server.Name, backup.FileName, FormatFileSize(backup.FileSize))
+}
 
+// stopServer shuts the server process down; the caller holds the transition.
+// The registry lock isn't held while waiting, so status queries stay responsive
+// and report the server as stopping.
+func stopServer(server *models.Server) error {
+	serverMux.Lock()
 	sp, exists := runningServers[server.ID]
+	serverMux.Unlock()
+
 	if !exists {
 		return errors.New("server is not running")
 	}
@@ -148,8 +276,12 @@ func StopServer(server *models.Server) error {
 		}
 	}
 
-	// Clean up
-	delete(runningServers, server.ID)
+	// Clean up (the process monitor may have done so already)
+	serverMux.Lock()
+	if runningServers[server.ID] == sp {
+		delete(runningServers, server.ID)
+	}
+	serverMux.Unlock()
 	server.SetStatus("offline")
 
 	// Close all WebSocket connections
@@ -166,20 +298,29 @@ func StopServer(server *models.Server) error {
 
 // RestartServer restarts a Minecraft server
 func RestartServer(server *models.Server) error {
+	// Hold the transition across stop, pause and start so nothing slips in between
+	if err := beginTransition(server.ID, ServerStateStopping); err != nil {
+		return err
+	}
+	defer endTransition(server.ID)
+
 	// Stop the server
-	if err := StopServer(server); err != nil {
+	if err := stopServer(server); err != nil {
 		// If server is not running, just start it
 		if err.Error() == "server is not running" {
-			return StartServer(server)
+			setTransition(server.ID, ServerStateStarting)
+			return startServer(server)
 		}
 		return err
 	}
 
+	setTransition(server.ID, ServerStateStarting)
+
 	// Wait a moment before restarting
 	time.Sleep(2 * time.Second)
 
 	// Start the server
-	return StartServer(server)
+	return startServer(server)
 }
 
 // SendCommand sends a command to the server console
@@ -205,6 +346,21 @@ func SendCommand(server *models.Server, command string) error {
 	return nil
 }
 
+// ErrNoReloadCommand is returned when reloading a server that has no reload command configured
+var ErrNoReloadCommand = errors.New("no reload command is configured for this server")
+
+// ReloadServer sends the server's configured reload command, reloading it in place
+// without restarting the process
+func ReloadServer(server *models.Server) error {
+	if server.ReloadCommand == "" {
+		return ErrNoReloadCommand
+	}
+	if !IsServerRunning(server) {
+		return errors.New("server is not running")
+	}
+	return SendCommand(server, server.ReloadCommand)
+}
+
 // GetLogs returns the server logs
 func GetLogs(server *models.Server) []string {
 	serverMux.Lock()
@@ -230,15 +386,25 @@ func GetServerStats(server *models.Server) (*ServerStats, error) {
 	sp, exists := runningServers[server.ID]
 	serverMux.Unlock()
 
+	state := GetServerTransition(server)
+
 	if !exists {
+		if state == "" {
+			state = "offline"
+		}
 		return &ServerStats{
 			MemoryMB:  0,
 			MemoryGB:  0,
 			PID:       0,
 			IsRunning: false,
+			State:     state,
 		}, nil
 	}
 
+	if state == "" {
+		state = "online"
+	}
+
 	pid := sp.Cmd.Process.Pid
 	memoryKB, err := getProcessMemory(pid)
 	if err != nil {
@@ -248,18 +414,28 @@ func GetServerStats(server *models.Server) (*ServerStats, error) {
 			MemoryGB:  0,
 			PID:       pid,
 			IsRunning: true,
+			State:     state,
 		}, nil
 	}
 
 	memoryMB := float64(memoryKB) / 1024.0
 	memoryGB := memoryMB / 1024.0
 
-	return &ServerStats{
+	stats := &ServerStats{
 		MemoryMB:  memoryMB,
 		MemoryGB:  memoryGB,
 		PID:       pid,
 		IsRunning: true,
-	}, nil
+		State:     state,
+	}
+
+	// CPU usage and alert state come from the resource monitor's latest sample
+	if alert := GetAlertState(server.ID); alert != nil {
+		stats.CPUPercent = alert.CPUPercent
+		stats.Alert = alert
+	}
+
+	return stats, nil
 }
 
 // getProcessMemory reads memory usage from /proc/[pid]/status
@@ -313,28 +489,11 @@ func AddConsoleListener(server *models.Server, conn *websocket.Conn) {
 
 	// Send existing logs to new client
 	sp.LogMux.Lock()
+	conn.SetWriteDeadline(time.Now().Add(WSWriteWait))
 	for _, logLine := range sp.Logs {
 		conn.WriteMessage(websocket.TextMessage, []byte(logLine))
 	}
 	sp.LogMux.Unlock()
-
-	// Set up ping/pong handlers for keepalive
-	conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-		return nil
-	})
-
-	// Start ping ticker
-	ticker := time.NewTicker(30 * time.Second)
-	go func() {
-		defer ticker.Stop()
-		for range ticker.C {
-			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return
-			}
-		}
-	}()
 }
 
 // RemoveConsoleListener removes a WebSocket client
@@ -359,45 +518,127 @@ func RemoveConsoleListener(server *models.Server, conn *websocket.Conn) {
 	}
 }
 
-// readOutput reads from stdout/stderr and broadcasts to clients
+// readOutput reads from stdout/stderr and broadcasts to clients.
+// Lines are capped in length, the retained history is capped in bytes and the
+// broadcast rate is limited, so a runaway process can't exhaust memory or flood clients.
 func (sp *ServerProcess) readOutput(reader io.ReadCloser, isError bool) {
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		line := scanner.Text()
-		
+	maxLine, maxBuffer, maxRate := config.GetConsoleLimits()
+
+	br := bufio.NewReader(reader)
+	for {
+		line, err := readCappedLine(br, maxLine)
+		if err != nil && line == "" {
+			if err != io.EOF {
+				log.Printf("⚠️  Error reading output from server '%s': %v", sp.Server.Name, err)
+			}
+			return
+		}
+
 		// Strip ANSI color codes
 		line = stripAnsiCodes(line)
 
-		// Add to logs
-		sp.LogMux.Lock()
-		sp.Logs = append(sp.Logs, line)
-		// Keep only last 1000 lines
-		if len(sp.Logs) > 1000 {
-			sp.Logs = sp.Logs[len(sp.Logs)-1000:]
+		sp.appendLog(line, maxBuffer)
+		sp.broadcast(line, maxRate)
+	}
+}
+
+// readCappedLine reads one line without its line ending. Lines longer than maxLine bytes
+// are cut and marked, and the rest of the line is discarded without being buffered.
+func readCappedLine(br *bufio.Reader, maxLine int) (string, error) {
+	var buf []byte
+	truncated := false
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if !truncated {
+			if room := maxLine - len(buf); len(chunk) > room {
+				buf = append(buf, chunk[:room]...)
+				truncated = true
+			} else {
+				buf = append(buf, chunk...)
+			}
 		}
-		sp.LogMux.Unlock()
-
-		// Broadcast to WebSocket clients
-		sp.ClientMux.Lock()
-		disconnectedClients := []int{}
-		for i, client := range sp.Clients {
-			err := client.WriteMessage(websocket.TextMessage, []byte(line))
-			if err != nil {
-				// Mark client for removal
-				disconnectedClients = append(disconnectedClients, i)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+
+		line := strings.TrimRight(string(buf), "\r\n")
+		if truncated {
+			line = strings.ToValidUTF8(line, "") + truncatedLineMarker
+		}
+		return line, err
+	}
+}
+
+// appendLog adds a line to the console history, dropping the oldest lines once
+// the line count or the total size goes over its limit
+func (sp *ServerProcess) appendLog(line string, maxBuffer int) {
+	sp.LogMux.Lock()
+	defer sp.LogMux.Unlock()
+
+	sp.Logs = append(sp.Logs, line)
+	sp.logBytes += len(line)
+
+	drop := 0
+	for drop < len(sp.Logs)-1 && (len(sp.Logs)-drop > maxLogLines || sp.logBytes > maxBuffer) {
+		sp.logBytes -= len(sp.Logs[drop])
+		drop++
+	}
+	if drop > 0 {
+		sp.Logs = append([]string(nil), sp.Logs[drop:]...)
+	}
+}
+
+// broadcast sends a line to all WebSocket clients, allowing at most maxRate lines per
+// second (0 = unlimited). Lines over the rate stay in the history but aren't sent, and
+// clients are told how many were skipped once output slows down again.
+func (sp *ServerProcess) broadcast(line string, maxRate int) {
+	sp.ClientMux.Lock()
+	defer sp.ClientMux.Unlock()
+
+	if maxRate > 0 {
+		now := time.Now()
+		if sp.lastRefill.IsZero() {
+			sp.broadcastTokens = float64(maxRate)
+		} else {
+			sp.broadcastTokens += now.Sub(sp.lastRefill).Seconds() * float64(maxRate)
+			if sp.broadcastTokens > float64(maxRate) {
+				sp.broadcastTokens = float64(maxRate)
 			}
 		}
-		
-		// Remove disconnected clients
-		for i := len(disconnectedClients) - 1; i >= 0; i-- {
-			idx := disconnectedClients[i]
-			sp.Clients = append(sp.Clients[:idx], sp.Clients[idx+1:]...)
+		sp.lastRefill = now
+
+		if sp.broadcastTokens < 1 {
+			sp.droppedLines++
+			return
 		}
-		sp.ClientMux.Unlock()
+		sp.broadcastTokens--
 	}
-	
-	if err := scanner.Err(); err != nil {
-		log.Printf("⚠️  Error reading output from server '%s': %v", sp.Server.Name, err)
+
+	messages := []string{line}
+	if sp.droppedLines > 0 {
+		notice := fmt.Sprintf("[%d lines not shown, output rate limited to %d lines/s]", sp.droppedLines, maxRate)
+		messages = []string{notice, line}
+		sp.droppedLines = 0
+	}
+
+	disconnectedClients := []int{}
+	for i, client := range sp.Clients {
+		// A dead client must not stall output for everyone else
+		client.SetWriteDeadline(time.Now().Add(WSWriteWait))
+		for _, message := range messages {
+			if err := client.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+				// Mark client for removal and close it so its handler stops reading
+				disconnectedClients = append(disconnectedClients, i)
+				client.Close()
+				break
+			}
+		}
+	}
+
+	// Remove disconnected clients
+	for i := len(disconnectedClients) - 1; i >= 0; i-- {
+		idx := disconnectedClients[i]
+		sp.Clients = append(sp.Clients[:idx], sp.Clients[idx+1:]...)
 	}
 }
 
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"seiapanel/config"
+	"seiapanel/models"
+)
+
+// PartialSuffix marks a file the panel is still writing. Writers create the file under
+// PartialPath and rename it into place once complete, so anything still carrying the
+// suffix after a while was left behind by an interrupted operation.
+const PartialSuffix = ".seiapanel-partial"
+
+// tempCleanupMaxEntries bounds how many entries one cleanup walk of a directory visits
+const tempCleanupMaxEntries = 500000
+
+// PartialPath returns the temporary name a file is written under before it is complete
+func PartialPath(path string) string {
+	return path + PartialSuffix
+}
+
+// CleanupTempArtifacts removes partial files older than the configured age from server
+// folders, backup folders and the thumbnail cache, plus expired backup mount directories
+// from the system temp location
+func CleanupTempArtifacts() {
+	maxAge := config.GetTempMaxAge()
+	cutoff := time.Now().Add(-maxAge)
+
+	roots := []string{thumbnailCacheDir}
+	if servers, err := models.GetAllServers(); err != nil {
+		log.Printf("⚠️  Temp cleanup: failed to load servers: %v", err)
+	} else {
+		for _, server := range servers {
+			roots = append(roots, server.FolderPath)
+			if server.BackupPath != "" {
+				roots = append(roots, server.BackupPath)
+			}
+		}
+	}
+
+	removed := 0
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+		removed += removePartialFiles(root, cutoff)
+	}
+	removed += removeExpiredMountDirs(cutoff)
+
+	if removed > 0 {
+		log.Printf("🧹 Removed %d leftover temporary file(s) older than %s", removed, maxAge)
+	}
+}
+
+// removePartialFiles deletes partial files under root last modified before cutoff
+func removePartialFiles(root string, cutoff time.Time) int {
+	removed := 0
+	visited := 0
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable entries are skipped, a missing root just means nothing to clean
+			return nil
+		}
+		visited++
+		if visited > tempCleanupMaxEntries {
+			return filepath.SkipAll
+		}
+		if !d.Type().IsRegular() || !strings.HasSuffix(d.Name(), PartialSuffix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			log.Printf("⚠️  Temp cleanup: failed to remove %s: %v", path, err)
+			return nil
+		}
+		removed++
+		return nil
+	})
+	return removed
+}
+
+// removeExpiredMountDirs deletes backup mount directories older than cutoff that no
+// active mount is using
+func removeExpiredMountDirs(cutoff time.Time) int {
+	matches, err := filepath.Glob(filepath.Join(os.TempDir(), backupMountPrefix+"*"))
+	if err != nil {
+		return 0
+	}
+
+	backupMountMux.Lock()
+	active := make(map[string]bool, len(backupMounts))
+	for _, mount := range backupMounts {
+		active[mount.dir] = true
+	}
+	backupMountMux.Unlock()
+
+	removed := 0
+	for _, dir := range matches {
+		info, err := os.Stat(dir)
+		if err != nil || active[dir] || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("⚠️  Temp cleanup: failed to remove %s: %v", dir, err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
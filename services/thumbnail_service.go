@@ -0,0 +1,251 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ThumbnailMaxSize is the maximum width/height of a generated thumbnail in pixels
+const ThumbnailMaxSize = 256
+
+// thumbnailCacheDir is where generated thumbnails are stored
+const thumbnailCacheDir = "./cache/thumbnails"
+
+// ThumbnailMaxPixels bounds the dimensions of an image a thumbnail is made of, since decoding
+// needs memory for every pixel however small the file is
+const ThumbnailMaxPixels = 40 * 1000 * 1000
+
+// thumbnailConcurrency is how many thumbnails are generated at once
+const thumbnailConcurrency = 2
+
+// ErrNotAnImage is returned when a thumbnail is requested for a non-image file
+var ErrNotAnImage = errors.New("file is not a supported image")
+
+// ErrImageTooLarge is returned when an image has more pixels than thumbnails are made of
+var ErrImageTooLarge = errors.New("image is too large for a thumbnail")
+
+var (
+	// thumbnailLocks holds a lock per cache entry, so concurrent requests for the same image
+	// generate it once while other images aren't held up
+	thumbnailLocks = make(map[string]*thumbnailLock)
+	thumbnailMux   sync.Mutex
+
+	// thumbnailSlots bounds how many images are decoded at once
+	thumbnailSlots = make(chan struct{}, thumbnailConcurrency)
+)
+
+// thumbnailLock serializes the generation of one cache entry
+type thumbnailLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lockThumbnail locks the cache entry at thumbPath and returns the function unlocking it
+func lockThumbnail(thumbPath string) func() {
+	thumbnailMux.Lock()
+	lock, ok := thumbnailLocks[thumbPath]
+	if !ok {
+		lock = &thumbnailLock{}
+		thumbnailLocks[thumbPath] = lock
+	}
+	lock.refs++
+	thumbnailMux.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		thumbnailMux.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(thumbnailLocks, thumbPath)
+		}
+		thumbnailMux.Unlock()
+	}
+}
+
+// IsThumbnailable reports whether a file name has a supported image extension
+func IsThumbnailable(fileName string) bool {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+		return true
+	}
+	return false
+}
+
+// GetThumbnail returns the path and content type of a cached thumbnail for an image,
+// generating it on first request. The cache key is derived from the path and modtime,
+// so editing the image produces a fresh thumbnail.
+func GetThumbnail(imagePath string) (string, string, error) {
+	if !IsThumbnailable(imagePath) {
+		return "", "", ErrNotAnImage
+	}
+
+	info, err := os.Stat(imagePath)
+	if err != nil {
+		return "", "", err
+	}
+	if info.IsDir() {
+		return "", "", ErrNotAnImage
+	}
+
+	// PNG and GIF may carry transparency, so keep those as PNG
+	ext := ".jpg"
+	contentType := "image/jpeg"
+	if lower := strings.ToLower(filepath.Ext(imagePath)); lower == ".png" || lower == ".gif" {
+		ext = ".png"
+		contentType = "image/png"
+	}
+
+	key := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d", imagePath, info.ModTime().UnixNano(), info.Size())))
+	thumbPath := filepath.Join(thumbnailCacheDir, hex.EncodeToString(key[:])+ext)
+
+	unlock := lockThumbnail(thumbPath)
+	defer unlock()
+
+	// Serve from cache when available
+	if _, err := os.Stat(thumbPath); err == nil {
+		return thumbPath, contentType, nil
+	}
+
+	thumbnailSlots <- struct{}{}
+	err = generateThumbnail(imagePath, thumbPath, ext)
+	<-thumbnailSlots
+	if err != nil {
+		return "", "", err
+	}
+
+	return thumbPath, contentType, nil
+}
+
+// generateThumbnail decodes an image, scales it down and writes it to thumbPath. The image
+// header is read first, so images with more than ThumbnailMaxPixels are refused before
+// any pixel memory is allocated.
+func generateThumbnail(imagePath, thumbPath, ext string) error {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decodeConfig, decode := jpeg.DecodeConfig, jpeg.Decode
+	switch strings.ToLower(filepath.Ext(imagePath)) {
+	case ".png":
+		decodeConfig, decode = png.DecodeConfig, png.Decode
+	case ".gif":
+		decodeConfig, decode = gif.DecodeConfig, gif.Decode
+	}
+
+	cfg, err := decodeConfig(file)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > ThumbnailMaxPixels {
+		return fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	src, err := decode(file)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	thumb := scaleImage(src, ThumbnailMaxSize)
+
+	if err := os.MkdirAll(thumbnailCacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create thumbnail cache: %w", err)
+	}
+
+	// Write to a temp file first so a failed encode never leaves a broken cache entry
+	tmpPath := PartialPath(thumbPath)
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create thumbnail: %w", err)
+	}
+
+	if ext == ".png" {
+		err = png.Encode(out, thumb)
+	} else {
+		err = jpeg.Encode(out, thumb, &jpeg.Options{Quality: 80})
+	}
+	out.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+
+	return os.Rename(tmpPath, thumbPath)
+}
+
+// scaleImage scales an image down so neither side exceeds maxSize, averaging
+// the source pixels covered by each destination pixel
+func scaleImage(src image.Image, maxSize int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= maxSize && srcH <= maxSize {
+		return src
+	}
+
+	dstW, dstH := maxSize, maxSize
+	if srcW > srcH {
+		dstH = srcH * maxSize / srcW
+	} else {
+		dstW = srcW * maxSize / srcH
+	}
+	if dstW < 1 {
+		dstW = 1
+	}
+	if dstH < 1 {
+		dstH = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := bounds.Min.Y + (y+1)*srcH/dstH
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := bounds.Min.X + (x+1)*srcW/dstW
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					count++
+				}
+			}
+			if count == 0 {
+				continue
+			}
+
+			// Averaged values are premultiplied; convert back to straight alpha
+			i := dst.PixOffset(x, y)
+			alpha := a / count
+			if alpha > 0 {
+				dst.Pix[i+0] = uint8((r / count) * 0xffff / alpha >> 8)
+				dst.Pix[i+1] = uint8((g / count) * 0xffff / alpha >> 8)
+				dst.Pix[i+2] = uint8((b / count) * 0xffff / alpha >> 8)
+			}
+			dst.Pix[i+3] = uint8(alpha >> 8)
+		}
+	}
+
+	return dst
+}
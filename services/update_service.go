@@ -0,0 +1,116 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"seiapanel/config"
+)
+
+// updateCheckInterval is how long a release check result is reused
+const updateCheckInterval = 1 * time.Hour
+
+// UpdateInfo describes the latest available release
+type UpdateInfo struct {
+	LatestVersion   string    `json:"latest_version"`
+	UpdateAvailable bool      `json:"update_available"`
+	ReleaseURL      string    `json:"release_url"`
+	CheckedAt       time.Time `json:"checked_at"`
+}
+
+var (
+	cachedUpdate *UpdateInfo
+	updateMux    sync.Mutex
+)
+
+// CheckForUpdate compares the running version against the latest release.
+// Results are cached so dashboards polling this don't hammer the release URL.
+func CheckForUpdate() (*UpdateInfo, error) {
+	releaseURL := config.GetReleaseURL()
+	if releaseURL == "" {
+		return nil, fmt.Errorf("update checks are disabled")
+	}
+
+	updateMux.Lock()
+	defer updateMux.Unlock()
+
+	if cachedUpdate != nil && time.Since(cachedUpdate.CheckedAt) < updateCheckInterval {
+		return cachedUpdate, nil
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequest("GET", releaseURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release URL: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "SeiaPanel/"+config.Version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to contact release URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release URL returned status %d", resp.StatusCode)
+	}
+
+	// GitHub-style latest release response
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release info: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("release info has no version tag")
+	}
+
+	cachedUpdate = &UpdateInfo{
+		LatestVersion:   release.TagName,
+		UpdateAvailable: IsNewerVersion(release.TagName, config.Version),
+		ReleaseURL:      release.HTMLURL,
+		CheckedAt:       time.Now(),
+	}
+	return cachedUpdate, nil
+}
+
+// IsNewerVersion reports whether candidate is a newer semantic version than current.
+// Development builds never report updates.
+func IsNewerVersion(candidate, current string) bool {
+	if current == "dev" {
+		return false
+	}
+
+	a := parseVersion(candidate)
+	b := parseVersion(current)
+	for i := 0; i < 3; i++ {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return false
+}
+
+// parseVersion parses "v1.2.3" (pre-release/build suffixes ignored) into its numeric parts
+func parseVersion(version string) [3]int {
+	var parts [3]int
+
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	for i, p := range strings.SplitN(version, ".", 3) {
+		n, _ := strconv.Atoi(p)
+		parts[i] = n
+	}
+	return parts
+}
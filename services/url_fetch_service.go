@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"seiapanel/config"
+)
+
+// URLFetchTimeout bounds a whole fetch, including the body transfer
+const URLFetchTimeout = 5 * time.Minute
+
+// urlFetchMaxRedirects bounds how many redirects a fetch follows
+const urlFetchMaxRedirects = 5
+
+// ErrFetchTooLarge is returned when a fetched file exceeds the size limit
+var ErrFetchTooLarge = errors.New("remote file exceeds the size limit")
+
+// ValidateFetchURL checks a URL against the allowed schemes and the configured host lists.
+// It reports whether the host is explicitly allow-listed, which permits private addresses.
+func ValidateFetchURL(u *url.URL) (bool, error) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false, fmt.Errorf("only http and https URLs are allowed")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return false, fmt.Errorf("URL has no host")
+	}
+
+	allow, deny := config.GetURLFetchHosts()
+	if hostInList(host, deny) {
+		return false, fmt.Errorf("host %s is not allowed", host)
+	}
+	if len(allow) == 0 {
+		return false, nil
+	}
+	if !hostInList(host, allow) {
+		return false, fmt.Errorf("host %s is not in the allowed hosts", host)
+	}
+	return true, nil
+}
+
+// hostInList reports whether host equals an entry or is a subdomain of one
+func hostInList(host string, list []string) bool {
+	for _, entry := range list {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry != "" && (host == entry || strings.HasSuffix(host, "."+entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrivateIP reports whether an IP is loopback, private, link-local or unspecified
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// newFetchClient builds an HTTP client that re-validates every redirect and, unless private
+// addresses are allowed, refuses to connect to them. The check runs on the resolved address
+// at dial time, so DNS tricks can't sneak a request onto the local network.
+func newFetchClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			if allowPrivate {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+				return fmt.Errorf("connections to private address %s are not allowed", host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= urlFetchMaxRedirects {
+				return fmt.Errorf("too many redirects")
+			}
+			_, err := ValidateFetchURL(req.URL)
+			return err
+		},
+	}
+}
+
+// FetchURLToFile downloads a URL into destPath, failing with ErrFetchTooLarge past maxSize bytes.
+// The file is written under a temporary name and only renamed into place once complete.
+func FetchURLToFile(ctx context.Context, rawURL, destPath string, maxSize int64) (int64, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid URL: %w", err)
+	}
+	allowPrivate, err := ValidateFetchURL(u)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, URLFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "SeiaPanel/"+config.Version)
+
+	resp, err := newFetchClient(allowPrivate).Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, operationContextError(ctx)
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("remote server returned %s", resp.Status)
+	}
+	if resp.ContentLength > maxSize {
+		return 0, ErrFetchTooLarge
+	}
+
+	// Writes to a stuck mount can't be interrupted, so don't let them hold the request past
+	// the deadline; the canceled body read ends the copy once the write returns
+	var written int64
+	err = RunWithContext(ctx, func() error {
+		var err error
+		written, err = writeFetchedFile(resp.Body, destPath, maxSize)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return written, nil
+}
+
+// writeFetchedFile copies a fetched body into destPath through a temporary file
+func writeFetchedFile(body io.Reader, destPath string, maxSize int64) (int64, error) {
+	tmpPath := PartialPath(destPath)
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, err
+	}
+
+	// Read one byte past the limit to tell "exactly maxSize" from "too large"
+	written, err := io.Copy(out, io.LimitReader(body, maxSize+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written > maxSize {
+		err = ErrFetchTooLarge
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+
+	return written, nil
+}
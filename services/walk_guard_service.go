@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// MaxWalkDepth is how many directory levels below its root a recursive file operation may descend
+const MaxWalkDepth = 128
+
+// Errors returned when a recursive file operation meets a pathological tree
+var (
+	ErrWalkTooDeep = errors.New("directory tree is too deep")
+	ErrWalkCycle   = errors.New("directory tree contains a loop")
+)
+
+// dirID identifies a directory by device and inode, which stays the same however it is reached
+type dirID struct {
+	dev uint64
+	ino uint64
+}
+
+// WalkGuard stops recursive file operations from descending forever, either through very deep
+// trees or through loops created by bind mounts or followed symlinks. Use one guard per walk.
+type WalkGuard struct {
+	root    string
+	visited map[dirID]bool
+}
+
+// NewWalkGuard returns a guard for a walk starting at root
+func NewWalkGuard(root string) *WalkGuard {
+	return &WalkGuard{
+		root:    filepath.Clean(root),
+		visited: make(map[dirID]bool),
+	}
+}
+
+// EnterDir must be called for each directory before its contents are read. It fails when the
+// directory is nested too deep below the root or was already entered during this walk.
+func (g *WalkGuard) EnterDir(path string, info os.FileInfo) error {
+	if rel, err := filepath.Rel(g.root, path); err == nil && rel != "." {
+		if depth := strings.Count(rel, string(filepath.Separator)) + 1; depth > MaxWalkDepth {
+			return fmt.Errorf("%w (more than %d levels below %s)", ErrWalkTooDeep, MaxWalkDepth, filepath.Base(g.root))
+		}
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	id := dirID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}
+	if g.visited[id] {
+		return fmt.Errorf("%w: %s was already visited", ErrWalkCycle, path)
+	}
+	g.visited[id] = true
+	return nil
+}
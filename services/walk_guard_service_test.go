@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// walkFollowingLinks walks root like a walk that follows symlinks to folders would, stopping
+// when guard reports a problem
+func walkFollowingLinks(path string, guard *WalkGuard) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	if err := guard.EnterDir(path, info); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := walkFollowingLinks(filepath.Join(path, entry.Name()), guard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestWalkGuardStopsSymlinkLoop checks that a walk through a symlink pointing back up the
+// tree ends with ErrWalkCycle instead of running forever
+func TestWalkGuardStopsSymlinkLoop(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "world", "region"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(root, filepath.Join(root, "world", "region", "loop")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- walkFollowingLinks(root, NewWalkGuard(root)) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrWalkCycle) {
+			t.Errorf("walk error = %v, want ErrWalkCycle", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("walk through a symlink loop did not finish")
+	}
+}
+
+// TestWalkGuardStopsDeepTree checks that a walk below MaxWalkDepth levels ends with ErrWalkTooDeep
+func TestWalkGuardStopsDeepTree(t *testing.T) {
+	root := t.TempDir()
+	deepest := filepath.Join(root, strings.Repeat("d/", MaxWalkDepth+1))
+	if err := os.MkdirAll(deepest, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := walkFollowingLinks(root, NewWalkGuard(root)); !errors.Is(err, ErrWalkTooDeep) {
+		t.Errorf("walk error = %v, want ErrWalkTooDeep", err)
+	}
+}
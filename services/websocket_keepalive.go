@@ -0,0 +1,46 @@
+package services
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket keepalive timing. Proxies drop idle connections silently, so the panel pings
+// every client and treats one that stops answering as gone.
+const (
+	WSPingInterval = 30 * time.Second
+	WSPongWait     = 60 * time.Second
+	WSWriteWait    = 10 * time.Second
+)
+
+// KeepWebSocketAlive pings conn every WSPingInterval and moves its read deadline forward
+// whenever a pong arrives. A client that stays silent for WSPongWait makes the next read
+// fail, and a failed ping closes the connection, so the caller's read loop ends and runs
+// its cleanup either way. Call the returned function to stop pinging.
+func KeepWebSocketAlive(conn *websocket.Conn) func() {
+	conn.SetReadDeadline(time.Now().Add(WSPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(WSPongWait))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(WSPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// WriteControl may run alongside other writers, unlike WriteMessage
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(WSWriteWait)); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
@@ -130,12 +130,20 @@ const BackupManager = {
             const data = await response.json();
 
             if (data.success) {
-                console.log('Backup created successfully');
-                
-                // Reload backup list
-                await this.loadBackups();
-                
-                this.showSuccess('Backup created successfully');
+                // Backups are archived in the background, follow the job until it finishes
+                const job = await this.waitForJob(data.job_id, (job) => this.updateLoadingBackup(job));
+
+                if (job.status === 'completed') {
+                    console.log('Backup created successfully');
+
+                    // Reload backup list
+                    await this.loadBackups();
+
+                    this.showSuccess('Backup created successfully');
+                } else {
+                    this.showError(job.error || 'Failed to create backup');
+                    this.removeLoadingBackup();
+                }
             } else {
                 this.showError(data.error || 'Failed to create backup');
                 this.removeLoadingBackup();
@@ -164,7 +172,7 @@ const BackupManager = {
     /**
      * Restore from backup
      */
-    async restoreBackup(backupId, backupName) {
+    async restoreBackup(backupId, backupName, allowMismatch = false) {
         if (this.state.isRestoring) {
             return;
         }
@@ -177,12 +185,30 @@ const BackupManager = {
         }
 
         try {
+            const body = new URLSearchParams();
+            if (allowMismatch) {
+                body.append('allow_mismatch', 'true');
+            }
+
             const response = await fetch(`/server/${this.state.serverName}/backups/restore/${backupId}`, {
-                method: 'POST'
+                method: 'POST',
+                body: body
             });
 
             const data = await response.json();
 
+            if (!data.success && data.code === 'BACKUP_MISMATCH') {
+                // The archive belongs to another server; only restore it if the user insists
+                if (window.BackupModals) {
+                    window.BackupModals.closeRestoreProgressModal();
+                }
+                this.state.isRestoring = false;
+                if (confirm(`${data.error}\n\nRestore it into this server anyway?`)) {
+                    await this.restoreBackup(backupId, backupName, true);
+                }
+                return;
+            }
+
             if (data.success) {
                 console.log('Restore completed successfully');
                 
@@ -251,6 +277,33 @@ const BackupManager = {
         }
     },
 
+    /**
+     * Pin or unpin a backup so rotation keeps it
+     */
+    async togglePinBackup(backupId, pinned) {
+        try {
+            const formData = new URLSearchParams();
+            formData.append('pinned', pinned ? 'true' : 'false');
+
+            const response = await fetch(`/server/${this.state.serverName}/backups/${backupId}/pin`, {
+                method: 'POST',
+                body: formData
+            });
+
+            const data = await response.json();
+
+            if (data.success) {
+                await this.loadBackups();
+                this.showSuccess(data.message);
+            } else {
+                this.showError(data.error || 'Failed to update backup');
+            }
+        } catch (error) {
+            console.error('Failed to pin backup:', error);
+            this.showError('Failed to update backup');
+        }
+    },
+
     /**
      * Download a backup
      */
@@ -288,16 +341,23 @@ const BackupManager = {
      */
     createBackupItem(backup) {
         const item = document.createElement('div');
-        item.className = 'backup-item';
+        item.className = backup.pinned ? 'backup-item backup-item-pinned' : 'backup-item';
         item.innerHTML = `
             <div class="backup-item-info">
                 <div class="backup-item-name">${backup.file_name}</div>
                 <div class="backup-item-meta">
                     <span class="backup-item-size">${backup.size_display}</span>
                     <span class="backup-item-date">${backup.created_at}</span>
+                    ${backup.pinned ? '<span class="backup-item-pinned-label">Pinned</span>' : ''}
                 </div>
             </div>
             <div class="backup-item-actions">
+                <button class="backup-action-btn backup-action-pin${backup.pinned ? ' active' : ''}" data-backup-id="${backup.id}" title="${backup.pinned ? 'Unpin' : 'Pin (keep during rotation)'}">
+                    <svg xmlns="http://www.w3.org/2000/svg" width="18" height="18" viewBox="0 0 24 24" fill="${backup.pinned ? 'currentColor' : 'none'}" stroke="currentColor" stroke-width="2">
+                        <line x1="12" y1="17" x2="12" y2="22"></line>
+                        <path d="M5 17h14v-1.76a2 2 0 0 0-1.11-1.79l-1.78-.9A2 2 0 0 1 15 10.76V6h1a2 2 0 0 0 0-4H8a2 2 0 0 0 0 4h1v4.76a2 2 0 0 1-1.11 1.79l-1.78.9A2 2 0 0 0 5 15.24Z"></path>
+                    </svg>
+                </button>
                 <button class="backup-action-btn backup-action-restore" data-backup-id="${backup.id}" data-backup-name="${backup.file_name}" title="Restore">
                     <svg xmlns="http://www.w3.org/2000/svg" width="18" height="18" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
                         <polyline points="23 4 23 10 17 10"></polyline>
@@ -321,10 +381,17 @@ const BackupManager = {
         `;
 
         // Add event listeners
+        const pinBtn = item.querySelector('.backup-action-pin');
         const restoreBtn = item.querySelector('.backup-action-restore');
         const downloadBtn = item.querySelector('.backup-action-download');
         const deleteBtn = item.querySelector('.backup-action-delete');
 
+        if (pinBtn) {
+            pinBtn.addEventListener('click', () => {
+                this.togglePinBackup(backup.id, !backup.pinned);
+            });
+        }
+
         if (restoreBtn) {
             restoreBtn.addEventListener('click', () => {
                 if (window.BackupModals) {
@@ -388,6 +455,62 @@ const BackupManager = {
         container.insertBefore(loadingItem, container.firstChild);
     },
 
+    /**
+     * Poll a background backup job until it finishes
+     * @param {string} jobId - Job ID returned by backup creation
+     * @param {function} onProgress - Optional callback receiving the job on every poll
+     * @returns {Promise<object>} - The finished job
+     */
+    async waitForJob(jobId, onProgress) {
+        while (true) {
+            const response = await fetch(`/server/${this.state.serverName}/backups/job/${jobId}`);
+            const data = await response.json();
+
+            if (!data.success) {
+                throw new Error(data.error || 'Failed to get job status');
+            }
+
+            if (onProgress) {
+                onProgress(data.job);
+            }
+
+            if (data.job.status === 'completed' || data.job.status === 'failed') {
+                return data.job;
+            }
+
+            await new Promise(resolve => setTimeout(resolve, 1000));
+        }
+    },
+
+    /**
+     * Show backup job progress on the loading backup item
+     * @param {object} job - Job status object
+     */
+    updateLoadingBackup(job) {
+        const loadingItem = document.getElementById('loadingBackupItem');
+        if (!loadingItem) return;
+
+        const nameEl = loadingItem.querySelector('.backup-item-name');
+        const sizeEl = loadingItem.querySelector('.backup-item-size');
+
+        if (nameEl && job.bytes_total > 0) {
+            const percent = Math.min(100, Math.round((job.bytes_processed / job.bytes_total) * 100));
+            nameEl.textContent = `Creating backup... ${percent}%`;
+        }
+
+        if (sizeEl) {
+            let progress = `${formatBytes(job.bytes_processed)}`;
+            if (job.bytes_total > 0) {
+                progress += ` of ${formatBytes(job.bytes_total)}`;
+            }
+            progress += ` · ${job.files_processed.toLocaleString()} files`;
+            if (job.current_file) {
+                progress += ` · ${job.current_file}`;
+            }
+            sizeEl.textContent = progress;
+        }
+    },
+
     /**
      * Remove the loading backup item
      */
@@ -7,7 +7,8 @@ const BackupModals = {
         isOpen: false,
         currentSettings: {
             backup_path: '',
-            max_backups: 1
+            max_backups: 1,
+            auto_backup_on_stop: false
         },
         currentRestoreBackup: null
     },
@@ -166,6 +167,11 @@ const BackupModals = {
             maxBackupsSlider.value = maxBackups;
             maxBackupsValue.textContent = maxBackups;
         }
+
+        const autoBackupCheckbox = document.getElementById('autoBackupOnStop');
+        if (autoBackupCheckbox) {
+            autoBackupCheckbox.checked = !!this.state.currentSettings.auto_backup_on_stop;
+        }
     },
 
     /**
@@ -204,6 +210,11 @@ const BackupModals = {
             formData.append('backup_path', backupPath);
             formData.append('max_backups', maxBackups);
 
+            const autoBackupCheckbox = document.getElementById('autoBackupOnStop');
+            if (autoBackupCheckbox) {
+                formData.append('auto_backup_on_stop', autoBackupCheckbox.checked ? 'true' : 'false');
+            }
+
             const response = await fetch(
                 `/server/${window.BackupManager.state.serverName}/backups/settings`,
                 {
@@ -228,8 +239,12 @@ const BackupModals = {
                 // Close modal
                 this.closeSettingsModal();
 
-                // Show success message
-                this.showSuccess('Backup settings saved successfully');
+                // Show success message, including any path collision warnings
+                if (data.warnings && data.warnings.length > 0) {
+                    this.showSuccess('Backup settings saved with warnings:\n' + data.warnings.join('\n'));
+                } else {
+                    this.showSuccess('Backup settings saved successfully');
+                }
             } else {
                 this.showError(data.error || 'Failed to save settings');
             }
@@ -260,9 +275,44 @@ const BackupModals = {
             fileNameEl.textContent = backup.name;
         }
 
+        this.loadDiskImpact(backup);
+
         modal.classList.add('show');
     },
 
+    /**
+     * Show how many files a restore would delete and what the backup brings back
+     */
+    async loadDiskImpact(backup) {
+        const impactEl = document.getElementById('restoreDiskImpact');
+        if (!impactEl) return;
+
+        impactEl.textContent = 'Calculating disk impact...';
+
+        try {
+            const response = await fetch(
+                `/server/${window.BackupManager.state.serverName}/disk-impact?operation=restore&backup_id=${backup.id}`
+            );
+            const data = await response.json();
+
+            // Ignore late responses for a backup that is no longer shown
+            if (this.state.currentRestoreBackup !== backup) return;
+
+            if (data.success) {
+                const current = data.current;
+                const result = data.result;
+                impactEl.textContent =
+                    `This will delete ${current.item_count.toLocaleString()}${current.truncated ? '+' : ''} items (${formatBytes(current.total_size)}) ` +
+                    `and restore ${result.item_count.toLocaleString()} items (${formatBytes(result.total_size)}).`;
+            } else {
+                impactEl.textContent = data.error || 'Could not calculate disk impact.';
+            }
+        } catch (error) {
+            console.error('Failed to load disk impact:', error);
+            impactEl.textContent = 'Could not calculate disk impact.';
+        }
+    },
+
     /**
      * Close restore confirmation modal
      */
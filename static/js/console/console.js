@@ -45,8 +45,13 @@ function initConsoleWebSocket(serverName, onOnline, onOffline) {
         console.error('WebSocket error:', error);
     };
 
-    ws.onclose = function() {
-        console.log('WebSocket closed - server stopped');
+    ws.onclose = function(event) {
+        // 1013 = rejected because too many console connections are open
+        if (event.code === 1013) {
+            console.warn('WebSocket rejected:', event.reason);
+        } else {
+            console.log('WebSocket closed - server stopped');
+        }
         
         // Stop keepalive
         if (pingInterval) {
@@ -208,6 +213,14 @@ function startStatsPolling(serverName, interval = 3000) {
         fetch('/server/' + serverName + '/stats')
             .then(response => response.json())
             .then(data => {
+                // Highlight the status dot while a start or stop is in progress
+                const statusDot = document.querySelector('.status-dot');
+                if (statusDot) {
+                    const transitioning = data.state === 'starting' || data.state === 'stopping';
+                    statusDot.classList.toggle('status-transition', transitioning);
+                    statusDot.title = transitioning ? 'Server is ' + data.state : '';
+                }
+
                 if (data.is_running) {
                     updateMemoryDisplay(data.memory_mb, data.memory_gb);
                 } else {
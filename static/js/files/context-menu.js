@@ -92,6 +92,7 @@ const ContextMenu = {
                 isArchive 
                     ? { action: 'unarchive', label: 'Unarchive', icon: '<svg xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2"><rect x="2" y="4" width="20" height="5"></rect><path d="M4 9v9a2 2 0 002 2h12a2 2 0 002-2V9"></path><path d="M12 13v-4m0 0l-2 2m2-2l2 2"></path></svg>' }
                     : { action: 'archive', label: 'Archive', icon: '<svg xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2"><rect x="2" y="4" width="20" height="5"></rect><path d="M4 9v9a2 2 0 002 2h12a2 2 0 002-2V9"></path><path d="M10 13h4"></path></svg>' },
+                ...(isArchive ? [] : [{ action: 'compress', label: 'Compress (.gz)', icon: '<svg xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2"><path d="M4 14h6v6"></path><path d="M20 10h-6V4"></path><path d="M14 10l7-7"></path><path d="M3 21l7-7"></path></svg>' }]),
                 { action: 'download', label: 'Download', icon: Icons.getDownloadIcon() },
                 { action: 'delete', label: 'Delete', icon: Icons.getTrashIcon(), danger: true }
             ];
@@ -112,6 +113,7 @@ const ContextMenu = {
                 { action: 'duplicate', label: 'Duplicate', icon: Icons.getCopyIcon() },
                 { divider: true },
                 { action: 'archive', label: 'Archive', icon: '<svg xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2"><rect x="2" y="4" width="20" height="5"></rect><path d="M4 9v9a2 2 0 002 2h12a2 2 0 002-2V9"></path><path d="M10 13h4"></path></svg>' },
+                { action: 'compress', label: 'Compress (.gz)', icon: '<svg xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2"><path d="M4 14h6v6"></path><path d="M20 10h-6V4"></path><path d="M14 10l7-7"></path><path d="M3 21l7-7"></path></svg>' },
                 { action: 'download', label: 'Download', icon: Icons.getDownloadIcon() },
                 { action: 'delete', label: 'Delete', icon: Icons.getTrashIcon(), danger: true }
             ];
@@ -225,6 +227,9 @@ const ContextMenu = {
             case 'unarchive':
                 this.handleUnarchive(file);
                 break;
+            case 'compress':
+                this.handleCompress(file);
+                break;
             case 'download':
                 this.handleDownload(file);
                 break;
@@ -308,7 +313,14 @@ const ContextMenu = {
             const data = await response.json();
 
             if (data.success) {
-                console.log('Archive extracted successfully');
+                // Extraction runs in the background; wait for it to finish
+                const job = await FileUtils.waitForJob(data.job_id, FileUtils.logJobProgress);
+
+                if (job.status === 'completed') {
+                    console.log('Archive extracted successfully');
+                } else {
+                    FileUtils.showError(job.error || 'Failed to extract archive');
+                }
                 
                 // Reload directory to show extracted files
                 FileManagerCore.loadDirectory(FileManagerState.currentPath);
@@ -321,6 +333,42 @@ const ContextMenu = {
         }
     },
 
+    /**
+     * Handle compress action from context menu (gzip a single file in place)
+     */
+    async handleCompress(file) {
+        const deleteOriginal = confirm(`Compress "${file.name}" to "${file.name}.gz".\n\nPress OK to remove the original afterwards, or Cancel to keep it.`);
+
+        try {
+            const formData = new URLSearchParams();
+            formData.append('path', FileManagerState.currentPath);
+            formData.append('file', file.name);
+            formData.append('delete_original', deleteOriginal ? 'true' : 'false');
+
+            const response = await fetch(
+                `/server/${FileManagerState.serverName}/files/compress`,
+                {
+                    method: 'POST',
+                    headers: {
+                        'Content-Type': 'application/x-www-form-urlencoded',
+                    },
+                    body: formData
+                }
+            );
+
+            const data = await response.json();
+
+            if (data.success) {
+                FileManagerCore.loadDirectory(FileManagerState.currentPath);
+            } else {
+                FileUtils.showError(data.error || 'Failed to compress file');
+            }
+        } catch (error) {
+            console.error('Failed to compress file:', error);
+            FileUtils.showError('Failed to compress file');
+        }
+    },
+
     /**
      * Handle download action from context menu
      */
@@ -129,8 +129,10 @@ const FileEditor = {
 
     /**
      * Save file content
+     * @param {boolean} force - Save even if the running server has the file in use
+     * @param {boolean} override - Save even if the file is protected
      */
-    async save() {
+    async save(force = false, override = false) {
         if (!FileEditorState.currentFile) return;
 
         const textarea = document.getElementById('fileEditorTextarea');
@@ -150,11 +152,20 @@ const FileEditor = {
             Saving...
         `;
 
+        let retryForced = false;
+        let retryOverride = false;
+
         try {
             const formData = new URLSearchParams();
             formData.append('path', FileManagerState.currentPath);
             formData.append('file', FileEditorState.currentFile.name);
             formData.append('content', content);
+            if (force) {
+                formData.append('force', 'true');
+            }
+            if (override) {
+                formData.append('override_protection', 'true');
+            }
 
             const response = await fetch(
                 `/server/${FileManagerState.serverName}/files/write`,
@@ -174,9 +185,18 @@ const FileEditor = {
                 FileEditorState.hasChanges = false;
 
                 console.log('File saved successfully');
+                if (data.warning) {
+                    console.warn(data.warning);
+                }
 
                 this.close();
                 FileManagerCore.loadDirectory(FileManagerState.currentPath);
+            } else if (data.requires_force) {
+                // The file is live while the server runs, only save if the user accepts the risk
+                retryForced = confirm(`${data.error}\n\nSave anyway?`);
+            } else if (data.requires_override) {
+                // Protected files are only overwritten when the user explicitly overrides
+                retryOverride = confirm(`${data.error}\n\nOverride the protection and save anyway?`);
             } else {
                 FileUtils.showError(data.error || 'Failed to save file');
             }
@@ -187,6 +207,12 @@ const FileEditor = {
             saveBtn.disabled = false;
             saveBtn.innerHTML = originalText;
         }
+
+        if (retryForced) {
+            await this.save(true, override);
+        } else if (retryOverride) {
+            await this.save(force, true);
+        }
     },
 
     /**
@@ -86,8 +86,18 @@ const FileManagerCore = {
             const data = await response.json();
             
             if (data.error) {
-                FileUtils.showError(data.error);
                 FileManagerState.files = [];
+
+                // Fall back to the nearest ancestor that still exists
+                const fallback = await this.findNearestValidPath(path);
+                if (fallback !== null) {
+                    FileUtils.showError(`Folder "${path}" no longer exists, showing "${fallback}" instead`);
+                    FileManagerState.isLoading = false;
+                    this.loadDirectory(fallback);
+                    return;
+                }
+
+                FileUtils.showError(data.error);
             } else {
                 FileManagerState.files = data.files || [];
                 FileManagerState.currentPath = data.current_path || path;
@@ -107,6 +117,28 @@ const FileManagerCore = {
         }
     },
 
+    /**
+     * Find the deepest existing ancestor of a path that can't be listed
+     * @param {string} path - Path that failed to load
+     * @returns {Promise<string|null>} - Ancestor path, or null when the path itself is valid or unknown
+     */
+    async findNearestValidPath(path) {
+        try {
+            const response = await fetch(
+                `/server/${FileManagerState.serverName}/files/path-info?path=${encodeURIComponent(path)}`
+            );
+            const data = await response.json();
+
+            if (!data.success || data.valid || data.nearest_valid === data.path) {
+                return null;
+            }
+            return data.nearest_valid;
+        } catch (error) {
+            console.error('Failed to check path:', error);
+            return null;
+        }
+    },
+
     /**
      * Navigate to a specific folder
      * @param {string} folderName - Name of the folder
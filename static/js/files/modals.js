@@ -128,6 +128,7 @@ const Modals = {
         const moveBtn = document.getElementById('moveBtn');
         const duplicateBtn = document.getElementById('duplicateBtn');
         const archiveBtn = document.getElementById('archiveBtn');
+        const downloadSelectedBtn = document.getElementById('downloadSelectedBtn');
         const deleteBtn = document.getElementById('deleteBtn');
 
         if (moveBtn) {
@@ -142,6 +143,10 @@ const Modals = {
             archiveBtn.addEventListener('click', () => this.handleFloatingArchive());
         }
 
+        if (downloadSelectedBtn) {
+            downloadSelectedBtn.addEventListener('click', () => this.handleFloatingDownload());
+        }
+
         if (deleteBtn) {
             deleteBtn.addEventListener('click', () => this.handleFloatingDelete());
         }
@@ -211,12 +216,19 @@ const Modals = {
             console.log('Response:', data);
 
             if (data.success) {
-                console.log(`Archive created: ${data.archive}`);
-                
                 // Clear selection
                 FileManagerState.selectedFiles.clear();
                 FileManagerCore.updateSelectAllCheckbox();
                 FileManagerCore.updateFloatingActions();
+
+                // Archive creation runs in the background; wait for it to finish
+                const job = await FileUtils.waitForJob(data.job_id, FileUtils.logJobProgress);
+
+                if (job.status === 'completed') {
+                    console.log(`Archive created: ${data.archive}`);
+                } else {
+                    FileUtils.showError(job.error || 'Failed to create archive');
+                }
                 
                 // Reload directory to show new archive
                 FileManagerCore.loadDirectory(FileManagerState.currentPath);
@@ -229,6 +241,38 @@ const Modals = {
         }
     },
 
+    /**
+     * Handle download from floating button.
+     * Submits a hidden form so the browser streams the zip straight to disk.
+     */
+    handleFloatingDownload() {
+        if (FileManagerState.selectedFiles.size === 0) {
+            FileUtils.showError('No files selected');
+            return;
+        }
+
+        const form = document.createElement('form');
+        form.method = 'POST';
+        form.action = `/server/${FileManagerState.serverName}/files/download-selected`;
+        form.style.display = 'none';
+
+        const pathInput = document.createElement('input');
+        pathInput.type = 'hidden';
+        pathInput.name = 'path';
+        pathInput.value = FileManagerState.currentPath;
+        form.appendChild(pathInput);
+
+        const filesInput = document.createElement('input');
+        filesInput.type = 'hidden';
+        filesInput.name = 'files';
+        filesInput.value = JSON.stringify(Array.from(FileManagerState.selectedFiles));
+        form.appendChild(filesInput);
+
+        document.body.appendChild(form);
+        form.submit();
+        document.body.removeChild(form);
+    },
+
     /**
      * Handle delete from floating button
      */
@@ -103,6 +103,46 @@ const FileUtils = {
         return FILE_TYPES.ARCHIVE.includes(ext);
     },
 
+    /**
+     * Poll a background file job until it finishes
+     * @param {string} jobId - Job ID returned by archive/unarchive
+     * @param {function} onProgress - Optional callback receiving the job on every poll
+     * @returns {Promise<object>} - The finished job
+     */
+    async waitForJob(jobId, onProgress) {
+        while (true) {
+            const response = await fetch(
+                `/server/${FileManagerState.serverName}/files/job/${jobId}`
+            );
+            const data = await response.json();
+
+            if (!data.success) {
+                throw new Error(data.error || 'Failed to get job status');
+            }
+
+            if (onProgress) {
+                onProgress(data.job);
+            }
+
+            if (data.job.status === 'completed' || data.job.status === 'failed') {
+                return data.job;
+            }
+
+            await new Promise(resolve => setTimeout(resolve, 1000));
+        }
+    },
+
+    /**
+     * Log job progress as a percentage
+     * @param {object} job - Job status object
+     */
+    logJobProgress(job) {
+        if (job.bytes_total > 0) {
+            const percent = Math.min(100, Math.round((job.bytes_processed / job.bytes_total) * 100));
+            console.log(`${job.type}: ${percent}% (${job.files_processed} files) ${job.current_file || ''}`);
+        }
+    },
+
     /**
      * Show error message
      * @param {string} message - Error message
@@ -314,8 +314,13 @@ function initSettingsForm() {
             const data = await response.json();
 
             if (data.success) {
-                // Show success message
-                showAlert(data.message, 'success', 'settingsAlertContainer');
+                if (data.warning) {
+                    // Saved, but file operations will fail until permissions are fixed
+                    showAlert(`${data.message}. Warning: ${data.warning}`, 'error', 'settingsAlertContainer');
+                } else {
+                    // Show success message
+                    showAlert(data.message, 'success', 'settingsAlertContainer');
+                }
                 
                 // Update current path display if it exists
                 if (currentPathDisplay && data.path) {
@@ -323,9 +328,11 @@ function initSettingsForm() {
                 }
                 
                 // Optionally refresh dashboard to show new servers
-                setTimeout(() => {
-                    showAlert('Please visit the dashboard to see scanned servers.', 'success', 'settingsAlertContainer');
-                }, 2000);
+                if (!data.warning) {
+                    setTimeout(() => {
+                        showAlert('Please visit the dashboard to see scanned servers.', 'success', 'settingsAlertContainer');
+                    }, 2000);
+                }
             } else {
                 // Show error message
                 showAlert(data.error, 'error', 'settingsAlertContainer');
@@ -342,6 +349,53 @@ function initSettingsForm() {
     });
 }
 
+// ========== HISTORY RETENTION FORM ==========
+
+/**
+ * Initialize schedule run history retention form
+ */
+function initRetentionForm() {
+    const retentionForm = document.getElementById('retentionForm');
+    const retentionBtn = document.getElementById('retentionBtn');
+
+    if (!retentionForm || !retentionBtn) return;
+
+    retentionForm.addEventListener('submit', async function(e) {
+        e.preventDefault();
+
+        // Disable button and show loading state
+        retentionBtn.disabled = true;
+        const originalText = retentionBtn.textContent;
+        retentionBtn.textContent = 'Updating...';
+
+        // Get form data
+        const formData = new FormData(retentionForm);
+
+        try {
+            // Send AJAX request
+            const response = await fetch('/settings/update-history-retention', {
+                method: 'POST',
+                body: new URLSearchParams(formData)
+            });
+
+            const data = await response.json();
+
+            if (data.success) {
+                showAlert(data.message, 'success', 'retentionAlertContainer');
+            } else {
+                showAlert(data.error, 'error', 'retentionAlertContainer');
+            }
+        } catch (error) {
+            showAlert('An error occurred. Please try again.', 'error', 'retentionAlertContainer');
+            console.error('Retention update error:', error);
+        } finally {
+            // Re-enable button
+            retentionBtn.disabled = false;
+            retentionBtn.textContent = originalText;
+        }
+    });
+}
+
 // ========== STARTUP FORM ==========
 
 /**
@@ -373,8 +427,12 @@ function initStartupForm(serverName) {
         const originalText = startupBtn.textContent;
         startupBtn.textContent = 'Updating...';
 
-        // Get form data
+        // Get form data; an unchecked box isn't submitted, so send auto-start either way
         const formData = new FormData(startupForm);
+        const autoStartCheckbox = document.getElementById('auto_start_on_boot');
+        if (autoStartCheckbox) {
+            formData.set('auto_start_on_boot', autoStartCheckbox.checked ? 'true' : 'false');
+        }
 
         try {
             // Send AJAX request
@@ -404,6 +462,179 @@ function initStartupForm(serverName) {
     });
 }
 
+/**
+ * Initialize the server rename form
+ * @param {string} serverName - Current server name
+ */
+function initRenameForm(serverName) {
+    const renameForm = document.getElementById('renameForm');
+    const renameBtn = document.getElementById('renameBtn');
+
+    if (!renameForm || !renameBtn) return;
+
+    renameForm.addEventListener('submit', async function(e) {
+        e.preventDefault();
+
+        renameBtn.disabled = true;
+        const originalText = renameBtn.textContent;
+        renameBtn.textContent = 'Renaming...';
+
+        const formData = new FormData(renameForm);
+
+        try {
+            const response = await fetch(`/server/${serverName}/rename`, {
+                method: 'POST',
+                body: new URLSearchParams(formData)
+            });
+
+            const data = await response.json();
+
+            if (data.success) {
+                // The page URL contains the old name, so move to the new one
+                showAlert(data.message, 'success', 'renameAlertContainer');
+                setTimeout(() => {
+                    window.location.href = `/server/${encodeURIComponent(data.server.name)}/startup`;
+                }, 1500);
+                return;
+            }
+
+            showAlert(data.error, 'error', 'renameAlertContainer');
+        } catch (error) {
+            showAlert('An error occurred. Please try again.', 'error', 'renameAlertContainer');
+            console.error('Rename error:', error);
+        }
+
+        renameBtn.disabled = false;
+        renameBtn.textContent = originalText;
+    });
+}
+
+/**
+ * Initialize the file permissions form
+ * @param {string} serverName - Server name
+ */
+function initFileModesForm(serverName) {
+    const fileModesForm = document.getElementById('fileModesForm');
+    const fileModesBtn = document.getElementById('fileModesBtn');
+
+    if (!fileModesForm || !fileModesBtn) return;
+
+    fileModesForm.addEventListener('submit', async function(e) {
+        e.preventDefault();
+
+        fileModesBtn.disabled = true;
+        const originalText = fileModesBtn.textContent;
+        fileModesBtn.textContent = 'Saving...';
+
+        const formData = new FormData(fileModesForm);
+
+        try {
+            const response = await fetch(`/server/${serverName}/file-modes`, {
+                method: 'POST',
+                body: new URLSearchParams(formData)
+            });
+
+            const data = await response.json();
+
+            if (data.success) {
+                showAlert(data.message, 'success', 'fileModesAlertContainer');
+            } else {
+                showAlert(data.error, 'error', 'fileModesAlertContainer');
+            }
+        } catch (error) {
+            showAlert('An error occurred. Please try again.', 'error', 'fileModesAlertContainer');
+            console.error('File permissions error:', error);
+        }
+
+        fileModesBtn.disabled = false;
+        fileModesBtn.textContent = originalText;
+    });
+}
+
+/**
+ * Initialize the console command policy form
+ * @param {string} serverName - Server name
+ */
+function initConsolePolicyForm(serverName) {
+    const consolePolicyForm = document.getElementById('consolePolicyForm');
+    const consolePolicyBtn = document.getElementById('consolePolicyBtn');
+
+    if (!consolePolicyForm || !consolePolicyBtn) return;
+
+    consolePolicyForm.addEventListener('submit', async function(e) {
+        e.preventDefault();
+
+        consolePolicyBtn.disabled = true;
+        const originalText = consolePolicyBtn.textContent;
+        consolePolicyBtn.textContent = 'Saving...';
+
+        const formData = new FormData(consolePolicyForm);
+
+        try {
+            const response = await fetch(`/server/${serverName}/console-policy`, {
+                method: 'POST',
+                body: new URLSearchParams(formData)
+            });
+
+            const data = await response.json();
+
+            if (data.success) {
+                showAlert(data.message, 'success', 'consolePolicyAlertContainer');
+            } else {
+                showAlert(data.error, 'error', 'consolePolicyAlertContainer');
+            }
+        } catch (error) {
+            showAlert('An error occurred. Please try again.', 'error', 'consolePolicyAlertContainer');
+            console.error('Console policy error:', error);
+        }
+
+        consolePolicyBtn.disabled = false;
+        consolePolicyBtn.textContent = originalText;
+    });
+}
+
+/**
+ * Initialize the protected files form
+ * @param {string} serverName - Server name
+ */
+function initProtectedPathsForm(serverName) {
+    const protectedPathsForm = document.getElementById('protectedPathsForm');
+    const protectedPathsBtn = document.getElementById('protectedPathsBtn');
+
+    if (!protectedPathsForm || !protectedPathsBtn) return;
+
+    protectedPathsForm.addEventListener('submit', async function(e) {
+        e.preventDefault();
+
+        protectedPathsBtn.disabled = true;
+        const originalText = protectedPathsBtn.textContent;
+        protectedPathsBtn.textContent = 'Saving...';
+
+        const formData = new FormData(protectedPathsForm);
+
+        try {
+            const response = await fetch(`/server/${serverName}/protected-paths`, {
+                method: 'POST',
+                body: new URLSearchParams(formData)
+            });
+
+            const data = await response.json();
+
+            if (data.success) {
+                showAlert(data.message, 'success', 'protectedPathsAlertContainer');
+            } else {
+                showAlert(data.error, 'error', 'protectedPathsAlertContainer');
+            }
+        } catch (error) {
+            showAlert('An error occurred. Please try again.', 'error', 'protectedPathsAlertContainer');
+            console.error('Protected files error:', error);
+        }
+
+        protectedPathsBtn.disabled = false;
+        protectedPathsBtn.textContent = originalText;
+    });
+}
+
 // ========== EXPORTS (if using modules) ==========
 // Uncomment if using ES6 modules
 /*
@@ -413,6 +644,11 @@ export {
     initUsernameForm,
     initPasswordForm,
     initSettingsForm,
-    initStartupForm
+    initRetentionForm,
+    initStartupForm,
+    initRenameForm,
+    initFileModesForm,
+    initConsolePolicyForm,
+    initProtectedPathsForm
 };
 */
\ No newline at end of file
@@ -41,6 +41,7 @@ document.addEventListener('DOMContentLoaded', function() {
     // Settings Page
     if (currentPath === '/settings') {
         initSettingsForm();
+        initRetentionForm();
     }
 
     // Server Console Page
@@ -53,6 +54,10 @@ document.addEventListener('DOMContentLoaded', function() {
         const serverName = extractServerName(currentPath);
         if (serverName) {
             initStartupForm(serverName);
+            initRenameForm(serverName);
+            initFileModesForm(serverName);
+            initConsolePolicyForm(serverName);
+            initProtectedPathsForm(serverName);
         }
     }
 
@@ -234,7 +239,21 @@ function initConsolePage() {
         commandInput.addEventListener('keypress', function(e) {
             if (e.key === 'Enter' && this.value.trim() !== '') {
                 sendServerCommand(serverName, this.value.trim())
-                    .then(() => { this.value = ''; })
+                    .then(data => {
+                        // Commands blocked by the console policy stay in the input
+                        if (data && data.error) {
+                            const consoleEl = document.getElementById('console');
+                            if (consoleEl) {
+                                const line = document.createElement('div');
+                                line.className = 'console-line-error';
+                                line.textContent = data.error;
+                                consoleEl.appendChild(line);
+                                consoleEl.scrollTop = consoleEl.scrollHeight;
+                            }
+                            return;
+                        }
+                        this.value = '';
+                    })
                     .catch(err => console.error('Command failed:', err));
             }
         });
@@ -74,6 +74,26 @@ const ScheduleModals = {
                 }
             });
         }
+
+        const catchUpInput = document.getElementById('scheduleCatchUp');
+        if (catchUpInput) {
+            catchUpInput.addEventListener('change', (e) => {
+                const label = document.getElementById('scheduleCatchUpLabel');
+                if (label) {
+                    label.textContent = e.target.checked ? 'On' : 'Off';
+                }
+            });
+        }
+
+        const skipOverlapInput = document.getElementById('scheduleSkipOverlap');
+        if (skipOverlapInput) {
+            skipOverlapInput.addEventListener('change', (e) => {
+                const label = document.getElementById('scheduleSkipOverlapLabel');
+                if (label) {
+                    label.textContent = e.target.checked ? 'On' : 'Off';
+                }
+            });
+        }
     },
 
     /**
@@ -158,6 +178,26 @@ const ScheduleModals = {
             enabledLabel.textContent = schedule.enabled ? 'Enabled' : 'Disabled';
         }
 
+        // Catch-up toggle
+        const catchUpInput = document.getElementById('scheduleCatchUp');
+        const catchUpLabel = document.getElementById('scheduleCatchUpLabel');
+        if (catchUpInput) {
+            catchUpInput.checked = !!schedule.catch_up;
+        }
+        if (catchUpLabel) {
+            catchUpLabel.textContent = schedule.catch_up ? 'On' : 'Off';
+        }
+
+        // Skip-overlap toggle
+        const skipOverlapInput = document.getElementById('scheduleSkipOverlap');
+        const skipOverlapLabel = document.getElementById('scheduleSkipOverlapLabel');
+        if (skipOverlapInput) {
+            skipOverlapInput.checked = !!schedule.skip_overlap;
+        }
+        if (skipOverlapLabel) {
+            skipOverlapLabel.textContent = schedule.skip_overlap ? 'On' : 'Off';
+        }
+
         // Action
         const actionSelect = document.getElementById('scheduleAction');
         if (actionSelect) {
@@ -194,6 +234,14 @@ const ScheduleModals = {
         const enabledInput = document.getElementById('scheduleEnabled');
         if (enabledInput) enabledInput.checked = true;
 
+        // Reset catch-up to off
+        const catchUpLabel = document.getElementById('scheduleCatchUpLabel');
+        if (catchUpLabel) catchUpLabel.textContent = 'Off';
+
+        // Reset skip-overlap to off
+        const skipOverlapLabel = document.getElementById('scheduleSkipOverlapLabel');
+        if (skipOverlapLabel) skipOverlapLabel.textContent = 'Off';
+
         // Show command group (default action is send_command)
         this.handleActionChange('send_command');
 
@@ -289,6 +337,14 @@ const ScheduleModals = {
         const enabled = document.getElementById('scheduleEnabled')?.checked ? 'true' : 'false';
         formData.append('enabled', enabled);
 
+        // Catch up missed runs
+        const catchUp = document.getElementById('scheduleCatchUp')?.checked ? 'true' : 'false';
+        formData.append('catch_up', catchUp);
+
+        // Skip overlapping runs
+        const skipOverlap = document.getElementById('scheduleSkipOverlap')?.checked ? 'true' : 'false';
+        formData.append('skip_overlap', skipOverlap);
+
         // Action
         const action = document.getElementById('scheduleAction')?.value || 'send_command';
         formData.append('action', action);
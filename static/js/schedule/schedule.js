@@ -6,6 +6,9 @@ const ScheduleManager = {
     state: {
         serverName: '',
         schedules: [],
+        paused: false,
+        schedulerDown: false,
+        schedulerError: '',
         isLoading: false,
         currentEditingSchedule: null
     },
@@ -27,6 +30,95 @@ const ScheduleManager = {
         if (createBtn) {
             createBtn.addEventListener('click', () => this.openCreateModal());
         }
+
+        const pauseBtn = document.getElementById('pauseSchedulesBtn');
+        if (pauseBtn) {
+            pauseBtn.addEventListener('click', () => this.togglePaused());
+        }
+
+        const restartBtn = document.getElementById('restartSchedulerBtn');
+        if (restartBtn) {
+            restartBtn.addEventListener('click', () => this.restartScheduler());
+        }
+    },
+
+    /**
+     * Restart the scheduler after it failed to start
+     */
+    async restartScheduler() {
+        try {
+            const response = await fetch('/api/scheduler/restart', { method: 'POST' });
+            const data = await response.json();
+
+            if (data.success) {
+                this.showSuccess(data.warning ? `${data.message}: ${data.warning}` : data.message);
+                this.loadSchedules();
+            } else {
+                this.showError(data.error || 'Failed to restart scheduler');
+            }
+        } catch (error) {
+            console.error('Failed to restart scheduler:', error);
+            this.showError('Failed to restart scheduler');
+        }
+    },
+
+    /**
+     * Render the warning shown when the scheduler isn't running or didn't load everything
+     */
+    renderSchedulerHealth() {
+        const notice = document.getElementById('schedulerDownNotice');
+        if (!notice) return;
+
+        const unhealthy = this.state.schedulerDown || this.state.schedulerError;
+        notice.style.display = unhealthy ? 'flex' : 'none';
+
+        const message = document.getElementById('schedulerDownMessage');
+        if (message && unhealthy) {
+            const summary = this.state.schedulerDown
+                ? 'The scheduler is not running. Schedules are saved but will not run.'
+                : 'The scheduler started with errors. Some schedules may not run.';
+            message.textContent = this.state.schedulerError
+                ? `${summary} (${this.state.schedulerError})`
+                : summary;
+        }
+    },
+
+    /**
+     * Pause or resume all schedules
+     */
+    async togglePaused() {
+        const endpoint = this.state.paused ? '/api/schedules/resume' : '/api/schedules/pause';
+
+        try {
+            const response = await fetch(endpoint, { method: 'POST' });
+            const data = await response.json();
+
+            if (data.success) {
+                this.state.paused = data.paused;
+                this.renderPaused();
+                this.showSuccess(data.message);
+            } else {
+                this.showError(data.error || 'Failed to update schedules');
+            }
+        } catch (error) {
+            console.error('Failed to update schedules:', error);
+            this.showError('Failed to update schedules');
+        }
+    },
+
+    /**
+     * Render paused notice and button label
+     */
+    renderPaused() {
+        const notice = document.getElementById('schedulePausedNotice');
+        if (notice) {
+            notice.style.display = this.state.paused ? 'block' : 'none';
+        }
+
+        const pauseBtn = document.getElementById('pauseSchedulesBtn');
+        if (pauseBtn) {
+            pauseBtn.textContent = this.state.paused ? 'RESUME ALL' : 'PAUSE ALL';
+        }
     },
 
     /**
@@ -45,6 +137,11 @@ const ScheduleManager = {
 
             if (data.success) {
                 this.state.schedules = data.schedules || [];
+                this.state.paused = !!data.paused;
+                this.state.schedulerDown = !!data.scheduler_down;
+                this.state.schedulerError = data.scheduler_error || '';
+                this.renderPaused();
+                this.renderSchedulerHealth();
                 this.renderSchedules();
             } else {
                 this.showError(data.error || 'Failed to load schedules');
@@ -160,6 +257,21 @@ const ScheduleManager = {
             this.executeSchedule(schedule.id, schedule.name);
         });
 
+        // Duplicate button
+        const duplicateBtn = document.createElement('button');
+        duplicateBtn.className = 'schedule-action-btn schedule-action-duplicate';
+        duplicateBtn.innerHTML = `
+            <svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
+                <rect x="9" y="9" width="13" height="13" rx="2" ry="2"></rect>
+                <path d="M5 15H4a2 2 0 0 1-2-2V4a2 2 0 0 1 2-2h9a2 2 0 0 1 2 2v1"></path>
+            </svg>
+        `;
+        duplicateBtn.title = 'Duplicate schedule';
+        duplicateBtn.addEventListener('click', (e) => {
+            e.stopPropagation();
+            this.duplicateSchedule(schedule.id);
+        });
+
         // Delete button
         const deleteBtn = document.createElement('button');
         deleteBtn.className = 'schedule-action-btn schedule-action-delete';
@@ -179,6 +291,7 @@ const ScheduleManager = {
 
         actions.appendChild(toggleLabel);
         actions.appendChild(playBtn);
+        actions.appendChild(duplicateBtn);
         actions.appendChild(deleteBtn);
 
         // Assemble item
@@ -394,6 +507,32 @@ const ScheduleManager = {
         }
     },
 
+    /**
+     * Duplicate a schedule as a disabled copy
+     */
+    async duplicateSchedule(scheduleId) {
+        try {
+            const response = await fetch(
+                `/server/${this.state.serverName}/schedule/${scheduleId}/duplicate`,
+                {
+                    method: 'POST'
+                }
+            );
+
+            const data = await response.json();
+
+            if (data.success) {
+                await this.loadSchedules();
+                this.showSuccess(`Created disabled copy "${data.schedule.name}"`);
+            } else {
+                this.showError(data.error || 'Failed to duplicate schedule');
+            }
+        } catch (error) {
+            console.error('Failed to duplicate schedule:', error);
+            this.showError('Failed to duplicate schedule');
+        }
+    },
+
     /**
      * Execute schedule manually
      */